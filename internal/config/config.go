@@ -3,10 +3,12 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 
 	"auto-api-tester/internal/llm"
+	"auto-api-tester/internal/types"
 )
 
 // Config represents the application configuration
@@ -15,61 +17,248 @@ type Config struct {
 		Concurrent bool `json:"concurrent"`
 		MaxWorkers int  `json:"max_workers"`
 		Timeout    int  `json:"timeout"`
+		// BaseURL is prepended to relative endpoint paths and replaces the
+		// scheme and host of absolute ones; the -base-url flag overrides it
+		BaseURL string `json:"base_url,omitempty"`
+		// TotalTimeout bounds the whole run in seconds, zero leaves it unbounded
+		TotalTimeout int `json:"total_timeout,omitempty"`
+		// FollowRedirects, unless set to false, follows up to MaxRedirects
+		// redirects (default 10); otherwise 3xx responses are tested as they are
+		FollowRedirects *bool `json:"follow_redirects,omitempty"`
+		MaxRedirects    int   `json:"max_redirects,omitempty"`
+		// UseCookies sends cookies set by earlier responses on later requests
+		UseCookies bool `json:"use_cookies,omitempty"`
 		Retry      struct {
 			Attempts int `json:"attempts"`
 			Delay    int `json:"delay"`
+			// Budget caps the total retries across the suite; zero is unlimited
+			Budget int `json:"budget,omitempty"`
+			// RetryableStatusCodes are the statuses retried, by default 429, 502, 503 and 504
+			RetryableStatusCodes []int `json:"retryable_status_codes,omitempty"`
 		} `json:"retry"`
+		// MaxFailures aborts the run once this many tests have failed; zero runs everything
+		MaxFailures    int `json:"max_failures,omitempty"`
+		IdempotencyKey struct {
+			Enabled bool   `json:"enabled"`
+			Header  string `json:"header,omitempty"`
+		} `json:"idempotency_key"`
+
+		// Variables seeds the values referenced as {{name}} in request bodies
+		Variables map[string]interface{} `json:"variables,omitempty"`
+
+		// Sequential runs the test cases one at a time in the order they are declared
+		Sequential bool `json:"sequential,omitempty"`
+
+		// EnvHeaders maps header names to environment variables whose values
+		// are sent with every request, e.g. {"X-Tenant-ID": "TENANT_ID"}
+		EnvHeaders map[string]string `json:"env_headers,omitempty"`
+
+		// CircuitBreaker skips requests to a host for CooldownSeconds after
+		// Threshold consecutive failures within WindowSeconds
+		CircuitBreaker struct {
+			Threshold       int `json:"threshold"`
+			WindowSeconds   int `json:"window_seconds,omitempty"`
+			CooldownSeconds int `json:"cooldown_seconds,omitempty"`
+		} `json:"circuit_breaker"`
+
+		// Iterations runs every endpoint this many times, scaled by its weight
+		Iterations int `json:"iterations,omitempty"`
+
+		// Sample runs this many weighted random endpoint picks instead of every endpoint
+		Sample int `json:"sample,omitempty"`
+
+		// AllowEmptyBinary passes download endpoints that return an empty body
+		AllowEmptyBinary bool `json:"allow_empty_binary,omitempty"`
+
+		// ValidateResponses checks response bodies against the spec, which is
+		// fetched from SpecURL (or the -spec-url flag) at run time
+		ValidateResponses bool   `json:"validate_responses"`
+		SpecURL           string `json:"spec_url,omitempty"`
+
+		// StrictResponses implies ValidateResponses and also fails responses
+		// with fields the schema doesn't declare
+		StrictResponses bool `json:"strict_responses,omitempty"`
+
+		// ExpectDocumentedStatus fails a success status other than the one 2xx
+		// response an operation documents; it also reads the spec from SpecURL
+		ExpectDocumentedStatus bool `json:"expect_documented_status,omitempty"`
 	} `json:"test"`
 
 	Reporting struct {
-		Format    string `json:"format"`
-		OutputDir string `json:"output_dir"`
-		Detailed  bool   `json:"detailed"`
+		Format    Formats `json:"format"`
+		OutputDir string  `json:"output_dir"`
+		Detailed  bool    `json:"detailed"`
+
+		// MetricsFile, when set, receives Prometheus textfile-collector metrics after each run
+		MetricsFile string `json:"metrics_file,omitempty"`
+
+		// Sort orders report results: failed-first (default), slowest-first or alphabetical
+		Sort string `json:"sort,omitempty"`
+
+		// HAR exports every request/response pair of the run as an HTTP Archive
+		HAR bool `json:"har,omitempty"`
+
+		// RunFolders writes each run's artifacts into OutputDir/run_<timestamp>/
+		RunFolders bool `json:"run_folders,omitempty"`
+
+		// Template is an html/template file overriding the built-in HTML report layout
+		Template string `json:"template,omitempty"`
+
+		// Sinks receive each result as it completes and the final report,
+		// in addition to the report files
+		Sinks []SinkConfig `json:"sinks,omitempty"`
+
+		// UnredactedHeaders lists sensitive response headers, such as
+		// Set-Cookie, that are reported as received instead of redacted
+		UnredactedHeaders []string `json:"unredacted_headers,omitempty"`
 	} `json:"reporting"`
 
+	// Notify posts a summary of each run to a webhook such as a Slack or
+	// Teams incoming webhook
+	Notify struct {
+		WebhookURL string `json:"webhook_url,omitempty"`
+		Label      string `json:"label,omitempty"`
+		ReportURL  string `json:"report_url,omitempty"`
+		// Template is a text/template for the request body
+		Template string `json:"template,omitempty"`
+		// NotifyOn is "always" (default) or "failure"
+		NotifyOn string `json:"notify_on,omitempty"`
+	} `json:"notify"`
+
+	// Auth sends a bearer token with every request
+	Auth AuthConfig `json:"auth"`
+
+	// AuthProfiles are named credentials that endpoints select with auth_profile
+	// in place of Auth, e.g. separate user and admin tokens
+	AuthProfiles map[string]AuthConfig `json:"auth_profiles,omitempty"`
+
+	// AuthServices maps service base URLs (or hosts) to the auth profile used
+	// for their endpoints, so a multi-service run sends each service its own token
+	AuthServices map[string]string `json:"auth_services,omitempty"`
+
+	// Assertions are applied to every response in addition to the per-endpoint ones
+	Assertions struct {
+		Headers []types.HeaderAssertion `json:"headers,omitempty"`
+
+		// IgnoreFields are field names or JSONPath patterns (e.g. "$.meta.requestId",
+		// "$.items[*].createdAt") excluded from response comparisons
+		IgnoreFields []string `json:"ignore_fields,omitempty"`
+
+		// DetectCachedResponses flags GET endpoints whose response never varies
+		// across the calls of an iteration or sampling run
+		DetectCachedResponses bool `json:"detect_cached_responses,omitempty"`
+	} `json:"assertions"`
+
+	// Spec adds locations where the Swagger/OpenAPI spec is looked for
+	Spec struct {
+		// Candidates are paths relative to the base URL or absolute URLs
+		Candidates []string `json:"candidates,omitempty"`
+		// ReplaceDefaults tries only Candidates instead of adding them to the built-in list
+		ReplaceDefaults bool `json:"replace_defaults,omitempty"`
+	} `json:"spec"`
+
+	// Proxy is the HTTP proxy URL requests to the API and the spec go
+	// through, overriding HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	Proxy string `json:"proxy,omitempty"`
+
+	// Golden records responses on the first run and compares later runs against them
+	Golden struct {
+		Enabled      bool     `json:"enabled"`
+		Dir          string   `json:"dir"`
+		IgnoreFields []string `json:"ignore_fields,omitempty"`
+	} `json:"golden"`
+
+	// Generation controls the shape of generated test data
+	Generation struct {
+		ArrayItems types.ArrayBounds `json:"array_items"`
+
+		// FieldRules add name-based generators for domain-specific fields
+		FieldRules []types.FieldRule `json:"field_rules,omitempty"`
+	} `json:"generation"`
+
 	LLM *llm.Config `json:"llm,omitempty"`
 }
 
-// LoadConfig loads the configuration from a file
+// AuthConfig holds request credentials. The default bearer type sends Token,
+// fetched and refreshed over OAuth2 when TokenURL is set and a request returns
+// 401. The apikey type sends Value in the HeaderName header.
+type AuthConfig struct {
+	Type       string `json:"type,omitempty"`
+	HeaderName string `json:"header_name,omitempty"`
+	Value      string `json:"value,omitempty"`
+
+	// TokenEnv names an environment variable holding the token, or the value
+	// for apikey auth, so secrets stay out of the config file
+	TokenEnv string `json:"token_env,omitempty"`
+
+	Token        string   `json:"token,omitempty"`
+	TokenURL     string   `json:"token_url,omitempty"`
+	ClientID     string   `json:"client_id,omitempty"`
+	ClientSecret string   `json:"client_secret,omitempty"`
+	RefreshToken string   `json:"refresh_token,omitempty"`
+	Scopes       []string `json:"scopes,omitempty"`
+}
+
+// SinkConfig configures an output sink. The webhook type POSTs each result
+// and the final report as JSON to URL.
+type SinkConfig struct {
+	Type    string            `json:"type"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// Formats lists the report formats to write, e.g. ["json", "html"]
+type Formats []string
+
+// UnmarshalJSON accepts a bare string as a single format
+func (f *Formats) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*f = Formats{single}
+		return nil
+	}
+	var formats []string
+	if err := json.Unmarshal(data, &formats); err != nil {
+		return fmt.Errorf("format must be a string or a list of strings")
+	}
+	*f = formats
+	return nil
+}
+
+// DefaultConfigPaths are where LoadConfig looks for the configuration, in
+// order; the first one is written with defaults when none exists
+var DefaultConfigPaths = []string{"config/config.json", "config/config.yaml", "config/config.yml"}
+
+// LoadConfig loads the configuration from the first of DefaultConfigPaths
+// that exists
 func LoadConfig() (*Config, error) {
-	// Default config path
-	configPath := "config/config.json"
+	for _, path := range DefaultConfigPaths {
+		if _, err := os.Stat(path); err == nil {
+			return LoadConfigFile(path)
+		}
+	}
+	return LoadConfigFile(DefaultConfigPaths[0])
+}
 
+// LoadConfigFile loads the configuration from a JSON or, by its .yaml or
+// .yml extension, YAML file. A missing file is created with the defaults in
+// the same format.
+func LoadConfigFile(configPath string) (*Config, error) {
 	// Check if config file exists
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		// Create default config
-		config := &Config{
-			Test: struct {
-				Concurrent bool `json:"concurrent"`
-				MaxWorkers int  `json:"max_workers"`
-				Timeout    int  `json:"timeout"`
-				Retry      struct {
-					Attempts int `json:"attempts"`
-					Delay    int `json:"delay"`
-				} `json:"retry"`
-			}{
-				Concurrent: true,
-				MaxWorkers: 5,
-				Timeout:    30,
-				Retry: struct {
-					Attempts int `json:"attempts"`
-					Delay    int `json:"delay"`
-				}{
-					Attempts: 3,
-					Delay:    5,
-				},
-			},
-			Reporting: struct {
-				Format    string `json:"format"`
-				OutputDir string `json:"output_dir"`
-				Detailed  bool   `json:"detailed"`
-			}{
-				Format:    "json",
-				OutputDir: "reports",
-				Detailed:  true,
-			},
-			LLM: llm.NewDefaultConfig(),
-		}
+		config := &Config{LLM: llm.NewDefaultConfig()}
+		config.Test.Concurrent = true
+		config.Test.MaxWorkers = 5
+		config.Test.Timeout = 30
+		config.Test.Retry.Attempts = 3
+		config.Test.Retry.Delay = 5
+		config.Reporting.Format = Formats{"json"}
+		config.Reporting.OutputDir = "reports"
+		config.Reporting.Detailed = true
+		config.Reporting.Sort = "failed-first"
+		config.Golden.Dir = "golden"
+		config.Generation.ArrayItems = types.ArrayBounds{Min: 1, Max: 3}
 
 		// Create config directory if it doesn't exist
 		if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
@@ -77,7 +266,7 @@ func LoadConfig() (*Config, error) {
 		}
 
 		// Write default config
-		data, err := json.MarshalIndent(config, "", "  ")
+		data, err := marshalFile(configPath, config)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal default config: %v", err)
 		}
@@ -86,6 +275,8 @@ func LoadConfig() (*Config, error) {
 			return nil, fmt.Errorf("failed to write default config: %v", err)
 		}
 
+		applyEnvOverrides(config)
+
 		return config, nil
 	}
 
@@ -95,6 +286,16 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %v", err)
 	}
 
+	if data, err = toJSON(configPath, data); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %v", err)
+	}
+
+	// Expand ${VAR} references before parsing so that every setting can
+	// come from the environment
+	if data, err = ExpandEnvJSON(data); err != nil {
+		return nil, fmt.Errorf("invalid config file: %v", err)
+	}
+
 	// Parse config
 	var config Config
 	if err := json.Unmarshal(data, &config); err != nil {
@@ -107,5 +308,109 @@ func LoadConfig() (*Config, error) {
 		config.LLM = llm.NewDefaultConfig()
 	}
 
+	// Keep golden files out of the working directory for configs written
+	// before golden.dir existed
+	if config.Golden.Dir == "" {
+		config.Golden.Dir = "golden"
+	}
+
+	if err := config.Auth.resolveTokenEnv("auth"); err != nil {
+		return nil, err
+	}
+	for name, profile := range config.AuthProfiles {
+		if err := profile.resolveTokenEnv("auth_profiles." + name); err != nil {
+			return nil, err
+		}
+		config.AuthProfiles[name] = profile
+	}
+	applyEnvOverrides(&config)
+
 	return &config, nil
 }
+
+// redactedSecret replaces secrets in dumped configs
+const redactedSecret = "[REDACTED]"
+
+// Redacted returns a copy of the config with tokens, client secrets, API
+// keys, sink headers and the notification webhook masked, safe to print or attach to bug reports
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	redacted.Auth = c.Auth.redacted()
+	redacted.Notify.WebhookURL = redact(c.Notify.WebhookURL)
+	if u, err := url.Parse(c.Proxy); err == nil {
+		redacted.Proxy = u.Redacted()
+	}
+	if c.AuthProfiles != nil {
+		redacted.AuthProfiles = make(map[string]AuthConfig, len(c.AuthProfiles))
+		for name, profile := range c.AuthProfiles {
+			redacted.AuthProfiles[name] = profile.redacted()
+		}
+	}
+	if c.Reporting.Sinks != nil {
+		redacted.Reporting.Sinks = make([]SinkConfig, len(c.Reporting.Sinks))
+		for i, sink := range c.Reporting.Sinks {
+			headers := make(map[string]string, len(sink.Headers))
+			for name, value := range sink.Headers {
+				headers[name] = redact(value)
+			}
+			sink.Headers = headers
+			// Webhook URLs such as Slack's carry their token in the path
+			sink.URL = redact(sink.URL)
+			redacted.Reporting.Sinks[i] = sink
+		}
+	}
+	if c.LLM != nil {
+		llmConfig := *c.LLM
+		llmConfig.APIKey = redact(llmConfig.APIKey)
+		redacted.LLM = &llmConfig
+	}
+	return &redacted
+}
+
+func (a AuthConfig) redacted() AuthConfig {
+	a.Value = redact(a.Value)
+	a.Token = redact(a.Token)
+	a.ClientSecret = redact(a.ClientSecret)
+	a.RefreshToken = redact(a.RefreshToken)
+	return a
+}
+
+// redact masks a secret, leaving unset values empty so they stay distinguishable
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redactedSecret
+}
+
+// resolveTokenEnv reads the secret named by TokenEnv into Token, or into Value
+// for apikey auth
+func (a *AuthConfig) resolveTokenEnv(name string) error {
+	if a.TokenEnv == "" {
+		return nil
+	}
+	secret, ok := os.LookupEnv(a.TokenEnv)
+	if !ok {
+		return fmt.Errorf("invalid %s.token_env: environment variable %s is not set", name, a.TokenEnv)
+	}
+	if a.Type == "apikey" {
+		a.Value = secret
+	} else {
+		a.Token = secret
+	}
+	return nil
+}
+
+// applyEnvOverrides fills secrets left empty in the config file from the environment
+func applyEnvOverrides(config *Config) {
+	if config.LLM.APIKey == "" {
+		if config.LLM.Provider == "anthropic" {
+			config.LLM.APIKey = os.Getenv("ANTHROPIC_API_KEY")
+		} else {
+			config.LLM.APIKey = os.Getenv("OPENAI_API_KEY")
+		}
+	}
+	if config.Auth.Token == "" {
+		config.Auth.Token = os.Getenv("AUTH_TOKEN")
+	}
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// DefaultEnvFile is loaded at startup when present
+const DefaultEnvFile = ".env"
+
+// LoadEnvFile populates the environment from a .env file. Variables that are
+// already set take precedence over the file. A missing file is only an error
+// when required is true.
+func LoadEnvFile(path string, required bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) && !required {
+			return nil
+		}
+		return fmt.Errorf("failed to open env file: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return fmt.Errorf("%s:%d: expected KEY=VALUE", path, lineNum)
+		}
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return fmt.Errorf("%s:%d: empty variable name", path, lineNum)
+		}
+
+		if _, exists := os.LookupEnv(key); exists {
+			continue
+		}
+		if err := os.Setenv(key, parseEnvValue(strings.TrimSpace(value))); err != nil {
+			return fmt.Errorf("failed to set %s: %v", key, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read env file: %v", err)
+	}
+
+	return nil
+}
+
+// parseEnvValue strips quotes and trailing comments from a .env value
+func parseEnvValue(value string) string {
+	if len(value) >= 2 {
+		switch value[0] {
+		case '"':
+			if end := strings.LastIndexByte(value, '"'); end > 0 {
+				return strings.NewReplacer(`\n`, "\n", `\"`, `"`, `\\`, `\`).Replace(value[1:end])
+			}
+		case '\'':
+			if end := strings.LastIndexByte(value, '\''); end > 0 {
+				return value[1:end]
+			}
+		}
+	}
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return value
+}
+
+// envReference matches ${VAR} and ${VAR:-default} tokens
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// ExpandEnv replaces ${VAR} tokens in value with the environment variable's
+// value, and ${VAR:-default} tokens with the default when the variable is
+// unset or empty. Referencing an unset variable without a default is an
+// error; a bare $ is left alone.
+func ExpandEnv(value string) (string, error) {
+	var missing []string
+	expanded := envReference.ReplaceAllStringFunc(value, func(token string) string {
+		match := envReference.FindStringSubmatch(token)
+		name, hasDefault, fallback := match[1], match[2] != "", match[3]
+		v, ok := os.LookupEnv(name)
+		if hasDefault && v == "" {
+			return fallback
+		}
+		if !ok {
+			missing = append(missing, name)
+		}
+		return v
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable %s is not set", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
+
+// ExpandEnvJSON applies ExpandEnv to every string, object keys included, of
+// a JSON document. Key order and the other values are kept as written.
+// Malformed JSON is returned unchanged for the caller's parser to report, so
+// an error always means an unset variable.
+func ExpandEnvJSON(data []byte) ([]byte, error) {
+	// frame is an open object or array and the number of tokens written in it
+	type frame struct {
+		object bool
+		n      int
+	}
+	var stack []frame
+	var out bytes.Buffer
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return data, nil
+		}
+
+		delim, isDelim := token.(json.Delim)
+		closing := isDelim && (delim == '}' || delim == ']')
+		if len(stack) > 0 && !closing {
+			top := &stack[len(stack)-1]
+			switch {
+			case top.object && top.n%2 == 1:
+				out.WriteByte(':')
+			case top.n > 0:
+				out.WriteByte(',')
+			}
+			top.n++
+		}
+
+		switch t := token.(type) {
+		case json.Delim:
+			out.WriteRune(rune(t))
+			if closing {
+				stack = stack[:len(stack)-1]
+			} else {
+				stack = append(stack, frame{object: t == '{'})
+			}
+		case string:
+			expanded, err := ExpandEnv(t)
+			if err != nil {
+				return nil, err
+			}
+			encoded, err := json.Marshal(expanded)
+			if err != nil {
+				return nil, err
+			}
+			out.Write(encoded)
+		default:
+			encoded, err := json.Marshal(t)
+			if err != nil {
+				return nil, err
+			}
+			out.Write(encoded)
+		}
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"github.com/oasdiff/yaml"
+)
+
+// isYAML reports whether path names a YAML file by its extension
+func isYAML(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// toJSON converts the content of a YAML file to JSON, so that YAML and JSON
+// files share the json struct tags and parsing; JSON is returned as is
+func toJSON(path string, data []byte) ([]byte, error) {
+	if !isYAML(path) {
+		return data, nil
+	}
+	return yaml.YAMLToJSON(data)
+}
+
+// marshalFile encodes v as YAML or indented JSON, as path's extension asks
+func marshalFile(path string, v interface{}) ([]byte, error) {
+	if isYAML(path) {
+		return yaml.Marshal(v)
+	}
+	return json.MarshalIndent(v, "", "  ")
+}
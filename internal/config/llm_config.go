@@ -14,12 +14,15 @@ type LLMConfig struct {
 	BaseURL  string `json:"base_url"` // Optional, for custom endpoints
 }
 
-// LoadLLMConfig loads LLM configuration from a file
+// LoadLLMConfig loads LLM configuration from a JSON or YAML file
 func LoadLLMConfig(path string) (*LLMConfig, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read LLM config file: %v", err)
 	}
+	if data, err = toJSON(path, data); err != nil {
+		return nil, fmt.Errorf("failed to parse LLM config: %v", err)
+	}
 
 	var config LLMConfig
 	if err := json.Unmarshal(data, &config); err != nil {
@@ -40,9 +43,10 @@ func LoadLLMConfig(path string) (*LLMConfig, error) {
 	return &config, nil
 }
 
-// SaveLLMConfig saves LLM configuration to a file
+// SaveLLMConfig saves LLM configuration to a file, as YAML when its
+// extension is .yaml or .yml
 func SaveLLMConfig(config *LLMConfig, path string) error {
-	data, err := json.MarshalIndent(config, "", "  ")
+	data, err := marshalFile(path, config)
 	if err != nil {
 		return fmt.Errorf("failed to marshal LLM config: %v", err)
 	}
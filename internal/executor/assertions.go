@@ -0,0 +1,82 @@
+package executor
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"auto-api-tester/internal/types"
+)
+
+// checkHeaders verifies that the response carries every expected header,
+// returning a single error describing all missing or mismatched headers
+func checkHeaders(header http.Header, expected []types.HeaderAssertion) error {
+	var problems []string
+	for _, assertion := range expected {
+		values, ok := header[http.CanonicalHeaderKey(assertion.Name)]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("missing header %s", assertion.Name))
+			continue
+		}
+		value := strings.Join(values, ", ")
+
+		if assertion.Value != "" && value != assertion.Value {
+			problems = append(problems, fmt.Sprintf("header %s: expected %q, got %q", assertion.Name, assertion.Value, value))
+		}
+
+		if assertion.Pattern != "" {
+			re, err := regexp.Compile(assertion.Pattern)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("header %s: invalid pattern %q: %v", assertion.Name, assertion.Pattern, err))
+			} else if !re.MatchString(value) {
+				problems = append(problems, fmt.Sprintf("header %s: value %q does not match %q", assertion.Name, value, assertion.Pattern))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("response header assertions failed: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// checkDownload verifies a download response against the endpoint's expected
+// content type and minimum size. Without min_response_bytes the body must not
+// be empty unless allowEmpty is set.
+func checkDownload(contentType string, size int, testData *types.EndpointTestData, allowEmpty bool) error {
+	var problems []string
+
+	if expected := testData.ExpectedContentType; expected != "" && !matchesMediaType(contentType, expected) {
+		problems = append(problems, fmt.Sprintf("expected content type %s, got %q", expected, contentType))
+	}
+
+	switch {
+	case testData.MinResponseBytes > 0:
+		if size < testData.MinResponseBytes {
+			problems = append(problems, fmt.Sprintf("expected at least %d bytes, got %d", testData.MinResponseBytes, size))
+		}
+	case size == 0 && !allowEmpty:
+		problems = append(problems, "body is empty")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("download assertions failed for %s response: %s", contentType, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// matchesMediaType compares media types ignoring parameters and case;
+// expected may use a wildcard subtype such as image/*
+func matchesMediaType(contentType, expected string) bool {
+	actual, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	expected = strings.ToLower(strings.TrimSpace(expected))
+	if prefix, ok := strings.CutSuffix(expected, "/*"); ok {
+		return strings.HasPrefix(actual, prefix+"/")
+	}
+	return actual == expected
+}
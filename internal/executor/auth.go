@@ -0,0 +1,294 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"auto-api-tester/internal/types"
+)
+
+// Auth types
+const (
+	AuthBearer = "bearer"
+	AuthAPIKey = "apikey"
+)
+
+// AuthConfig configures the credentials sent with every request. Bearer auth,
+// the default, sends Token; when TokenURL is set the token is obtained and
+// refreshed with OAuth2, using the refresh_token grant if RefreshToken is set
+// and client_credentials otherwise. API key auth sends Value in the
+// HeaderName header, X-API-Key by default.
+type AuthConfig struct {
+	Type       string
+	HeaderName string
+	Value      string
+
+	Token        string
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	RefreshToken string
+	Scopes       []string
+}
+
+// NoAuthProfile is the auth profile of endpoints that must be called without credentials
+const NoAuthProfile = "none"
+
+// authenticator holds the current bearer token and refreshes it when it expires
+type authenticator struct {
+	config AuthConfig
+	client *http.Client
+
+	mu           sync.Mutex
+	token        string
+	refreshToken string
+}
+
+// validate checks the auth type
+func (c AuthConfig) validate() error {
+	switch c.Type {
+	case "", AuthBearer, AuthAPIKey:
+		return nil
+	}
+	return fmt.Errorf("unknown auth type %q: expected %s or %s", c.Type, AuthBearer, AuthAPIKey)
+}
+
+// headerName is the header carrying an API key
+func (c AuthConfig) headerName() string {
+	if c.HeaderName == "" {
+		return "X-API-Key"
+	}
+	return c.HeaderName
+}
+
+// newAuthenticator returns nil when no authentication is configured
+func newAuthenticator(config AuthConfig, client *http.Client) *authenticator {
+	if config.Type == AuthAPIKey {
+		if config.Value == "" {
+			return nil
+		}
+	} else if config.Token == "" && config.TokenURL == "" {
+		return nil
+	}
+	return &authenticator{
+		config:       config,
+		client:       client,
+		token:        config.Token,
+		refreshToken: config.RefreshToken,
+	}
+}
+
+// canRefresh reports whether a rejected token can be replaced
+func (a *authenticator) canRefresh() bool {
+	return a.config.TokenURL != ""
+}
+
+// current returns the token to send, fetching the first one if needed
+func (a *authenticator) current(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	token := a.token
+	a.mu.Unlock()
+
+	if token == "" && a.canRefresh() {
+		return a.refresh(ctx, "")
+	}
+	return token, nil
+}
+
+// refresh replaces the stale token. Concurrent callers that were rejected with
+// the same token share a single refresh.
+func (a *authenticator) refresh(ctx context.Context, stale string) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != stale {
+		return a.token, nil
+	}
+
+	form := url.Values{}
+	if a.refreshToken != "" {
+		form.Set("grant_type", "refresh_token")
+		form.Set("refresh_token", a.refreshToken)
+	} else {
+		form.Set("grant_type", "client_credentials")
+	}
+	if len(a.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(a.config.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if a.config.ClientID != "" {
+		req.SetBasicAuth(url.QueryEscape(a.config.ClientID), url.QueryEscape(a.config.ClientSecret))
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var token struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("token response has no access_token")
+	}
+
+	a.token = token.AccessToken
+	if token.RefreshToken != "" {
+		a.refreshToken = token.RefreshToken
+	}
+	return a.token, nil
+}
+
+// send performs the request with the current bearer token. A 401 triggers one
+// token refresh and retry. It returns the request that was actually sent.
+func (e *TestExecutor) send(req *http.Request, testData *types.EndpointTestData) (*http.Response, *http.Request, error) {
+	auth, err := e.authFor(testData, req.URL)
+	if err != nil {
+		return nil, req, err
+	}
+
+	if auth == nil {
+		resp, err := e.client.Do(req)
+		return resp, req, err
+	}
+
+	// API keys are static; test data that sets the header itself wins
+	if auth.config.Type == AuthAPIKey {
+		if name := auth.config.headerName(); !hasHeader(testData.Headers, name) {
+			req.Header.Set(name, auth.config.Value)
+		}
+		resp, err := e.client.Do(req)
+		return resp, req, err
+	}
+
+	// Test data that sets its own Authorization header opts out
+	if hasHeader(testData.Headers, "Authorization") {
+		resp, err := e.client.Do(req)
+		return resp, req, err
+	}
+
+	token, err := auth.current(req.Context())
+	if err != nil {
+		return nil, req, fmt.Errorf("failed to obtain auth token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := e.client.Do(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || !auth.canRefresh() {
+		return resp, req, err
+	}
+	resp.Body.Close()
+
+	fresh, err := auth.refresh(req.Context(), token)
+	if err != nil {
+		return nil, req, fmt.Errorf("failed to refresh auth token: %w", err)
+	}
+	retry, err := rewindRequest(req)
+	if err != nil {
+		return nil, req, err
+	}
+	retry.Header.Set("Authorization", "Bearer "+fresh)
+
+	resp, err = e.client.Do(retry)
+	return resp, retry, err
+}
+
+// authFor returns the authenticator for the endpoint's auth profile, then for
+// the profile of the service the request targets, and otherwise the global
+// one. Profile authenticators are created on first
+// use so each keeps its own token across requests.
+func (e *TestExecutor) authFor(testData *types.EndpointTestData, target *url.URL) (*authenticator, error) {
+	name := testData.AuthProfile
+	if name == "" {
+		name = e.serviceProfile(target)
+	}
+	switch name {
+	case "":
+		return e.auth, nil
+	case NoAuthProfile:
+		return nil, nil
+	}
+
+	e.profilesMu.Lock()
+	defer e.profilesMu.Unlock()
+
+	if auth, ok := e.profiles[name]; ok {
+		return auth, nil
+	}
+	config, ok := e.config.AuthProfiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown auth profile %q", name)
+	}
+	auth := newAuthenticator(config, e.client)
+	e.profiles[name] = auth
+	return auth, nil
+}
+
+// serviceProfile returns the auth profile configured for the service a URL
+// belongs to. Keys with a scheme match as URL prefixes, the longest winning;
+// bare keys match the host.
+func (e *TestExecutor) serviceProfile(target *url.URL) string {
+	if target == nil {
+		return ""
+	}
+	full := target.Scheme + "://" + target.Host + target.Path
+	profile, longest := "", -1
+	for service, name := range e.config.ServiceAuth {
+		var matched bool
+		if strings.Contains(service, "://") {
+			prefix := strings.TrimSuffix(service, "/")
+			matched = full == prefix || strings.HasPrefix(full, prefix+"/")
+		} else {
+			matched = strings.EqualFold(target.Host, service)
+		}
+		if matched && len(service) > longest {
+			profile, longest = name, len(service)
+		}
+	}
+	return profile
+}
+
+// hasHeader reports whether headers sets name, ignoring case
+func hasHeader(headers map[string]string, name string) bool {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// headerValue returns the value headers sets for name, ignoring case
+func headerValue(headers map[string]string, name string) string {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,52 @@
+package executor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// textApplicationTypes are application/* media types that carry text
+var textApplicationTypes = map[string]bool{
+	"application/json":                  true,
+	"application/xml":                   true,
+	"application/javascript":            true,
+	"application/x-www-form-urlencoded": true,
+	"application/yaml":                  true,
+	"application/x-yaml":                true,
+	"application/graphql":               true,
+}
+
+// isBinaryContentType reports whether a response body should be treated as
+// opaque bytes (downloads, images, archives) rather than text
+func isBinaryContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	switch {
+	case strings.HasPrefix(mediaType, "image/svg"):
+		return false
+	case strings.HasPrefix(mediaType, "image/"),
+		strings.HasPrefix(mediaType, "audio/"),
+		strings.HasPrefix(mediaType, "video/"),
+		strings.HasPrefix(mediaType, "font/"):
+		return true
+	case strings.HasPrefix(mediaType, "application/"):
+		if strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml") {
+			return false
+		}
+		return !textApplicationTypes[mediaType]
+	}
+	return false
+}
+
+// summarizeBinary describes a binary body by size and checksum instead of its content
+func summarizeBinary(contentType string, body []byte) (string, string) {
+	sum := sha256.Sum256(body)
+	checksum := hex.EncodeToString(sum[:])
+	return fmt.Sprintf("binary response (%s): %d bytes, sha256 %s", contentType, len(body), checksum), checksum
+}
@@ -0,0 +1,136 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// fileValuePrefix marks a multipart field value as the path of a file to upload
+const fileValuePrefix = "@file:"
+
+// encodeBody serializes a request body for the Content-Type of its test
+// case: form fields for application/x-www-form-urlencoded, parts for
+// multipart/form-data and JSON otherwise. It returns the Content-Type to
+// send, which for multipart carries the generated boundary, or "" to keep
+// the test case's own.
+func encodeBody(body interface{}, contentType string) ([]byte, string, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		fields, err := formFields(body)
+		if err != nil {
+			return nil, "", err
+		}
+		values := url.Values{}
+		for _, name := range sortedFieldNames(fields) {
+			for _, value := range fieldValues(fields[name]) {
+				values.Add(name, value)
+			}
+		}
+		return []byte(values.Encode()), "", nil
+
+	case "multipart/form-data":
+		fields, err := formFields(body)
+		if err != nil {
+			return nil, "", err
+		}
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		for _, name := range sortedFieldNames(fields) {
+			for _, value := range fieldValues(fields[name]) {
+				if err := writePart(writer, name, value); err != nil {
+					return nil, "", err
+				}
+			}
+		}
+		if err := writer.Close(); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), writer.FormDataContentType(), nil
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	return data, "", nil
+}
+
+// formFields returns the fields of a form body, which must be an object
+func formFields(body interface{}) (map[string]interface{}, error) {
+	fields, ok := body.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("form request body must be an object, got %T", body)
+	}
+	return fields, nil
+}
+
+// sortedFieldNames returns the field names in order so bodies are stable
+func sortedFieldNames(fields map[string]interface{}) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fieldValues formats a form field: an array becomes one value per item,
+// an object its JSON and anything else its text
+func fieldValues(value interface{}) []string {
+	switch v := value.(type) {
+	case nil:
+		return []string{""}
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, fieldValues(item)...)
+		}
+		return values
+	case map[string]interface{}:
+		data, _ := json.Marshal(v)
+		return []string{string(data)}
+	}
+	return []string{formatValue(value)}
+}
+
+// writePart adds a multipart field, uploading the file when the value is
+// "@file:path"
+func writePart(writer *multipart.Writer, name, value string) error {
+	path, isFile := strings.CutPrefix(value, fileValuePrefix)
+	if !isFile {
+		return writer.WriteField(name, value)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read upload for field %s: %w", name, err)
+	}
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{
+		"name":     name,
+		"filename": filepath.Base(path),
+	}))
+	header.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	_, err = part.Write(content)
+	return err
+}
@@ -0,0 +1,127 @@
+package executor
+
+import (
+	"sync"
+	"time"
+)
+
+// BreakerConfig configures the per-host circuit breaker
+type BreakerConfig struct {
+	// Threshold is the number of consecutive failures within Window that
+	// opens the circuit; zero disables the breaker
+	Threshold int
+	Window    time.Duration
+	// Cooldown is how long an open circuit fast-fails before letting a probe through
+	Cooldown time.Duration
+}
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// hostCircuit tracks the failures of one host
+type hostCircuit struct {
+	state        circuitState
+	failures     int
+	firstFailure time.Time
+	openedAt     time.Time
+	probing      bool
+}
+
+// circuitBreaker fast-fails requests to hosts that keep failing
+type circuitBreaker struct {
+	config BreakerConfig
+
+	mu    sync.Mutex
+	hosts map[string]*hostCircuit
+}
+
+// newCircuitBreaker returns nil when the breaker is disabled
+func newCircuitBreaker(config BreakerConfig) *circuitBreaker {
+	if config.Threshold <= 0 {
+		return nil
+	}
+	return &circuitBreaker{config: config, hosts: make(map[string]*hostCircuit)}
+}
+
+func (b *circuitBreaker) circuit(host string) *hostCircuit {
+	c, ok := b.hosts[host]
+	if !ok {
+		c = &hostCircuit{}
+		b.hosts[host] = c
+	}
+	return c
+}
+
+// allow reports whether a request to host may be sent. Once the cooldown of
+// an open circuit has passed, a single probe request is let through.
+func (b *circuitBreaker) allow(host string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuit(host)
+	switch c.state {
+	case circuitOpen:
+		if time.Since(c.openedAt) < b.config.Cooldown {
+			return false
+		}
+		c.state = circuitHalfOpen
+		c.probing = true
+		return true
+	case circuitHalfOpen:
+		if c.probing {
+			return false
+		}
+		c.probing = true
+		return true
+	}
+	return true
+}
+
+// record updates the host's circuit with the outcome of a request
+func (b *circuitBreaker) record(host string, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuit(host)
+	now := time.Now()
+
+	if c.state == circuitHalfOpen {
+		c.probing = false
+		if failed {
+			c.state = circuitOpen
+			c.openedAt = now
+			return
+		}
+		*c = hostCircuit{}
+		return
+	}
+
+	if !failed {
+		c.failures = 0
+		return
+	}
+
+	if c.failures == 0 || (b.config.Window > 0 && now.Sub(c.firstFailure) > b.config.Window) {
+		c.failures = 0
+		c.firstFailure = now
+	}
+	c.failures++
+	if c.failures >= b.config.Threshold {
+		c.state = circuitOpen
+		c.openedAt = now
+	}
+}
+
+// isHostFailure reports whether a result indicates the host itself is
+// struggling: no complete response, or a 5xx
+func isHostFailure(result TestResult) bool {
+	if result.Status == "ERROR" {
+		return true
+	}
+	return result.StatusCode >= 500
+}
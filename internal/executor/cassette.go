@@ -0,0 +1,177 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Cassette modes
+const (
+	CassetteRecord = "record"
+	CassetteReplay = "replay"
+)
+
+// CassetteConfig records responses to a file or replays them instead of
+// calling the server
+type CassetteConfig struct {
+	// Mode is "record" or "replay"; empty disables the cassette
+	Mode string
+	Path string
+}
+
+// interaction is one recorded request/response pair
+type interaction struct {
+	Request struct {
+		Method string `json:"method"`
+		URL    string `json:"url"`
+		Body   string `json:"body,omitempty"`
+	} `json:"request"`
+	Response struct {
+		StatusCode int         `json:"status_code"`
+		Header     http.Header `json:"header"`
+		Body       string      `json:"body"`
+	} `json:"response"`
+}
+
+// cassette is an http.RoundTripper that records or replays interactions
+type cassette struct {
+	mode      string
+	transport http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []interaction
+	// used marks replayed interactions so repeated requests get successive responses
+	used []bool
+}
+
+// newCassette creates the cassette for config, loading the file in replay mode
+func newCassette(config CassetteConfig, transport http.RoundTripper) (*cassette, error) {
+	c := &cassette{mode: config.Mode, transport: transport}
+	switch config.Mode {
+	case CassetteRecord:
+	case CassetteReplay:
+		data, err := os.ReadFile(config.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cassette: %w", err)
+		}
+		if err := json.Unmarshal(data, &c.interactions); err != nil {
+			return nil, fmt.Errorf("failed to parse cassette %s: %w", config.Path, err)
+		}
+		c.used = make([]bool, len(c.interactions))
+	default:
+		return nil, fmt.Errorf("unknown cassette mode %q (want %s or %s)", config.Mode, CassetteRecord, CassetteReplay)
+	}
+	return c, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (c *cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		if reqBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	if c.mode == CassetteReplay {
+		return c.replay(req, reqBody)
+	}
+	return c.record(req, reqBody)
+}
+
+// record sends the request and stores the exchange
+func (c *cassette) record(req *http.Request, reqBody []byte) (*http.Response, error) {
+	resp, err := c.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	var entry interaction
+	entry.Request.Method = req.Method
+	entry.Request.URL = req.URL.String()
+	entry.Request.Body = string(reqBody)
+	entry.Response.StatusCode = resp.StatusCode
+	entry.Response.Header = resp.Header.Clone()
+	entry.Response.Body = string(body)
+
+	c.mu.Lock()
+	c.interactions = append(c.interactions, entry)
+	c.mu.Unlock()
+	return resp, nil
+}
+
+// replay serves the first unused recorded response matching the request's
+// method, URL and body, falling back to the last match once all are used
+func (c *cassette) replay(req *http.Request, reqBody []byte) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Query parameters are compared regardless of order, which cassettes
+	// recorded by earlier versions didn't keep stable
+	url := canonicalURL(req.URL.String())
+	match := -1
+	for i, entry := range c.interactions {
+		if entry.Request.Method != req.Method || canonicalURL(entry.Request.URL) != url || entry.Request.Body != string(reqBody) {
+			continue
+		}
+		match = i
+		if !c.used[i] {
+			break
+		}
+	}
+	if match < 0 {
+		return nil, fmt.Errorf("no recorded response in cassette for %s %s", req.Method, req.URL)
+	}
+	c.used[match] = true
+
+	entry := c.interactions[match]
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", entry.Response.StatusCode, http.StatusText(entry.Response.StatusCode)),
+		StatusCode:    entry.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        entry.Response.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader([]byte(entry.Response.Body))),
+		ContentLength: int64(len(entry.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// save writes the recorded interactions to path
+func (c *cassette) save(path string) error {
+	c.mu.Lock()
+	data, err := json.MarshalIndent(c.interactions, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// SaveCassette writes the interactions recorded during the run to the cassette file.
+// It does nothing unless the executor is recording.
+func (e *TestExecutor) SaveCassette() error {
+	if e.cassette == nil || e.cassette.mode != CassetteRecord {
+		return nil
+	}
+	return e.cassette.save(e.config.Cassette.Path)
+}
@@ -0,0 +1,116 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"auto-api-tester/internal/types"
+)
+
+// extractVariables stores the values at a test case's extract paths in the
+// response body as variables. A path matching several values, such as one
+// with a [*] wildcard, stores them as a list.
+func (e *TestExecutor) extractVariables(body []byte, extract map[string]string) error {
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return fmt.Errorf("failed to extract variables: response is not JSON: %v", err)
+	}
+
+	names := make([]string, 0, len(extract))
+	for name := range extract {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := make(map[string]interface{}, len(names))
+	for _, name := range names {
+		segments, err := parsePath(extract[name])
+		if err != nil {
+			return fmt.Errorf("failed to extract %s: %v", name, err)
+		}
+		matches := selectPath(document, segments)
+		switch len(matches) {
+		case 0:
+			return fmt.Errorf("failed to extract %s: no value at %s", name, extract[name])
+		case 1:
+			values[name] = matches[0]
+		default:
+			values[name] = matches
+		}
+	}
+	for name, value := range values {
+		e.vars.Set(name, value)
+	}
+	return nil
+}
+
+// variableDependencies returns, for each test case, the indexes of the cases
+// extracting a variable it references
+func variableDependencies(testCases []*types.EndpointTestData) [][]int {
+	producers := make(map[string][]int)
+	for i, testData := range testCases {
+		if testData == nil {
+			continue
+		}
+		for name := range testData.Extract {
+			producers[name] = append(producers[name], i)
+		}
+	}
+
+	deps := make([][]int, len(testCases))
+	if len(producers) == 0 {
+		return deps
+	}
+	for i, testData := range testCases {
+		if testData == nil {
+			continue
+		}
+		for _, name := range variableRefs(testData) {
+			for _, j := range producers[name] {
+				if j != i {
+					deps[i] = append(deps[i], j)
+				}
+			}
+		}
+	}
+	return deps
+}
+
+// variableRefs returns the names of the variables referenced in a test
+// case's path and query parameters, headers and body, in sorted order
+func variableRefs(testData *types.EndpointTestData) []string {
+	seen := make(map[string]bool)
+	var walk func(value interface{})
+	walk = func(value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		case []interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		case string:
+			for _, match := range placeholderPattern.FindAllStringSubmatch(v, -1) {
+				if segments, err := parsePath(match[1]); err == nil && len(segments) > 0 {
+					seen[segments[0].key] = true
+				}
+			}
+		}
+	}
+	walk(testData.PathParams)
+	walk(testData.QueryParams)
+	walk(testData.Body)
+	for _, value := range testData.Headers {
+		walk(value)
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,128 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"auto-api-tester/internal/types"
+)
+
+// maxReportedDiffs limits how many differences are included in an error message
+const maxReportedDiffs = 10
+
+// diffError is an assertion failure carrying the structured differences
+// behind it, so reports can show them field by field
+type diffError struct {
+	message string
+	diffs   []types.FieldDiff
+}
+
+// newDiffError summarizes diffs after message, listing at most maxReportedDiffs
+func newDiffError(message string, diffs []types.FieldDiff) *diffError {
+	lines := make([]string, 0, min(len(diffs), maxReportedDiffs)+1)
+	for i, diff := range diffs {
+		if i == maxReportedDiffs {
+			lines = append(lines, fmt.Sprintf("... and %d more", len(diffs)-maxReportedDiffs))
+			break
+		}
+		lines = append(lines, formatDiff(diff))
+	}
+	return &diffError{
+		message: fmt.Sprintf("%s: %s", message, strings.Join(lines, "; ")),
+		diffs:   diffs,
+	}
+}
+
+func (e *diffError) Error() string {
+	return e.message
+}
+
+// formatDiff renders a difference on one line
+func formatDiff(diff types.FieldDiff) string {
+	if diff.Problem != "" {
+		return fmt.Sprintf("%s: %s", diff.Path, diff.Problem)
+	}
+	return fmt.Sprintf("%s: expected %s, got %s", diff.Path, describeValue(diff.Expected), describeValue(diff.Actual))
+}
+
+// printDiffs shows the differences behind a failed assertion as an aligned
+// table headed by the test's name. The table is printed in one write so that
+// tables of tests running in parallel don't interleave.
+func printDiffs(name string, diffs []types.FieldDiff) {
+	width := len("PATH")
+	for _, diff := range diffs {
+		width = max(width, len(diff.Path))
+	}
+	var table strings.Builder
+	fmt.Fprintf(&table, "Differences for %s:\n  %-*s  %s\n", name, width, "PATH", "EXPECTED -> ACTUAL")
+	for _, diff := range diffs {
+		detail := diff.Problem
+		if detail == "" {
+			detail = describeValue(diff.Expected) + " -> " + describeValue(diff.Actual)
+		}
+		fmt.Fprintf(&table, "  %-*s  %s\n", width, diff.Path, detail)
+	}
+	fmt.Print(table.String())
+}
+
+// diffValues compares two decoded JSON values structurally and returns every difference
+func diffValues(path string, expected, actual interface{}) []types.FieldDiff {
+	var diffs []types.FieldDiff
+	switch exp := expected.(type) {
+	case map[string]interface{}:
+		act, ok := actual.(map[string]interface{})
+		if !ok {
+			return []types.FieldDiff{{Path: path, Problem: fmt.Sprintf("expected object, got %s", describeValue(actual))}}
+		}
+		for _, key := range sortedKeys(exp) {
+			actValue, exists := act[key]
+			if !exists {
+				diffs = append(diffs, types.FieldDiff{Path: path + "." + key, Expected: exp[key], Problem: "missing"})
+				continue
+			}
+			diffs = append(diffs, diffValues(path+"."+key, exp[key], actValue)...)
+		}
+		for _, key := range sortedKeys(act) {
+			if _, exists := exp[key]; !exists {
+				diffs = append(diffs, types.FieldDiff{Path: path + "." + key, Actual: act[key], Problem: "unexpected field"})
+			}
+		}
+	case []interface{}:
+		act, ok := actual.([]interface{})
+		if !ok {
+			return []types.FieldDiff{{Path: path, Problem: fmt.Sprintf("expected array, got %s", describeValue(actual))}}
+		}
+		if len(exp) != len(act) {
+			diffs = append(diffs, types.FieldDiff{Path: path, Problem: fmt.Sprintf("expected %d items, got %d", len(exp), len(act))})
+		}
+		for i := 0; i < len(exp) && i < len(act); i++ {
+			diffs = append(diffs, diffValues(fmt.Sprintf("%s[%d]", path, i), exp[i], act[i])...)
+		}
+	default:
+		if !reflect.DeepEqual(expected, actual) {
+			diffs = append(diffs, types.FieldDiff{Path: path, Expected: expected, Actual: actual})
+		}
+	}
+	return diffs
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// describeValue renders a decoded JSON value for use in diff messages
+func describeValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,80 @@
+package executor
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"auto-api-tester/internal/types"
+)
+
+// responseTracker fingerprints the responses of each endpoint across a run
+type responseTracker struct {
+	mu     sync.Mutex
+	hashes map[string]map[[sha256.Size]byte]int
+}
+
+func newResponseTracker() *responseTracker {
+	return &responseTracker{hashes: make(map[string]map[[sha256.Size]byte]int)}
+}
+
+// record adds a response body, normalized by removing ignored fields
+func (t *responseTracker) record(key string, body []byte, ignored []string) {
+	normalized, err := json.Marshal(ignoreFields(decodeBody(body), ignored))
+	if err != nil {
+		normalized = body
+	}
+	hash := sha256.Sum256(normalized)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.hashes[key] == nil {
+		t.hashes[key] = make(map[[sha256.Size]byte]int)
+	}
+	t.hashes[key][hash]++
+}
+
+// identical returns the number of calls for each endpoint that returned the
+// same response every time it was called more than once
+func (t *responseTracker) identical() map[string]int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	identical := make(map[string]int)
+	for key, hashes := range t.hashes {
+		if len(hashes) != 1 {
+			continue
+		}
+		for _, count := range hashes {
+			if count > 1 {
+				identical[key] = count
+			}
+		}
+	}
+	return identical
+}
+
+// flagIdenticalResponses fails the results of endpoints whose responses never
+// varied, which usually means a cache is serving stale content
+func (e *TestExecutor) flagIdenticalResponses(results []TestResult) []TestResult {
+	if e.responses == nil {
+		return results
+	}
+
+	identical := e.responses.identical()
+	for i, result := range results {
+		key := types.Endpoint{Method: result.Method, Path: result.Endpoint, Case: result.Case}.Key()
+		count, ok := identical[key]
+		if !ok {
+			continue
+		}
+		err := fmt.Errorf("response was identical across all %d calls; possible caching bug", count)
+		if result.Error != nil {
+			err = fmt.Errorf("%v; %w", result.Error, err)
+		}
+		results[i].Status = "FAILURE"
+		results[i].Error = err
+	}
+	return results
+}
@@ -0,0 +1,87 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"auto-api-tester/internal/types"
+)
+
+// GoldenConfig holds configuration for golden response comparison
+type GoldenConfig struct {
+	Enabled      bool
+	Dir          string
+	IgnoreFields []string
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// isReadMethod reports whether responses of the method are expected to be stable
+func isReadMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// goldenPath returns the golden file path for an endpoint and its test case
+func (e *TestExecutor) goldenPath(endpoint types.Endpoint) string {
+	path := endpoint.Path
+	if i := strings.Index(path, "://"); i >= 0 {
+		path = path[i+3:]
+	}
+	if endpoint.Case != "" {
+		path += "_" + endpoint.Case
+	}
+	name := unsafeFileChars.ReplaceAllString(endpoint.Method+"_"+path, "_")
+	return filepath.Join(e.config.Golden.Dir, strings.Trim(name, "_")+".json")
+}
+
+// compareGolden records the response body on the first run and diffs it
+// against the recorded golden file on subsequent runs
+func (e *TestExecutor) compareGolden(endpoint types.Endpoint, body []byte) error {
+	ignored := append(append([]string{}, e.config.IgnoreFields...), e.config.Golden.IgnoreFields...)
+	actual := ignoreFields(decodeBody(body), ignored)
+	path := e.goldenPath(endpoint)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create golden directory: %w", err)
+		}
+		data, err := json.MarshalIndent(actual, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal golden response: %w", err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return fmt.Errorf("failed to write golden file: %w", err)
+		}
+		e.printf("Recorded golden response for %s in %s\n", endpoint.Key(), path)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read golden file: %w", err)
+	}
+
+	var expected interface{}
+	if err := json.Unmarshal(data, &expected); err != nil {
+		return fmt.Errorf("failed to parse golden file %s: %w", path, err)
+	}
+	expected = ignoreFields(expected, ignored)
+
+	if diffs := diffValues("$", expected, actual); len(diffs) > 0 {
+		return newDiffError(fmt.Sprintf("response differs from golden file %s", path), diffs)
+	}
+	return nil
+}
+
+// decodeBody parses a JSON body, falling back to the raw string for non-JSON content
+func decodeBody(body []byte) interface{} {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return string(body)
+	}
+	return value
+}
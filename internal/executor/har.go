@@ -0,0 +1,240 @@
+package executor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// harLog is the root of an HTTP Archive (HAR 1.2) document
+type harLog struct {
+	Log harContent `json:"log"`
+}
+
+type harContent struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+
+	// started orders entries; StartedDateTime drops trailing zeros of the
+	// fraction, so it doesn't sort as a string
+	started time.Time
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harBody        `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harBody struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	// Encoding is "base64" when Text holds a binary body encoded as base64
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// harRecorder collects request/response pairs for the HAR export. Secrets
+// are redacted as they are recorded, so the file can be shared.
+type harRecorder struct {
+	// secretHeaders are the headers besides sensitiveHeaders whose values
+	// are redacted, such as the configured API key headers
+	secretHeaders []string
+
+	mu      sync.Mutex
+	entries []harEntry
+}
+
+// newHARRecorder returns a recorder that redacts the credentials config sends
+func newHARRecorder(config TestConfig) *harRecorder {
+	auths := []AuthConfig{config.Auth}
+	for _, profile := range config.AuthProfiles {
+		auths = append(auths, profile)
+	}
+
+	h := &harRecorder{}
+	for _, auth := range auths {
+		if auth.Type == AuthAPIKey {
+			h.secretHeaders = append(h.secretHeaders, auth.headerName())
+		}
+	}
+	return h
+}
+
+// record adds a completed exchange to the archive
+func (h *harRecorder) record(start time.Time, duration time.Duration, req *http.Request, reqBody []byte, resp *http.Response, respBody []byte) {
+	entry := harEntry{
+		StartedDateTime: start.Format(time.RFC3339Nano),
+		started:         start,
+		Time:            milliseconds(duration),
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         req.URL.Redacted(),
+			HTTPVersion: req.Proto,
+			Cookies:     []harNameValue{},
+			Headers:     h.headers(req.Header),
+			QueryString: []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    len(reqBody),
+		},
+		Response: harResponse{
+			Status:      resp.StatusCode,
+			StatusText:  http.StatusText(resp.StatusCode),
+			HTTPVersion: resp.Proto,
+			Cookies:     []harNameValue{},
+			Headers:     h.headers(resp.Header),
+			Content:     harContentOf(resp.Header.Get("Content-Type"), respBody),
+			HeadersSize: -1,
+			BodySize:    len(respBody),
+		},
+		Timings: harTimings{Wait: milliseconds(duration)},
+	}
+
+	for key, values := range req.URL.Query() {
+		for _, value := range values {
+			entry.Request.QueryString = append(entry.Request.QueryString, harNameValue{Name: key, Value: value})
+		}
+	}
+	if len(reqBody) > 0 {
+		entry.Request.PostData = &harPostData{
+			MimeType: req.Header.Get("Content-Type"),
+			Text:     string(reqBody),
+		}
+	}
+
+	h.mu.Lock()
+	h.entries = append(h.entries, entry)
+	h.mu.Unlock()
+}
+
+// write saves the recorded exchanges as a HAR file, ordered by start time
+func (h *harRecorder) write(path string) error {
+	h.mu.Lock()
+	entries := make([]harEntry, len(h.entries))
+	copy(entries, h.entries)
+	h.mu.Unlock()
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].started.Before(entries[j].started)
+	})
+
+	data, err := json.MarshalIndent(harLog{Log: harContent{
+		Version: "1.2",
+		Creator: harCreator{Name: "auto-api-tester", Version: "1.0"},
+		Entries: entries,
+	}}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal HAR: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create HAR directory: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// headers converts HTTP headers into sorted HAR name/value pairs, redacting
+// credentials and cookies
+func (h *harRecorder) headers(header http.Header) []harNameValue {
+	pairs := make([]harNameValue, 0, len(header))
+	for name, values := range header {
+		secret := isSensitiveHeader(name, nil) || slices.ContainsFunc(h.secretHeaders, func(s string) bool {
+			return strings.EqualFold(s, name)
+		})
+		for _, value := range values {
+			if secret {
+				value = redactedHeader
+			}
+			pairs = append(pairs, harNameValue{Name: name, Value: value})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].Name < pairs[j].Name
+	})
+	return pairs
+}
+
+// harContentOf describes a response body, encoding binary bodies as base64
+// since HAR text must be valid UTF-8
+func harContentOf(contentType string, body []byte) harBody {
+	content := harBody{Size: len(body), MimeType: contentType}
+	if isBinaryContentType(contentType) || !utf8.Valid(body) {
+		content.Text = base64.StdEncoding.EncodeToString(body)
+		content.Encoding = "base64"
+	} else {
+		content.Text = string(body)
+	}
+	return content
+}
+
+// milliseconds converts a duration into fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// WriteHAR writes every recorded request/response pair to a HAR file.
+// It is a no-op unless HAR recording is enabled.
+func (e *TestExecutor) WriteHAR(path string) error {
+	if e.har == nil {
+		return nil
+	}
+	return e.har.write(path)
+}
@@ -0,0 +1,44 @@
+package executor
+
+import (
+	"net/http"
+	"strings"
+)
+
+// sensitiveHeaders have their values redacted in reported response headers
+// unless TestConfig.UnredactedHeaders lists them
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// redactedHeader replaces the values of sensitive headers
+const redactedHeader = "[REDACTED]"
+
+// reportedHeaders copies response headers for the report, redacting the
+// values of sensitive headers that aren't in unredacted
+func reportedHeaders(header http.Header, unredacted []string) map[string][]string {
+	if len(header) == 0 {
+		return nil
+	}
+	reported := make(map[string][]string, len(header))
+	for name, values := range header {
+		if isSensitiveHeader(name, unredacted) {
+			values = []string{redactedHeader}
+		}
+		reported[name] = append([]string(nil), values...)
+	}
+	return reported
+}
+
+// isSensitiveHeader reports whether a header's values must be redacted
+func isSensitiveHeader(name string, unredacted []string) bool {
+	for _, allowed := range unredacted {
+		if strings.EqualFold(name, allowed) {
+			return false
+		}
+	}
+	for _, sensitive := range sensitiveHeaders {
+		if strings.EqualFold(name, sensitive) {
+			return true
+		}
+	}
+	return false
+}
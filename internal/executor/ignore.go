@@ -0,0 +1,113 @@
+package executor
+
+import (
+	"strconv"
+	"strings"
+)
+
+// fieldPattern is a parsed ignore-field pattern. A bare field name such as
+// "updatedAt" matches that field at any depth, while a JSONPath pattern such
+// as "$.items[*].id" or "$..meta.requestId" matches only where it selects.
+type fieldPattern []pathSegment
+
+// parseFieldPattern parses an ignore-field pattern
+func parseFieldPattern(pattern string) (fieldPattern, error) {
+	if !strings.HasPrefix(pattern, "$") {
+		return fieldPattern{{key: pattern, recursive: true}}, nil
+	}
+	return parsePath(pattern)
+}
+
+// checkFieldPatterns reports the first ignore-field pattern that isn't
+// valid JSONPath
+func checkFieldPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			if _, err := parseFieldPattern(pattern); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseFieldPatterns parses ignore-field patterns into matchers. Invalid
+// patterns, which NewTestExecutor rejects, are skipped.
+func parseFieldPatterns(patterns []string) []fieldPattern {
+	parsed := make([]fieldPattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		if pattern = strings.TrimSpace(pattern); pattern == "" {
+			continue
+		}
+		if p, err := parseFieldPattern(pattern); err == nil {
+			parsed = append(parsed, p)
+		}
+	}
+	return parsed
+}
+
+// matches reports whether the pattern selects the location given by path
+func (p fieldPattern) matches(path []string) bool {
+	if len(p) == 0 {
+		return len(path) == 0
+	}
+	segment, rest := p[0], p[1:]
+	if !segment.recursive {
+		return len(path) > 0 && segment.matchesKey(path[0]) && rest.matches(path[1:])
+	}
+	for i := range path {
+		if segment.matchesKey(path[i]) && rest.matches(path[i+1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoreFields returns a copy of the decoded JSON value with every field
+// matching one of the patterns set to null, so volatile values such as
+// generated IDs and timestamps don't affect comparisons
+func ignoreFields(value interface{}, patterns []string) interface{} {
+	parsed := parseFieldPatterns(patterns)
+	if len(parsed) == 0 {
+		return value
+	}
+	return nullMatching(value, nil, parsed)
+}
+
+func nullMatching(value interface{}, path []string, patterns []fieldPattern) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			childPath := append(append([]string{}, path...), key)
+			if matchesAny(patterns, childPath) {
+				result[key] = nil
+				continue
+			}
+			result[key] = nullMatching(item, childPath, patterns)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			childPath := append(append([]string{}, path...), strconv.Itoa(i))
+			if matchesAny(patterns, childPath) {
+				result[i] = nil
+				continue
+			}
+			result[i] = nullMatching(item, childPath, patterns)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+func matchesAny(patterns []fieldPattern, path []string) bool {
+	for _, pattern := range patterns {
+		if pattern.matches(path) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,377 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"auto-api-tester/internal/types"
+)
+
+// checkJSONAssertions evaluates the endpoint's JSONPath assertions against a
+// JSON response body, returning a single error describing every mismatch
+func checkJSONAssertions(body []byte, assertions []types.JSONAssertion) error {
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return fmt.Errorf("json assertions failed: response is not JSON: %v", err)
+	}
+
+	var diffs []types.FieldDiff
+	for _, assertion := range assertions {
+		diffs = append(diffs, checkJSONAssertion(document, assertion)...)
+	}
+
+	if len(diffs) > 0 {
+		return newDiffError("json assertions failed", diffs)
+	}
+	return nil
+}
+
+// checkJSONAssertion returns the differences that make an assertion fail
+func checkJSONAssertion(document interface{}, assertion types.JSONAssertion) []types.FieldDiff {
+	path := assertion.Path
+	segments, err := parsePath(path)
+	if err != nil {
+		return []types.FieldDiff{{Path: path, Problem: err.Error()}}
+	}
+	values := selectPath(document, segments)
+
+	operator := assertion.Operator
+	if operator == "" {
+		operator = "=="
+	}
+	if operator == "exists" {
+		if len(values) == 0 {
+			return []types.FieldDiff{{Path: path, Problem: "expected a value, found none"}}
+		}
+		return nil
+	}
+	if len(values) == 0 {
+		return []types.FieldDiff{{Path: path, Expected: assertion.Value, Problem: fmt.Sprintf("expected %s %s, found no value", operator, formatJSON(assertion.Value))}}
+	}
+
+	for _, actual := range values {
+		// Equality compares structurally so nested values show each differing field
+		if operator == "==" || operator == "eq" {
+			if diffs := withoutTolerated(diffValues(path, normalizeJSON(assertion.Value), actual), assertion); len(diffs) > 0 {
+				return diffs
+			}
+			continue
+		}
+		if (operator == "!=" || operator == "ne") && hasTolerance(assertion) {
+			if len(withoutTolerated(diffValues(path, normalizeJSON(assertion.Value), actual), assertion)) == 0 {
+				return []types.FieldDiff{{
+					Path:     path,
+					Expected: assertion.Value,
+					Actual:   actual,
+					Problem:  fmt.Sprintf("expected a value outside the tolerance of %s, got %s", formatJSON(assertion.Value), formatJSON(actual)),
+				}}
+			}
+			continue
+		}
+
+		ok, err := compareJSON(actual, operator, assertion.Value)
+		if err != nil {
+			return []types.FieldDiff{{Path: path, Problem: err.Error()}}
+		}
+		if !ok {
+			return []types.FieldDiff{{
+				Path:     path,
+				Expected: assertion.Value,
+				Actual:   actual,
+				Problem:  fmt.Sprintf("expected %s %s, got %s", operator, formatJSON(assertion.Value), formatJSON(actual)),
+			}}
+		}
+	}
+	return nil
+}
+
+// hasTolerance reports whether an assertion compares numbers approximately
+func hasTolerance(assertion types.JSONAssertion) bool {
+	return assertion.Tolerance > 0 || assertion.RelativeTolerance > 0
+}
+
+// withoutTolerated drops the differences between two numbers that are within
+// the assertion's tolerance
+func withoutTolerated(diffs []types.FieldDiff, assertion types.JSONAssertion) []types.FieldDiff {
+	if !hasTolerance(assertion) {
+		return diffs
+	}
+	kept := diffs[:0]
+	for _, diff := range diffs {
+		expected, eok := diff.Expected.(float64)
+		actual, aok := diff.Actual.(float64)
+		if eok && aok && withinTolerance(actual, expected, assertion) {
+			continue
+		}
+		kept = append(kept, diff)
+	}
+	return kept
+}
+
+// withinTolerance reports whether actual is close enough to expected
+func withinTolerance(actual, expected float64, assertion types.JSONAssertion) bool {
+	delta := math.Abs(actual - expected)
+	return delta <= assertion.Tolerance || delta <= assertion.RelativeTolerance*math.Abs(expected)
+}
+
+// pathSegment is one step of a parsed JSONPath: an object key or array
+// index, a wildcard, or either of those at any depth below the current value
+type pathSegment struct {
+	key       string
+	wildcard  bool
+	recursive bool
+}
+
+// matchesKey reports whether the segment selects the child named key, an
+// object key or an array index in decimal
+func (s pathSegment) matchesKey(key string) bool {
+	return s.wildcard || s.key == key
+}
+
+// parsePath parses the supported subset of JSONPath: an optional leading $,
+// .name and ['name'] children (the bracketed form for names containing dots
+// or spaces), [n] indexes counting from the end when negative, .* and [*]
+// wildcards, and ..name, ..* and ..[n] recursive descent. Filters, slices,
+// unions and functions are rejected rather than silently misread.
+func parsePath(path string) ([]pathSegment, error) {
+	invalid := func(reason string) error {
+		return fmt.Errorf("invalid JSONPath %q: %s", path, reason)
+	}
+
+	rest := strings.TrimPrefix(strings.TrimSpace(path), "$")
+	var segments []pathSegment
+	for first := true; rest != ""; first = false {
+		var segment pathSegment
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			segment.recursive = true
+			rest = rest[2:]
+			if strings.HasPrefix(rest, "[") {
+				break
+			}
+			fallthrough
+		case strings.HasPrefix(rest, "."), first && !strings.HasPrefix(rest, "["):
+			rest = strings.TrimPrefix(rest, ".")
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			rest = rest[end:]
+			switch {
+			case name == "":
+				return nil, invalid("empty name")
+			case strings.HasSuffix(name, ")"):
+				return nil, invalid("functions are not supported")
+			case name == "*":
+				segment.wildcard = true
+			default:
+				segment.key = name
+			}
+			segments = append(segments, segment)
+			continue
+		case !strings.HasPrefix(rest, "["):
+			return nil, invalid(fmt.Sprintf("unexpected %q", rest[:1]))
+		}
+
+		// Bracketed step: a quoted name, an index or a wildcard
+		if q := rest[1:]; strings.HasPrefix(q, "'") || strings.HasPrefix(q, `"`) {
+			quote := q[0]
+			var name strings.Builder
+			i := 1
+			for ; i < len(q) && q[i] != quote; i++ {
+				if q[i] == '\\' && i+1 < len(q) {
+					i++
+				}
+				name.WriteByte(q[i])
+			}
+			if i+1 >= len(q) || q[i+1] != ']' {
+				return nil, invalid("unterminated quoted name")
+			}
+			segment.key = name.String()
+			rest = q[i+2:]
+			segments = append(segments, segment)
+			continue
+		}
+		end := strings.Index(rest, "]")
+		if end < 0 {
+			return nil, invalid("missing ]")
+		}
+		content := strings.TrimSpace(rest[1:end])
+		rest = rest[end+1:]
+		switch {
+		case content == "*":
+			segment.wildcard = true
+		case strings.HasPrefix(content, "?"):
+			return nil, invalid("filter expressions are not supported")
+		case strings.Contains(content, ":"):
+			return nil, invalid("slices are not supported")
+		case strings.Contains(content, ","):
+			return nil, invalid("unions are not supported")
+		default:
+			if _, err := strconv.Atoi(content); err != nil {
+				return nil, invalid(fmt.Sprintf("expected an index, * or a quoted name in [%s]", content))
+			}
+			segment.key = content
+		}
+		segments = append(segments, segment)
+	}
+	return segments, nil
+}
+
+// selectPath returns the values at the location given by path segments, in
+// document order with object keys sorted
+func selectPath(value interface{}, segments []pathSegment) []interface{} {
+	if len(segments) == 0 {
+		return []interface{}{value}
+	}
+	segment, rest := segments[0], segments[1:]
+
+	var matches []interface{}
+	if segment.recursive {
+		// The step applies to the value's children and, in turn, to theirs
+		direct := segment
+		direct.recursive = false
+		matches = selectPath(value, append([]pathSegment{direct}, rest...))
+		for _, child := range children(value) {
+			matches = append(matches, selectPath(child, segments)...)
+		}
+		return matches
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if segment.wildcard {
+			for _, child := range children(v) {
+				matches = append(matches, selectPath(child, rest)...)
+			}
+		} else if child, ok := v[segment.key]; ok {
+			matches = selectPath(child, rest)
+		}
+	case []interface{}:
+		if segment.wildcard {
+			for _, child := range v {
+				matches = append(matches, selectPath(child, rest)...)
+			}
+		} else if index, err := strconv.Atoi(segment.key); err == nil {
+			if index < 0 {
+				index += len(v)
+			}
+			if index >= 0 && index < len(v) {
+				matches = selectPath(v[index], rest)
+			}
+		}
+	}
+	return matches
+}
+
+// children returns the elements of an array, or the values of an object in
+// key order
+func children(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		values := make([]interface{}, 0, len(v))
+		for _, key := range sortedKeys(v) {
+			values = append(values, v[key])
+		}
+		return values
+	case []interface{}:
+		return v
+	}
+	return nil
+}
+
+// compareJSON applies operator to a decoded response value and the expected value
+func compareJSON(actual interface{}, operator string, expected interface{}) (bool, error) {
+	switch operator {
+	case "==", "eq":
+		return jsonEqual(actual, expected), nil
+	case "!=", "ne":
+		return !jsonEqual(actual, expected), nil
+	case ">", ">=", "<", "<=":
+		a, aok := actual.(float64)
+		b, bok := toFloat(expected)
+		if !aok || !bok {
+			return false, fmt.Errorf("operator %s needs numbers, got %s and %s", operator, formatJSON(actual), formatJSON(expected))
+		}
+		switch operator {
+		case ">":
+			return a > b, nil
+		case ">=":
+			return a >= b, nil
+		case "<":
+			return a < b, nil
+		default:
+			return a <= b, nil
+		}
+	case "contains":
+		switch v := actual.(type) {
+		case string:
+			return strings.Contains(v, formatValue(expected)), nil
+		case []interface{}:
+			for _, item := range v {
+				if jsonEqual(item, expected) {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+		return false, fmt.Errorf("operator contains needs a string or array, got %s", formatJSON(actual))
+	case "matches":
+		pattern, ok := expected.(string)
+		if !ok {
+			return false, fmt.Errorf("operator matches needs a regular expression string")
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return false, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+		return re.MatchString(formatValue(actual)), nil
+	}
+	return false, fmt.Errorf("unknown operator %q", operator)
+}
+
+// jsonEqual compares values after normalizing the expected one through JSON,
+// so integers in test data equal the float64 numbers of a decoded response
+func jsonEqual(actual, expected interface{}) bool {
+	return reflect.DeepEqual(actual, normalizeJSON(expected))
+}
+
+// normalizeJSON round-trips a value through JSON so it has the types of a decoded response
+func normalizeJSON(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func formatJSON(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
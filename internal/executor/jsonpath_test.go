@@ -0,0 +1,111 @@
+package executor
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const pathDocument = `{
+	"id": 7,
+	"meta": {"requestId": "r-1", "name": "meta"},
+	"items": [
+		{"name": "a", "tags": ["x", "y"]},
+		{"name": "b", "tags": []}
+	],
+	"a.b": {"c d": 1},
+	"owner": {"profile": {"name": "Ada"}}
+}`
+
+func TestSelectPath(t *testing.T) {
+	var document interface{}
+	if err := json.Unmarshal([]byte(pathDocument), &document); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want []interface{}
+	}{
+		{"$.id", []interface{}{7.0}},
+		{"$", []interface{}{document}},
+		{"$.items[1].name", []interface{}{"b"}},
+		{"$.items[-1].name", []interface{}{"b"}},
+		{"$.items[*].name", []interface{}{"a", "b"}},
+		{"$.items.*.name", []interface{}{"a", "b"}},
+		{"$.items[0].tags[*]", []interface{}{"x", "y"}},
+		{"$['a.b']['c d']", []interface{}{1.0}},
+		{`$["a.b"]["c d"]`, []interface{}{1.0}},
+		{"$..name", []interface{}{"a", "b", "meta", "Ada"}},
+		{"$..profile.name", []interface{}{"Ada"}},
+		{"$..tags[0]", []interface{}{"x"}},
+		{"$.missing", nil},
+		{"$.items[5]", nil},
+		{"meta.requestId", []interface{}{"r-1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			segments, err := parsePath(tt.path)
+			if err != nil {
+				t.Fatalf("parsePath: %v", err)
+			}
+			if got := selectPath(document, segments); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectPath = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePathRejectsUnsupportedSyntax(t *testing.T) {
+	tests := []struct {
+		path    string
+		problem string
+	}{
+		{"$.items[?(@.price < 10)]", "filter expressions are not supported"},
+		{"$.items[0:2]", "slices are not supported"},
+		{"$.items[0,1]", "unions are not supported"},
+		{"$.items.length()", "functions are not supported"},
+		{"$.items[name]", "expected an index"},
+		{"$.items[0", "missing ]"},
+		{"$['a.b", "unterminated quoted name"},
+		{"$.a..", "empty name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			_, err := parsePath(tt.path)
+			if err == nil || !strings.Contains(err.Error(), tt.problem) {
+				t.Errorf("parsePath(%q) error = %v, want %q", tt.path, err, tt.problem)
+			}
+		})
+	}
+}
+
+func TestFieldPatternMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    []string
+		want    bool
+	}{
+		{"updatedAt", []string{"items", "0", "updatedAt"}, true},
+		{"updatedAt", []string{"updatedAt", "value"}, false},
+		{"$.meta.requestId", []string{"meta", "requestId"}, true},
+		{"$.meta.requestId", []string{"data", "meta", "requestId"}, false},
+		{"$.items[*].id", []string{"items", "3", "id"}, true},
+		{"$..meta.requestId", []string{"data", "meta", "requestId"}, true},
+		{"$['a.b']", []string{"a.b"}, true},
+		{"$['a.b']", []string{"a", "b"}, false},
+	}
+
+	for _, tt := range tests {
+		pattern, err := parseFieldPattern(tt.pattern)
+		if err != nil {
+			t.Fatalf("parseFieldPattern(%q): %v", tt.pattern, err)
+		}
+		if got := pattern.matches(tt.path); got != tt.want {
+			t.Errorf("%q matches %v = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"auto-api-tester/internal/types"
+)
+
+// LoadConfig turns a run into a load test that sends every test case's
+// request repeatedly through MaxWorkers workers
+type LoadConfig struct {
+	// Repeat sends each test case this many times
+	Repeat int
+	// Duration keeps sending requests, cycling through the test cases, until
+	// it has passed; with Repeat as well the run ends at whichever comes first
+	Duration time.Duration
+	// RampUp spreads the workers' start over this period instead of starting
+	// them all at once
+	RampUp time.Duration
+}
+
+// Enabled reports whether the config asks for a load run
+func (c LoadConfig) Enabled() bool {
+	return c.Repeat > 0 || c.Duration > 0
+}
+
+// LoadResult aggregates the requests a load run made for one test case
+type LoadResult struct {
+	Endpoint string
+	Method   string
+	Case     string
+
+	// Requests counts the attempts, including those that couldn't be sent
+	Requests int
+	// Errors counts the requests that didn't succeed, ServerErrors those
+	// the server answered with a 5xx status
+	Errors       int
+	ServerErrors int
+	// Durations holds the response time of each request that was sent
+	Durations []time.Duration
+	// Error is the last failure seen, as an example of what went wrong
+	Error error
+}
+
+// RunLoad runs the load test configured in TestConfig.Load, returning the
+// results per test case and the time the run took. Requests aren't retried
+// and report no individual results.
+func (e *TestExecutor) RunLoad(ctx context.Context, endpoints []types.Endpoint) ([]LoadResult, time.Duration) {
+	load := e.config.Load
+	if load.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, load.Duration)
+		defer cancel()
+	}
+
+	results := make([]LoadResult, len(endpoints))
+	testCases := make([]*types.EndpointTestData, len(endpoints))
+	var runnable []int
+	for i, endpoint := range endpoints {
+		results[i] = LoadResult{Endpoint: endpoint.Path, Method: endpoint.Method, Case: endpoint.Case}
+		testData, err := e.testDataFor(endpoint)
+		if err != nil {
+			results[i].Error = fmt.Errorf("failed to get test data: %w", err)
+			continue
+		}
+		testCases[i] = testData
+		runnable = append(runnable, i)
+	}
+
+	// Hand out test case indexes round-robin until Repeat rounds are done or
+	// the Duration expires
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		if len(runnable) == 0 {
+			return
+		}
+		for round := 0; load.Repeat <= 0 || round < load.Repeat; round++ {
+			for _, i := range runnable {
+				select {
+				case jobs <- i:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	workers := e.config.MaxWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	start := time.Now()
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			if delay := load.RampUp * time.Duration(w) / time.Duration(workers); delay > 0 {
+				select {
+				case <-time.After(delay):
+				case <-ctx.Done():
+					return
+				}
+			}
+			for i := range jobs {
+				result := e.loadRequest(ctx, endpoints[i], testCases[i])
+				// Requests cut off by the end of the run aren't counted
+				if ctx.Err() != nil && result.Status != "SUCCESS" {
+					return
+				}
+
+				mu.Lock()
+				aggregate := &results[i]
+				aggregate.Requests++
+				if result.StatusCode != 0 {
+					aggregate.Durations = append(aggregate.Durations, result.Duration)
+				}
+				if result.Status != "SUCCESS" {
+					aggregate.Errors++
+					aggregate.Error = result.Error
+					// A 5xx the test case expects is a pass, not a server error
+					if result.StatusCode >= 500 && result.StatusCode < 600 {
+						aggregate.ServerErrors++
+					}
+				}
+				mu.Unlock()
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	return results, time.Since(start)
+}
+
+// loadRequest sends one request of a load run
+func (e *TestExecutor) loadRequest(ctx context.Context, endpoint types.Endpoint, testData *types.EndpointTestData) TestResult {
+	req, err := e.buildRequest(ctx, endpoint, testData)
+	if err != nil {
+		return TestResult{Status: "ERROR", Error: fmt.Errorf("failed to build request: %w", err)}
+	}
+	e.setIdempotencyKey(req)
+	return e.executeTest(req, endpoint, testData)
+}
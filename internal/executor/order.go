@@ -0,0 +1,87 @@
+package executor
+
+import (
+	"fmt"
+
+	"auto-api-tester/internal/types"
+)
+
+// dependencies returns, for each scheduled test case, the indexes of the
+// cases that must finish before it starts: those extracting a variable it
+// references, those named in its depends_on, and with Sequential the case
+// scheduled before it. A dependency that would close a cycle is dropped, so
+// the case in question runs without waiting. A depends_on entry matching no
+// case is reported as the case's error.
+func (e *TestExecutor) dependencies(scheduled []types.Endpoint, testCases []*types.EndpointTestData) ([][]int, []error) {
+	deps := variableDependencies(testCases)
+	errs := make([]error, len(scheduled))
+
+	for i, testData := range testCases {
+		if testData == nil {
+			continue
+		}
+		for _, name := range testData.DependsOn {
+			matched := false
+			for j, endpoint := range scheduled {
+				if dependencyMatches(endpoint, name) {
+					matched = true
+					if j != i {
+						deps[i] = append(deps[i], j)
+					}
+				}
+			}
+			if !matched && errs[i] == nil {
+				errs[i] = fmt.Errorf("unknown dependency %q", name)
+			}
+		}
+	}
+
+	if e.config.Sequential {
+		for i := 1; i < len(deps); i++ {
+			deps[i] = append(deps[i], i-1)
+		}
+	}
+	return acyclic(deps), errs
+}
+
+// dependencyMatches reports whether a depends_on entry names the endpoint's
+// test case: by case name, by "METHOD path" for all of the endpoint's cases,
+// or by "METHOD path [case]" for one of them
+func dependencyMatches(endpoint types.Endpoint, name string) bool {
+	return name == endpoint.Key() ||
+		name == endpoint.Method+" "+endpoint.Path ||
+		endpoint.Case != "" && name == endpoint.Case
+}
+
+// acyclic removes the edges of a dependency graph that lead back to a case
+// still being visited, visiting cases and their dependencies in order
+func acyclic(deps [][]int) [][]int {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(deps))
+	result := make([][]int, len(deps))
+
+	var visit func(i int)
+	visit = func(i int) {
+		state[i] = visiting
+		for _, j := range deps[i] {
+			if state[j] == visiting {
+				continue
+			}
+			if state[j] == unvisited {
+				visit(j)
+			}
+			result[i] = append(result[i], j)
+		}
+		state[i] = visited
+	}
+	for i := range deps {
+		if state[i] == unvisited {
+			visit(i)
+		}
+	}
+	return result
+}
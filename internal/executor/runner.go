@@ -4,40 +4,209 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/cookiejar"
+	neturl "net/url"
+	"os"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"auto-api-tester/internal/testdata"
 	"auto-api-tester/internal/types"
+
+	"github.com/google/uuid"
 )
 
 // TestResult represents the result of a single test
 type TestResult struct {
-	Endpoint    string
-	Method      string
-	Status      string
+	Endpoint string
+	Method   string
+	// Case is the name of the endpoint's test case, if it has one
+	Case   string
+	Status string
+	// StatusCode is the HTTP status returned, zero when no response was received
+	StatusCode  int
 	Duration    time.Duration
 	Error       error
 	RequestBody string
 	Response    string
+	// ContentType is the response's Content-Type header
+	ContentType string
+	// ResponseHeaders are the response's headers, with sensitive values redacted
+	ResponseHeaders map[string][]string
+
+	// ExpectedStatus is the test case's expected_status; empty expects any 2xx
+	ExpectedStatus []int
+
+	// BodySize and Checksum (SHA-256) describe binary responses, whose
+	// content is left out of Response
+	BodySize int
+	Checksum string
+
+	// Timings breaks the request down into DNS, connect, TLS and
+	// time-to-first-byte phases
+	Timings *types.Timings
+
+	// Diffs lists the differences behind a failed JSONPath, schema or golden assertion
+	Diffs []types.FieldDiff
 }
 
 // TestConfig holds configuration for test execution
 type TestConfig struct {
 	Concurrent bool
 	MaxWorkers int
-	Timeout    int
 	Retry      RetryConfig
+
+	// RequestTimeout bounds each HTTP call and TotalTimeout the whole run, both
+	// in seconds; a zero TotalTimeout leaves the run unbounded
+	RequestTimeout int
+	TotalTimeout   int
+
+	// BaseURL, when set, is prepended to relative endpoint paths and
+	// replaces the scheme and host of absolute ones
+	BaseURL string
+
+	// FollowRedirects follows 3xx responses up to MaxRedirects hops, 10 when
+	// zero. Otherwise the redirect itself is the response, so its status and
+	// Location header can be asserted on.
+	FollowRedirects bool
+	MaxRedirects    int
+
+	// UseCookies keeps cookies set by responses and sends them on later
+	// requests, e.g. a session cookie from a login endpoint. Pair it with
+	// Sequential so the login runs first.
+	UseCookies bool
+
+	Idempotency IdempotencyConfig
+
+	// Breaker fast-fails requests to a host after repeated failures
+	Breaker BreakerConfig
+
+	// Cassette records exchanges to a file or replays them offline
+	Cassette CassetteConfig
+
+	// Auth supplies a bearer token, refreshed on 401 when OAuth2 is configured
+	Auth AuthConfig
+
+	// AuthProfiles are named credentials selected per endpoint with auth_profile
+	AuthProfiles map[string]AuthConfig
+
+	// ServiceAuth maps a service's base URL (or bare host) to the auth profile
+	// used for endpoints on it that don't name their own auth_profile
+	ServiceAuth map[string]string
+
+	// RecordHAR captures every request/response pair for export with WriteHAR
+	RecordHAR bool
+
+	// Quiet keeps requests, responses and progress messages off stdout
+	Quiet bool
+
+	// Variables seeds the variable store referenced as {{name}} in requests
+	Variables map[string]interface{}
+
+	// Sequential runs the test cases one at a time in the order they are
+	// declared; cases can also order themselves with depends_on
+	Sequential bool
+
+	// EnvHeaders maps header names to environment variables read when each
+	// request is built; an endpoint's own headers take precedence
+	EnvHeaders map[string]string
+
+	// Iterations runs every endpoint this many times, scaled by its weight
+	Iterations int
+
+	// Sample, when set, runs this many endpoints drawn at random in
+	// proportion to their weight instead of running every endpoint
+	Sample int
+
+	// DetectCachedResponses fails read endpoints that return an identical
+	// response (after IgnoreFields) on every call of a multi-call run
+	DetectCachedResponses bool
+
+	// AllowEmptyBinary passes binary responses with an empty body; by default
+	// a download must contain at least one byte
+	AllowEmptyBinary bool
+
+	// ValidateResponses validates response bodies against the spec schema
+	// declared for the returned status code
+	ValidateResponses bool
+
+	// StrictResponses additionally fails responses carrying fields the schema
+	// doesn't declare, as if every object had additionalProperties: false
+	StrictResponses bool
+
+	// ExpectDocumentedStatus fails a 2xx response whose status differs from
+	// the operation's only documented 2xx response, e.g. 200 for a 201 create
+	ExpectDocumentedStatus bool
+
+	// ExpectedHeaders are asserted on every response in addition to the endpoint's own
+	ExpectedHeaders []types.HeaderAssertion
+
+	// UnredactedHeaders lists sensitive response headers, such as Set-Cookie,
+	// whose values are reported as received instead of redacted
+	UnredactedHeaders []string
+
+	// IgnoreFields are field names or JSONPath patterns nulled out of responses
+	// before they are compared
+	IgnoreFields []string
+
+	Golden GoldenConfig
+
+	// Transport, when set, replaces http.DefaultTransport for every request,
+	// e.g. to add logging or request signing, or to test without a server.
+	// A cassette still wraps it when recording or replaying.
+	Transport http.RoundTripper
+
+	// MaxFailures aborts the run once this many tests have failed; zero runs everything
+	MaxFailures int
+
+	// Load configures RunLoad
+	Load LoadConfig
 }
 
 // RetryConfig holds configuration for retry behavior
 type RetryConfig struct {
 	Attempts int
 	Delay    time.Duration
+
+	// Budget caps the retries across the whole suite so a widespread outage
+	// doesn't multiply into thousands of extra requests; zero is unlimited
+	Budget int
+
+	// RetryableStatusCodes are the response statuses worth retrying; nil
+	// means DefaultRetryableStatusCodes. Transport errors are always retried.
+	RetryableStatusCodes []int
+}
+
+// DefaultRetryableStatusCodes are the statuses of transient server failures
+var DefaultRetryableStatusCodes = []int{429, 502, 503, 504}
+
+// retryable reports whether a failed attempt may succeed when repeated:
+// the exchange failed in transit, even after the status line arrived, or
+// the status is a retryable one
+func (c RetryConfig) retryable(result TestResult) bool {
+	var transportErr *transportError
+	if errors.As(result.Error, &transportErr) {
+		return true
+	}
+	codes := c.RetryableStatusCodes
+	if codes == nil {
+		codes = DefaultRetryableStatusCodes
+	}
+	return slices.Contains(codes, result.StatusCode)
+}
+
+// IdempotencyConfig controls the idempotency key attached to mutating requests
+type IdempotencyConfig struct {
+	Enabled bool
+	// Header is the header name, defaulting to Idempotency-Key
+	Header string
 }
 
 // TestExecutor handles the execution of API tests
@@ -45,14 +214,119 @@ type TestExecutor struct {
 	config   TestConfig
 	client   *http.Client
 	testData *testdata.Loader
+	har      *harRecorder
+	vars     *Variables
+	auth     *authenticator
+
+	profilesMu sync.Mutex
+	profiles   map[string]*authenticator
+
+	cassette *cassette
+	breaker  *circuitBreaker
+
+	retriesLeft    atomic.Int64
+	budgetExceeded sync.Once
+
+	failures atomic.Int64
+	aborted  atomic.Bool
+
+	// responses fingerprints read responses when DetectCachedResponses is set
+	responses *responseTracker
+
+	limitsMu sync.Mutex
+	limits   map[string]chan struct{}
+
+	// onResult is called with each result as it completes, one at a time
+	onResult func(TestResult)
 }
 
-// NewTestExecutor creates a new test executor
-func NewTestExecutor(config TestConfig, testData *testdata.Loader) *TestExecutor {
-	return &TestExecutor{
+// NewTestExecutor creates a new test executor. A nil testData runs each
+// endpoint with its own TestData instead of reading it from the loader.
+func NewTestExecutor(config TestConfig, testData *testdata.Loader) (*TestExecutor, error) {
+	if err := config.Auth.validate(); err != nil {
+		return nil, err
+	}
+	for service, name := range config.ServiceAuth {
+		if _, ok := config.AuthProfiles[name]; !ok && name != NoAuthProfile {
+			return nil, fmt.Errorf("service %s uses unknown auth profile %q", service, name)
+		}
+	}
+	for name, profile := range config.AuthProfiles {
+		if err := profile.validate(); err != nil {
+			return nil, fmt.Errorf("auth profile %s: %w", name, err)
+		}
+	}
+	if err := checkFieldPatterns(config.IgnoreFields); err != nil {
+		return nil, fmt.Errorf("assertions.ignore_fields: %w", err)
+	}
+	if err := checkFieldPatterns(config.Golden.IgnoreFields); err != nil {
+		return nil, fmt.Errorf("golden.ignore_fields: %w", err)
+	}
+
+	transport := config.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	client := &http.Client{
+		Timeout:       time.Duration(config.RequestTimeout) * time.Second,
+		Transport:     transport,
+		CheckRedirect: redirectPolicy(config.FollowRedirects, config.MaxRedirects),
+	}
+	if config.UseCookies {
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cookie jar: %w", err)
+		}
+		client.Jar = jar
+	}
+
+	// Route traffic through the cassette when recording or replaying
+	var recorder *cassette
+	if config.Cassette.Mode != "" {
+		var err error
+		if recorder, err = newCassette(config.Cassette, transport); err != nil {
+			return nil, err
+		}
+		client.Transport = recorder
+	}
+
+	executor := &TestExecutor{
 		config:   config,
-		client:   &http.Client{Timeout: time.Duration(config.Timeout) * time.Second},
+		client:   client,
 		testData: testData,
+		vars:     NewVariables(config.Variables),
+		auth:     newAuthenticator(config.Auth, client),
+		profiles: make(map[string]*authenticator, len(config.AuthProfiles)),
+		cassette: recorder,
+		breaker:  newCircuitBreaker(config.Breaker),
+	}
+	if config.RecordHAR {
+		executor.har = newHARRecorder(config)
+	}
+	if config.DetectCachedResponses {
+		executor.responses = newResponseTracker()
+	}
+	executor.retriesLeft.Store(int64(config.Retry.Budget))
+	return executor, nil
+}
+
+// defaultMaxRedirects matches the limit of Go's default redirect policy
+const defaultMaxRedirects = 10
+
+// redirectPolicy returns the client's CheckRedirect: stop at the first
+// redirect when not following, else give up after max hops
+func redirectPolicy(follow bool, max int) func(*http.Request, []*http.Request) error {
+	if max <= 0 {
+		max = defaultMaxRedirects
+	}
+	return func(req *http.Request, via []*http.Request) error {
+		if !follow {
+			return http.ErrUseLastResponse
+		}
+		if len(via) >= max {
+			return fmt.Errorf("stopped after %d redirects", max)
+		}
+		return nil
 	}
 }
 
@@ -62,64 +336,288 @@ func (e *TestExecutor) RunTests(ctx context.Context, endpoints []types.Endpoint)
 	var mu sync.Mutex
 	var wg sync.WaitGroup
 
+	// notify hands results to the OnResult goroutine started once the
+	// number of tests is known
+	var notify chan TestResult
+	var notified sync.WaitGroup
+
+	// record collects an endpoint's result and hands it to OnResult as soon as it's known
+	record := func(endpoint types.Endpoint, result TestResult) {
+		result.Case = endpoint.Case
+		mu.Lock()
+		results = append(results, result)
+		mu.Unlock()
+		if notify != nil {
+			notify <- result
+		}
+	}
+
+	if e.config.TotalTimeout > 0 {
+		var cancelRun context.CancelFunc
+		ctx, cancelRun = context.WithTimeout(ctx, time.Duration(e.config.TotalTimeout)*time.Second)
+		defer cancelRun()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	// Create a channel to limit concurrent executions
 	sem := make(chan struct{}, e.config.MaxWorkers)
 
-	for _, endpoint := range endpoints {
+	scheduled := e.schedule(endpoints)
+
+	// OnResult is called from a single goroutine, so a slow callback such as a
+	// webhook sink never holds up the tests; the buffer fits every result
+	if e.onResult != nil {
+		notify = make(chan TestResult, len(scheduled))
+		notified.Add(1)
+		go func() {
+			defer notified.Done()
+			for result := range notify {
+				e.onResult(result)
+			}
+		}()
+	}
+
+	// Load every test case up front so that cases can wait for the ones they
+	// depend on
+	testCases := make([]*types.EndpointTestData, len(scheduled))
+	loadErrs := make([]error, len(scheduled))
+	for i, endpoint := range scheduled {
+		testCases[i], loadErrs[i] = e.testDataFor(endpoint)
+	}
+	waits, depErrs := e.dependencies(scheduled, testCases)
+	done := make([]chan struct{}, len(scheduled))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+
+	for i, endpoint := range scheduled {
 		wg.Add(1)
-		go func(endpoint types.Endpoint) {
+		go func(i int, endpoint types.Endpoint) {
 			defer wg.Done()
+			defer close(done[i])
+
+			testData := testCases[i]
+			if err := loadErrs[i]; err != nil {
+				record(endpoint, TestResult{
+					Endpoint: endpoint.Path,
+					Method:   endpoint.Method,
+					Status:   "ERROR",
+					Error:    fmt.Errorf("failed to get test data: %w", err),
+				})
+				return
+			}
+			if err := depErrs[i]; err != nil {
+				record(endpoint, TestResult{
+					Endpoint: endpoint.Path,
+					Method:   endpoint.Method,
+					Status:   "ERROR",
+					Error:    err,
+				})
+				return
+			}
+
+			// Wait for the cases this one depends on before taking any worker slot
+			for _, j := range waits[i] {
+				<-done[j]
+			}
+
+			// Acquire the endpoint's own limit first so waiting calls don't hold global slots
+			if limit := e.endpointLimit(endpoint, testData.MaxConcurrency); limit != nil {
+				limit <- struct{}{}
+				defer func() { <-limit }()
+			}
 
 			// Acquire semaphore
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
-			// Get test data for this endpoint
-			testData, err := e.testData.GetTestDataForEndpoint(endpoint)
-			if err != nil {
-				mu.Lock()
-				results = append(results, TestResult{
+			// Tests that hadn't started when the run was aborted are left out of the report
+			if e.aborted.Load() {
+				return
+			}
+
+			// Past the run deadline nothing more is sent, but the test is still reported
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				record(endpoint, TestResult{
 					Endpoint: endpoint.Path,
 					Method:   endpoint.Method,
 					Status:   "ERROR",
-					Error:    fmt.Errorf("failed to get test data: %w", err),
+					Error:    errRunDeadline,
 				})
-				mu.Unlock()
 				return
 			}
 
 			// Build request
 			req, err := e.buildRequest(ctx, endpoint, testData)
 			if err != nil {
-				mu.Lock()
-				results = append(results, TestResult{
+				record(endpoint, TestResult{
 					Endpoint: endpoint.Path,
 					Method:   endpoint.Method,
 					Status:   "ERROR",
 					Error:    fmt.Errorf("failed to build request: %w", err),
 				})
-				mu.Unlock()
 				return
 			}
 
+			// Use one idempotency key for all attempts so the server can dedupe retries
+			e.setIdempotencyKey(req)
+
 			// Execute test with retries
 			var result TestResult
 			for attempt := 0; attempt < e.config.Retry.Attempts; attempt++ {
-				result = e.executeTest(req, endpoint)
-				if result.Error == nil {
+				if attempt > 0 {
+					if req, err = rewindRequest(req); err != nil {
+						result = TestResult{
+							Endpoint: endpoint.Path,
+							Method:   endpoint.Method,
+							Status:   "ERROR",
+							Error:    err,
+						}
+						break
+					}
+				}
+				// Fast-fail while the host's circuit is open
+				if e.breaker != nil && !e.breaker.allow(req.URL.Host) {
+					result = TestResult{
+						Endpoint: endpoint.Path,
+						Method:   endpoint.Method,
+						Status:   "SKIPPED",
+						Error:    fmt.Errorf("circuit open for host %s", req.URL.Host),
+					}
+					break
+				}
+				result = e.executeTest(req, endpoint, testData)
+				if e.breaker != nil {
+					e.breaker.record(req.URL.Host, isHostFailure(result))
+				}
+				if result.Error == nil || !e.config.Retry.retryable(result) || attempt+1 >= e.config.Retry.Attempts || ctx.Err() != nil {
+					break
+				}
+				if !e.takeRetry() {
 					break
 				}
 				time.Sleep(e.config.Retry.Delay)
 			}
 
-			mu.Lock()
-			results = append(results, result)
-			mu.Unlock()
-		}(endpoint)
+			// Requests cut short by the abort didn't really run
+			if e.aborted.Load() && errors.Is(result.Error, context.Canceled) {
+				return
+			}
+			if result.Error != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				result.Status = "ERROR"
+				result.Error = fmt.Errorf("%w: %v", errRunDeadline, result.Error)
+			}
+
+			record(endpoint, result)
+
+			// Skipped tests were never sent, so they don't count as failures
+			if result.Status == "FAILURE" || result.Status == "ERROR" {
+				e.recordFailure(cancel)
+			}
+		}(i, endpoint)
 	}
 
 	wg.Wait()
-	return results
+	if notify != nil {
+		close(notify)
+		notified.Wait()
+	}
+	return e.flagIdenticalResponses(results)
+}
+
+// OnResult registers a function called with each result as soon as its test
+// completes, before the run finishes; calls are never concurrent
+func (e *TestExecutor) OnResult(fn func(TestResult)) {
+	e.onResult = fn
+}
+
+// printf prints to stdout unless Quiet is set
+func (e *TestExecutor) printf(format string, args ...interface{}) {
+	if !e.config.Quiet {
+		fmt.Printf(format, args...)
+	}
+}
+
+// transportError is a request or response that failed in transit, such as a
+// refused connection or one reset while the body was being read
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transportError) Unwrap() error {
+	return e.err
+}
+
+// errRunDeadline marks tests cancelled or never sent because TotalTimeout expired
+var errRunDeadline = errors.New("run deadline exceeded")
+
+// recordFailure counts a failed test and cancels the run once MaxFailures is reached
+func (e *TestExecutor) recordFailure(cancel context.CancelFunc) {
+	if e.config.MaxFailures <= 0 {
+		return
+	}
+	if e.failures.Add(1) == int64(e.config.MaxFailures) {
+		e.aborted.Store(true)
+		e.printf("Aborting run after %d failures\n", e.config.MaxFailures)
+		cancel()
+	}
+}
+
+// Aborted reports whether the last run stopped early because of MaxFailures
+func (e *TestExecutor) Aborted() bool {
+	return e.aborted.Load()
+}
+
+// testDataFor returns the endpoint's test case from the loader, or the
+// endpoint's own TestData when the executor has no loader
+func (e *TestExecutor) testDataFor(endpoint types.Endpoint) (*types.EndpointTestData, error) {
+	if e.testData == nil {
+		testData := endpoint.TestData
+		return &testData, nil
+	}
+	return e.testData.GetTestDataForEndpoint(endpoint)
+}
+
+// takeRetry consumes one retry from the suite-wide budget, reporting false
+// once it is exhausted
+func (e *TestExecutor) takeRetry() bool {
+	if e.config.Retry.Budget <= 0 {
+		return true
+	}
+	if e.retriesLeft.Add(-1) >= 0 {
+		return true
+	}
+	e.budgetExceeded.Do(func() {
+		e.printf("Retry budget of %d exhausted; remaining failures are reported without retrying\n", e.config.Retry.Budget)
+	})
+	return false
+}
+
+// endpointLimit returns the semaphore enforcing an endpoint's max_concurrency,
+// or nil when the endpoint is only bound by the global limit
+func (e *TestExecutor) endpointLimit(endpoint types.Endpoint, maxConcurrency int) chan struct{} {
+	if maxConcurrency <= 0 {
+		return nil
+	}
+
+	e.limitsMu.Lock()
+	defer e.limitsMu.Unlock()
+
+	key := endpoint.Method + " " + endpoint.Path
+	if e.limits == nil {
+		e.limits = make(map[string]chan struct{})
+	}
+	limit, ok := e.limits[key]
+	if !ok {
+		limit = make(chan struct{}, maxConcurrency)
+		e.limits[key] = limit
+	}
+	return limit
 }
 
 // buildRequest creates an HTTP request for the given endpoint and test data
@@ -127,35 +625,63 @@ func (e *TestExecutor) buildRequest(ctx context.Context, endpoint types.Endpoint
 	// Replace path parameters
 	url := endpoint.Path
 	for key, value := range testData.PathParams {
-		url = strings.Replace(url, fmt.Sprintf("{%s}", key), fmt.Sprint(value), -1)
+		value, err := e.vars.Substitute(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve path parameter %s: %w", key, err)
+		}
+		url = strings.Replace(url, fmt.Sprintf("{%s}", key), formatValue(value), -1)
+	}
+
+	// Point the request at the endpoint's own service or the configured
+	// environment, falling back to the test data's base URL
+	baseURL := e.config.BaseURL
+	if testData.BaseURL != "" {
+		baseURL = testData.BaseURL
+	}
+	url, err := resolveURL(url, baseURL, endpoint.BaseURL)
+	if err != nil {
+		return nil, err
 	}
 
-	// Add query parameters
+	// Add query parameters, sorted by name and escaped, so the same test
+	// data always produces the same URL
 	if len(testData.QueryParams) > 0 {
-		query := make([]string, 0, len(testData.QueryParams))
+		query := neturl.Values{}
 		for key, value := range testData.QueryParams {
-			query = append(query, fmt.Sprintf("%s=%s", key, fmt.Sprint(value)))
+			value, err := e.vars.Substitute(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve query parameter %s: %w", key, err)
+			}
+			query.Set(key, formatValue(value))
 		}
-		url = fmt.Sprintf("%s?%s", url, strings.Join(query, "&"))
+		url = fmt.Sprintf("%s?%s", url, query.Encode())
+	}
+
+	// Resolve {{variable}} references in the body
+	requestBody, err := e.vars.Substitute(testData.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve request body: %w", err)
 	}
 
 	// Debug logging for request
-	fmt.Printf("Request URL: %s\n", url)
-	fmt.Printf("Request Method: %s\n", endpoint.Method)
-	fmt.Printf("Request Headers: %v\n", testData.Headers)
-	if testData.Body != nil {
-		bodyBytes, _ := json.Marshal(testData.Body)
-		fmt.Printf("Request Body: %s\n", string(bodyBytes))
+	e.printf("Request URL: %s\n", url)
+	e.printf("Request Method: %s\n", endpoint.Method)
+	e.printf("Request Headers: %v\n", testData.Headers)
+	if requestBody != nil {
+		bodyBytes, _ := json.Marshal(requestBody)
+		e.printf("Request Body: %s\n", string(bodyBytes))
 	}
 
-	// Create request
+	// Create request, encoding the body for the test case's Content-Type
 	var body io.Reader
-	if testData.Body != nil {
-		bodyBytes, err := json.Marshal(testData.Body)
+	bodyContentType := ""
+	if requestBody != nil {
+		bodyBytes, contentType, err := encodeBody(requestBody, headerValue(testData.Headers, "Content-Type"))
 		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+			return nil, err
 		}
 		body = bytes.NewReader(bodyBytes)
+		bodyContentType = contentType
 	}
 
 	req, err := http.NewRequestWithContext(ctx, endpoint.Method, url, body)
@@ -163,74 +689,236 @@ func (e *TestExecutor) buildRequest(ctx context.Context, endpoint types.Endpoint
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// Add headers
+	// Add headers, the endpoint's own overriding those from the environment
+	for header, name := range e.config.EnvHeaders {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return nil, fmt.Errorf("header %s: environment variable %s is not set", header, name)
+		}
+		req.Header.Set(header, value)
+	}
 	for key, value := range testData.Headers {
-		req.Header.Set(key, fmt.Sprint(value))
+		resolved, err := e.vars.Substitute(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve header %s: %w", key, err)
+		}
+		req.Header.Set(key, formatValue(resolved))
+	}
+	if bodyContentType != "" {
+		req.Header.Set("Content-Type", bodyContentType)
 	}
 
 	return req, nil
 }
 
+// setIdempotencyKey attaches a fresh idempotency key to mutating requests
+// unless the test data already provides one
+func (e *TestExecutor) setIdempotencyKey(req *http.Request) {
+	if !e.config.Idempotency.Enabled {
+		return
+	}
+	switch req.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return
+	}
+
+	header := e.config.Idempotency.Header
+	if header == "" {
+		header = "Idempotency-Key"
+	}
+	if req.Header.Get(header) == "" {
+		req.Header.Set(header, uuid.New().String())
+	}
+}
+
+// rewindRequest returns a copy of the request with a fresh body so it can be sent again
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	if req.GetBody == nil {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("failed to rewind request body: %w", err)
+	}
+	retry := req.Clone(req.Context())
+	retry.Body = body
+	return retry, nil
+}
+
 // executeTest executes a single test and returns the result
-func (e *TestExecutor) executeTest(req *http.Request, endpoint types.Endpoint) TestResult {
+func (e *TestExecutor) executeTest(req *http.Request, endpoint types.Endpoint, testData *types.EndpointTestData) TestResult {
+	// Keep a copy of the request body for the HAR export
+	var reqBody []byte
+	if e.har != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			reqBody, _ = io.ReadAll(body)
+			body.Close()
+		}
+	}
+
+	req, trace := withTimingTrace(req)
 	start := time.Now()
-	resp, err := e.client.Do(req)
+	resp, req, err := e.send(req, testData)
 	duration := time.Since(start)
 
 	result := TestResult{
-		Endpoint: endpoint.Path,
-		Method:   endpoint.Method,
-		Duration: duration,
+		Endpoint:       endpoint.Path,
+		Method:         endpoint.Method,
+		ExpectedStatus: testData.ExpectedStatus,
+		Duration:       duration,
+		Timings:        trace.timings(start, duration),
 	}
 
 	if err != nil {
 		result.Status = "ERROR"
-		result.Error = err
+		result.Error = &transportError{err: err}
 		return result
 	}
 	defer resp.Body.Close()
+	result.StatusCode = resp.StatusCode
 
 	// Read response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		result.Status = "ERROR"
-		result.Error = fmt.Errorf("failed to read response body: %w", err)
+		result.Error = &transportError{err: fmt.Errorf("failed to read response body: %w", err)}
 		return result
 	}
 
+	if e.har != nil {
+		e.har.record(start, duration, req, reqBody, resp, body)
+	}
+
+	// Empty bodies, such as every HEAD response, are identical by nature
+	if e.responses != nil && isReadMethod(endpoint.Method) && len(body) > 0 && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		e.responses.record(endpoint.Key(), body, e.config.IgnoreFields)
+	}
+
 	// Debug logging
-	fmt.Printf("Response Status Code: %d\n", resp.StatusCode)
-	fmt.Printf("Response Content-Type: %s\n", resp.Header.Get("Content-Type"))
-	fmt.Printf("Raw Response Body: %s\n", string(body))
+	contentType := resp.Header.Get("Content-Type")
+	binary := isBinaryContentType(contentType)
+	result.ContentType = contentType
+	result.ResponseHeaders = reportedHeaders(resp.Header, e.config.UnredactedHeaders)
+	e.printf("Response Status Code: %d\n", resp.StatusCode)
+	e.printf("Response Content-Type: %s\n", contentType)
+	if binary {
+		result.Response, result.Checksum = summarizeBinary(contentType, body)
+		result.BodySize = len(body)
+		e.printf("%s\n", result.Response)
+	} else {
+		e.printf("Raw Response Body: %s\n", string(body))
+	}
 
 	// Set result status based on response status code
-	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+	switch {
+	case len(testData.ExpectedStatus) > 0:
+		if slices.Contains(testData.ExpectedStatus, resp.StatusCode) {
+			result.Status = "SUCCESS"
+		} else {
+			result.Status = "FAILURE"
+			result.Error = fmt.Errorf("unexpected status code: %d, expected %v", resp.StatusCode, testData.ExpectedStatus)
+		}
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
 		result.Status = "SUCCESS"
-	} else {
+	default:
 		result.Status = "FAILURE"
 		result.Error = fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
+	if result.Error == nil && e.config.ExpectDocumentedStatus && len(testData.ExpectedStatus) == 0 {
+		if documented, ok := documentedSuccess(endpoint); ok && resp.StatusCode != documented {
+			result.Status = "FAILURE"
+			result.Error = fmt.Errorf("unexpected status code: %d, the spec documents %d", resp.StatusCode, documented)
+		}
+	}
+
+	// A download that succeeded must be of the expected kind and size. An
+	// expected content type is checked on text responses too, so an HTML
+	// error page served with 200 in place of a file still fails.
+	if result.Error == nil && (binary || testData.ExpectedContentType != "" || testData.MinResponseBytes > 0) {
+		if err := checkDownload(contentType, len(body), testData, e.config.AllowEmptyBinary); err != nil {
+			result.Status = "FAILURE"
+			result.Error = err
+		}
+	}
+
+	// Validate the body against the schema for the returned status
+	if e.config.ValidateResponses && !binary {
+		if err := validateResponse(endpoint, resp.StatusCode, body, e.config.StrictResponses, e.config.IgnoreFields); err != nil {
+			result.Status = "FAILURE"
+			if result.Error != nil {
+				err = fmt.Errorf("%v; %w", result.Error, err)
+			}
+			result.Error = err
+		}
+	}
+
+	// Check expected response headers
+	if result.Error == nil {
+		expected := append(append([]types.HeaderAssertion{}, e.config.ExpectedHeaders...), testData.ExpectedHeaders...)
+		if err := checkHeaders(resp.Header, expected); err != nil {
+			result.Status = "FAILURE"
+			result.Error = err
+		}
+	}
+
+	// Check JSONPath assertions on the body
+	if result.Error == nil && len(testData.JSONAssertions) > 0 && !binary {
+		if err := checkJSONAssertions(body, testData.JSONAssertions); err != nil {
+			result.Status = "FAILURE"
+			result.Error = err
+		}
+	}
+
+	// Make the requested response values available to later requests
+	if result.Error == nil && len(testData.Extract) > 0 && !binary {
+		if err := e.extractVariables(body, testData.Extract); err != nil {
+			result.Status = "FAILURE"
+			result.Error = err
+		}
+	}
+
+	// Compare against the recorded golden response
+	if result.Error == nil && e.config.Golden.Enabled && isReadMethod(endpoint.Method) && !binary {
+		if err := e.compareGolden(endpoint, body); err != nil {
+			result.Status = "FAILURE"
+			result.Error = err
+		}
+	}
+
+	// Keep the field-by-field differences of a failed content assertion
+	var diffErr *diffError
+	if errors.As(result.Error, &diffErr) {
+		result.Diffs = diffErr.diffs
+		if !e.config.Quiet {
+			printDiffs(endpoint.Key(), result.Diffs)
+		}
+	}
+
+	// Binary bodies are only reported by size and checksum
+	if binary {
+		return result
+	}
 
 	// Format response body if it's JSON
-	contentType := resp.Header.Get("Content-Type")
 	if strings.Contains(contentType, "application/json") {
 		var jsonResponse interface{}
 		if err := json.Unmarshal(body, &jsonResponse); err == nil {
 			// Pretty print the JSON response
 			if prettyJSON, err := json.MarshalIndent(jsonResponse, "", "  "); err == nil {
 				result.Response = string(prettyJSON)
-				fmt.Printf("Formatted JSON Response: %s\n", result.Response)
+				e.printf("Formatted JSON Response: %s\n", result.Response)
 			} else {
 				result.Response = string(body)
-				fmt.Printf("Failed to format JSON, using raw response: %s\n", result.Response)
+				e.printf("Failed to format JSON, using raw response: %s\n", result.Response)
 			}
 		} else {
 			result.Response = string(body)
-			fmt.Printf("Failed to parse JSON, using raw response: %s\n", result.Response)
+			e.printf("Failed to parse JSON, using raw response: %s\n", result.Response)
 		}
 	} else {
 		result.Response = string(body)
-		fmt.Printf("Non-JSON response: %s\n", result.Response)
+		e.printf("Non-JSON response: %s\n", result.Response)
 	}
 
 	return result
@@ -0,0 +1,69 @@
+package executor
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"auto-api-tester/internal/types"
+)
+
+// weightOf returns the endpoint's traffic weight, defaulting to 1
+func weightOf(endpoint types.Endpoint) float64 {
+	if endpoint.TestData.Weight > 0 {
+		return endpoint.TestData.Weight
+	}
+	return 1
+}
+
+// schedule expands endpoints into the list of requests to run. With sample
+// set, that many endpoints are drawn at random in proportion to their weight;
+// otherwise each endpoint runs iterations times scaled by its weight.
+func (e *TestExecutor) schedule(endpoints []types.Endpoint) []types.Endpoint {
+	if len(endpoints) == 0 {
+		return endpoints
+	}
+
+	if e.config.Sample > 0 {
+		return sampleEndpoints(endpoints, e.config.Sample, rand.New(rand.NewSource(time.Now().UnixNano())))
+	}
+
+	// A plain run exercises every endpoint once regardless of weight
+	iterations := e.config.Iterations
+	if iterations <= 0 {
+		return endpoints
+	}
+
+	scheduled := make([]types.Endpoint, 0, len(endpoints)*iterations)
+	for _, endpoint := range endpoints {
+		count := int(math.Round(float64(iterations) * weightOf(endpoint)))
+		if count < 1 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			scheduled = append(scheduled, endpoint)
+		}
+	}
+	return scheduled
+}
+
+// sampleEndpoints draws n endpoints with replacement, weighted by traffic weight
+func sampleEndpoints(endpoints []types.Endpoint, n int, rng *rand.Rand) []types.Endpoint {
+	cumulative := make([]float64, len(endpoints))
+	total := 0.0
+	for i, endpoint := range endpoints {
+		total += weightOf(endpoint)
+		cumulative[i] = total
+	}
+
+	sampled := make([]types.Endpoint, 0, n)
+	for len(sampled) < n {
+		target := rng.Float64() * total
+		i := 0
+		for i < len(cumulative)-1 && cumulative[i] <= target {
+			i++
+		}
+		sampled = append(sampled, endpoints[i])
+	}
+	return sampled
+}
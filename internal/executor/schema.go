@@ -0,0 +1,224 @@
+package executor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"auto-api-tester/internal/types"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// responseSchema returns the schema the spec declares for the returned status
+// code, falling back to the spec's default response
+func responseSchema(endpoint types.Endpoint, status int) (*openapi3.Schema, bool) {
+	response, ok := endpoint.Responses[status]
+	if !ok {
+		response, ok = endpoint.Responses[types.DefaultResponse]
+	}
+	if !ok {
+		return nil, false
+	}
+
+	switch schema := response.Schema.(type) {
+	case *openapi3.SchemaRef:
+		if schema != nil && schema.Value != nil {
+			return schema.Value, true
+		}
+	case *openapi3.Schema:
+		if schema != nil {
+			return schema, true
+		}
+	}
+	return nil, false
+}
+
+// documentedSuccess returns the operation's success status when the spec
+// declares exactly one 2xx response
+func documentedSuccess(endpoint types.Endpoint) (int, bool) {
+	success, count := 0, 0
+	for status := range endpoint.Responses {
+		if status >= 200 && status < 300 {
+			success = status
+			count++
+		}
+	}
+	return success, count == 1
+}
+
+// validateResponse validates a response body against the schema declared for
+// its status code. In strict mode fields the schema doesn't declare fail too,
+// unless they match one of the ignored field patterns.
+func validateResponse(endpoint types.Endpoint, status int, body []byte, strict bool, ignored []string) error {
+	schema, ok := responseSchema(endpoint, status)
+	if !ok {
+		return nil
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return fmt.Errorf("response for status %d is not valid JSON: %v", status, err)
+	}
+
+	// writeOnly properties (e.g. passwords) are neither required nor checked in
+	// responses; every violation is collected so all offending fields are listed
+	err := schema.VisitJSON(data, openapi3.VisitAsResponse(), openapi3.DisableWriteOnlyValidation(), openapi3.MultiErrors())
+
+	var diffs []types.FieldDiff
+	for _, schemaErr := range schemaErrors(err) {
+		diffs = append(diffs, schemaDiff(schemaErr))
+	}
+	if strict {
+		diffs = append(diffs, undeclaredFields(schema, data, nil, parseFieldPatterns(ignored))...)
+	}
+	if err != nil && len(diffs) == 0 {
+		return fmt.Errorf("response does not match the schema for status %d: %v", status, err)
+	}
+	if len(diffs) == 0 {
+		return nil
+	}
+	return newDiffError(fmt.Sprintf("response does not match the schema for status %d", status), diffs)
+}
+
+// undeclaredFields lists the object fields in data that schema neither
+// declares as a property nor allows through additionalProperties. Fields of
+// allOf, anyOf and oneOf members count as declared. Fields matching an ignored
+// pattern are skipped along with their contents.
+func undeclaredFields(schema *openapi3.Schema, data interface{}, path []string, ignored []fieldPattern) []types.FieldDiff {
+	if schema == nil {
+		return nil
+	}
+
+	var diffs []types.FieldDiff
+	switch value := data.(type) {
+	case map[string]interface{}:
+		// A type mismatch is already reported by schema validation
+		if schema.Type != nil && !schema.Type.Includes("object") {
+			return nil
+		}
+		properties, additional, open := declaredProperties(schema)
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fieldPath := append(append([]string(nil), path...), key)
+			switch {
+			case matchesAny(ignored, fieldPath):
+			case properties[key] != nil:
+				diffs = append(diffs, undeclaredFields(properties[key], value[key], fieldPath, ignored)...)
+			case additional != nil:
+				diffs = append(diffs, undeclaredFields(additional, value[key], fieldPath, ignored)...)
+			case !open:
+				diffs = append(diffs, types.FieldDiff{
+					Path:    pointerPath(fieldPath),
+					Actual:  value[key],
+					Problem: "field is not declared in the response schema",
+				})
+			}
+		}
+	case []interface{}:
+		items := itemsSchema(schema)
+		for i, item := range value {
+			diffs = append(diffs, undeclaredFields(items, item, append(append([]string(nil), path...), strconv.Itoa(i)), ignored)...)
+		}
+	}
+	return diffs
+}
+
+// declaredProperties collects the properties of schema and its composed
+// members, the schema additional fields must match, and whether the object
+// is explicitly open (additionalProperties: true) or already closed
+// (additionalProperties: false, which schema validation reports itself)
+func declaredProperties(schema *openapi3.Schema) (properties map[string]*openapi3.Schema, additional *openapi3.Schema, open bool) {
+	properties = make(map[string]*openapi3.Schema)
+	var collect func(s *openapi3.Schema)
+	collect = func(s *openapi3.Schema) {
+		if s == nil {
+			return
+		}
+		for name, prop := range s.Properties {
+			if prop != nil && prop.Value != nil {
+				properties[name] = prop.Value
+			}
+		}
+		if s.AdditionalProperties.Schema != nil && s.AdditionalProperties.Schema.Value != nil {
+			additional = s.AdditionalProperties.Schema.Value
+		}
+		if s.AdditionalProperties.Has != nil {
+			open = true
+		}
+		for _, refs := range []openapi3.SchemaRefs{s.AllOf, s.AnyOf, s.OneOf} {
+			for _, ref := range refs {
+				if ref != nil {
+					collect(ref.Value)
+				}
+			}
+		}
+	}
+	collect(schema)
+	return properties, additional, open
+}
+
+// itemsSchema returns the item schema of an array schema or its composed members
+func itemsSchema(schema *openapi3.Schema) *openapi3.Schema {
+	if schema.Items != nil && schema.Items.Value != nil {
+		return schema.Items.Value
+	}
+	for _, refs := range []openapi3.SchemaRefs{schema.AllOf, schema.AnyOf, schema.OneOf} {
+		for _, ref := range refs {
+			if ref != nil && ref.Value != nil {
+				if items := itemsSchema(ref.Value); items != nil {
+					return items
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// schemaErrors flattens the (possibly nested) multi-error of a validation into
+// its individual schema violations
+func schemaErrors(err error) []*openapi3.SchemaError {
+	var multi openapi3.MultiError
+	if errors.As(err, &multi) {
+		var all []*openapi3.SchemaError
+		for _, inner := range multi {
+			all = append(all, schemaErrors(inner)...)
+		}
+		return all
+	}
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		return []*openapi3.SchemaError{schemaErr}
+	}
+	return nil
+}
+
+// schemaDiff describes a schema violation at the location of the offending value
+func schemaDiff(err *openapi3.SchemaError) types.FieldDiff {
+	return types.FieldDiff{
+		Path:    pointerPath(err.JSONPointer()),
+		Actual:  err.Value,
+		Problem: err.Reason,
+	}
+}
+
+// pointerPath renders JSON pointer segments as a JSONPath like $.items[0].id
+func pointerPath(segments []string) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, segment := range segments {
+		if _, err := strconv.Atoi(segment); err == nil {
+			fmt.Fprintf(&b, "[%s]", segment)
+		} else {
+			b.WriteString("." + segment)
+		}
+	}
+	return b.String()
+}
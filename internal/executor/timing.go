@@ -0,0 +1,77 @@
+package executor
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"auto-api-tester/internal/types"
+)
+
+// timingTrace records the connection phases of a request via httptrace
+type timingTrace struct {
+	mu sync.Mutex
+
+	dnsStart, dnsDone         time.Time
+	connectStart, connectDone time.Time
+	tlsStart, tlsDone         time.Time
+	firstByte                 time.Time
+	reused                    bool
+}
+
+// withTimingTrace returns a copy of req that reports its connection phases
+// to the returned trace
+func withTimingTrace(req *http.Request) (*http.Request, *timingTrace) {
+	t := &timingTrace{}
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { t.mark(&t.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { t.mark(&t.dnsDone) },
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			// Dialing several addresses reports several starts; time from the first
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+		},
+		ConnectDone:       func(string, string, error) { t.mark(&t.connectDone) },
+		TLSHandshakeStart: func() { t.mark(&t.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { t.mark(&t.tlsDone) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			t.reused = info.Reused
+			t.mu.Unlock()
+		},
+		GotFirstResponseByte: func() { t.mark(&t.firstByte) },
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace)), t
+}
+
+func (t *timingTrace) mark(at *time.Time) {
+	t.mu.Lock()
+	*at = time.Now()
+	t.mu.Unlock()
+}
+
+// timings summarizes the trace of a request sent at start that took total
+func (t *timingTrace) timings(start time.Time, total time.Duration) *types.Timings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	since := func(from, to time.Time) time.Duration {
+		if from.IsZero() || to.IsZero() {
+			return 0
+		}
+		return to.Sub(from)
+	}
+	return &types.Timings{
+		DNS:        since(t.dnsStart, t.dnsDone),
+		Connect:    since(t.connectStart, t.connectDone),
+		TLS:        since(t.tlsStart, t.tlsDone),
+		TTFB:       since(start, t.firstByte),
+		Total:      total,
+		ConnReused: t.reused,
+	}
+}
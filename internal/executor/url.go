@@ -0,0 +1,67 @@
+package executor
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// resolveURL returns the URL an endpoint path is sent to. A relative path is
+// appended to baseURL, or to fallback, the test data's own base URL, when
+// none is configured. An absolute path is kept, with its scheme and host
+// replaced by those of baseURL when one is configured.
+func resolveURL(path, baseURL, fallback string) (string, error) {
+	if isAbsoluteURL(path) {
+		if baseURL == "" {
+			return path, nil
+		}
+		return overrideOrigin(path, baseURL)
+	}
+
+	if baseURL == "" {
+		baseURL = fallback
+	}
+	if baseURL == "" {
+		return "", fmt.Errorf("endpoint path %s is relative: set a base URL with -base-url, test.base_url or base_url in the test data", path)
+	}
+	if base, err := url.Parse(baseURL); err != nil || base.Scheme == "" || base.Host == "" {
+		return "", fmt.Errorf("invalid base URL %q", baseURL)
+	}
+	return strings.TrimSuffix(baseURL, "/") + "/" + strings.TrimPrefix(path, "/"), nil
+}
+
+// isAbsoluteURL reports whether path carries its own scheme and host
+func isAbsoluteURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// overrideOrigin replaces the scheme and host of rawURL with those of baseURL
+// while keeping its path, so testdata recorded against one environment can be
+// run against another
+func overrideOrigin(rawURL, baseURL string) (string, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil || base.Scheme == "" || base.Host == "" {
+		return "", fmt.Errorf("invalid base URL %q", baseURL)
+	}
+
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint URL %q: %w", rawURL, err)
+	}
+
+	target.Scheme = base.Scheme
+	target.Host = base.Host
+	target.User = base.User
+	return target.String(), nil
+}
+
+// canonicalURL returns rawURL with its query parameters sorted by key and
+// escaped, so URLs that differ only in parameter order compare equal
+func canonicalURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery == "" {
+		return rawURL
+	}
+	u.RawQuery = u.Query().Encode()
+	return u.String()
+}
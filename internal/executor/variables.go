@@ -0,0 +1,149 @@
+package executor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// placeholderPattern matches {{name}} and {{name.field[0].sub}} references
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+
+// Variables is a concurrency-safe store of values shared between requests.
+// Test data references them as {{name}}, optionally followed by a path into
+// the value such as {{createdOrder.id}} or {{items[0].sku}}.
+type Variables struct {
+	mu     sync.RWMutex
+	values map[string]interface{}
+}
+
+// NewVariables creates a variable store seeded with the given values
+func NewVariables(initial map[string]interface{}) *Variables {
+	values := make(map[string]interface{}, len(initial))
+	for name, value := range initial {
+		values[name] = value
+	}
+	return &Variables{values: values}
+}
+
+// Set stores a variable, replacing any previous value
+func (v *Variables) Set(name string, value interface{}) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.values[name] = value
+}
+
+// Lookup resolves a reference such as "createdOrder.id" against the store.
+// A reference selecting several values, through a wildcard, resolves to the
+// list of them.
+func (v *Variables) Lookup(ref string) (interface{}, bool) {
+	segments, err := parsePath(ref)
+	if err != nil || len(segments) == 0 || segments[0].wildcard || segments[0].recursive {
+		return nil, false
+	}
+
+	v.mu.RLock()
+	value, ok := v.values[segments[0].key]
+	v.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+
+	matches := selectPath(value, segments[1:])
+	switch {
+	case len(matches) == 0:
+		return nil, false
+	case len(matches) == 1 && !hasWildcard(segments):
+		return matches[0], true
+	}
+	return matches, true
+}
+
+// hasWildcard reports whether a path can select more than one value
+func hasWildcard(segments []pathSegment) bool {
+	for _, segment := range segments {
+		if segment.wildcard || segment.recursive {
+			return true
+		}
+	}
+	return false
+}
+
+// Substitute returns a copy of a decoded JSON value with every placeholder
+// replaced. A string consisting of a single placeholder resolves to the
+// variable's native type, while placeholders embedded in longer text are
+// formatted into the string.
+func (v *Variables) Substitute(value interface{}) (interface{}, error) {
+	switch val := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(val))
+		for key, item := range val {
+			substituted, err := v.Substitute(item)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = substituted
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(val))
+		for i, item := range val {
+			substituted, err := v.Substitute(item)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = substituted
+		}
+		return result, nil
+	case string:
+		return v.substituteString(val)
+	default:
+		return value, nil
+	}
+}
+
+// substituteString replaces the placeholders within a single string
+func (v *Variables) substituteString(s string) (interface{}, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+
+	// A lone placeholder keeps the variable's type
+	if match := placeholderPattern.FindStringSubmatchIndex(s); match != nil && match[0] == 0 && match[1] == len(s) {
+		ref := s[match[2]:match[3]]
+		value, ok := v.Lookup(ref)
+		if !ok {
+			return nil, fmt.Errorf("undefined variable %q", ref)
+		}
+		return value, nil
+	}
+
+	var missing string
+	result := placeholderPattern.ReplaceAllStringFunc(s, func(placeholder string) string {
+		ref := placeholderPattern.FindStringSubmatch(placeholder)[1]
+		value, ok := v.Lookup(ref)
+		if !ok {
+			if missing == "" {
+				missing = ref
+			}
+			return placeholder
+		}
+		return formatValue(value)
+	})
+	if missing != "" {
+		return nil, fmt.Errorf("undefined variable %q", missing)
+	}
+	return result, nil
+}
+
+// formatValue renders a decoded JSON value as text for a path, query string
+// or larger string. Numbers decode as float64, which fmt would print in
+// exponent form, turning an id of 1234567 into 1.234567e+06.
+func formatValue(value interface{}) string {
+	if f, ok := value.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
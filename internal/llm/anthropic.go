@@ -0,0 +1,140 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"auto-api-tester/internal/logger"
+)
+
+const (
+	// anthropicMessagesURL is the endpoint of Anthropic's messages API
+	anthropicMessagesURL = "https://api.anthropic.com/v1/messages"
+	// anthropicVersion is the API version sent in the anthropic-version header
+	anthropicVersion = "2023-06-01"
+	// anthropicDefaultMaxTokens is used when the config leaves MaxTokens unset,
+	// since the messages API requires it
+	anthropicDefaultMaxTokens = 2000
+)
+
+// AnthropicClient implements the LLMClient interface using Anthropic's messages API
+type AnthropicClient struct {
+	*BaseClient
+	client *http.Client
+	url    string
+}
+
+// anthropicMessage is a single turn of a messages API conversation
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// anthropicRequest is the body of a messages API call
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature float64            `json:"temperature"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+}
+
+// anthropicResponse holds the parts of a messages API response we read
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// NewAnthropicClient creates a new Anthropic client
+func NewAnthropicClient(config *Config, logger *logger.Logger) *AnthropicClient {
+	messagesURL := anthropicMessagesURL
+	if config.BaseURL != "" {
+		messagesURL = strings.TrimRight(config.BaseURL, "/") + "/v1/messages"
+	}
+	return &AnthropicClient{
+		BaseClient: NewBaseClient(config, logger),
+		client:     &http.Client{},
+		url:        messagesURL,
+	}
+}
+
+// callLLM implements the actual LLM API call for Anthropic
+func (c *AnthropicClient) callLLM(ctx context.Context, prompt string) (string, error) {
+	maxTokens := c.config.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = anthropicDefaultMaxTokens
+	}
+	body, err := json.Marshal(anthropicRequest{
+		Model:       c.config.Model,
+		MaxTokens:   maxTokens,
+		Temperature: c.config.Temperature,
+		System:      "You are a helpful assistant that analyzes data and generates test data. Always respond in the requested format, as a single JSON object without surrounding text.",
+		Messages: []anthropicMessage{
+			{Role: "user", Content: prompt},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", c.config.APIKey)
+	req.Header.Set("anthropic-version", anthropicVersion)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("Anthropic API error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("Anthropic API error: %w", err)
+	}
+
+	var result anthropicResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return "", fmt.Errorf("Anthropic API error: status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if result.Error != nil {
+		return "", fmt.Errorf("Anthropic API error: status %d: %s: %s", resp.StatusCode, result.Error.Type, result.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Anthropic API error: status %d", resp.StatusCode)
+	}
+
+	var text strings.Builder
+	for _, block := range result.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return "", fmt.Errorf("no response from Anthropic")
+	}
+
+	return text.String(), nil
+}
+
+// ValidateResponse validates the LLM response format
+func (c *AnthropicClient) ValidateResponse(response string, expectedType interface{}) error {
+	if err := json.Unmarshal([]byte(response), expectedType); err != nil {
+		return fmt.Errorf("invalid response format: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package llm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// cacheEntry is a cached LLM response as stored on disk
+type cacheEntry struct {
+	Provider    string  `json:"provider"`
+	Model       string  `json:"model"`
+	Temperature float64 `json:"temperature"`
+	Response    string  `json:"response"`
+}
+
+// cacheKey hashes a prompt with the settings that change its answer
+func cacheKey(config *Config, prompt string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%s\x00%g\x00%d\x00%s",
+		config.Provider, config.BaseURL, config.Model, config.Temperature, config.MaxTokens, prompt)))
+	return hex.EncodeToString(sum[:])
+}
+
+// cachePath returns the file holding the cached response for key
+func (c *BaseClient) cachePath(key string) string {
+	return filepath.Join(c.config.CacheDir, key+".json")
+}
+
+// cachedResponse returns the cached response for key, if there is one
+func (c *BaseClient) cachedResponse(key string) (string, bool) {
+	data, err := os.ReadFile(c.cachePath(key))
+	if err != nil {
+		return "", false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return "", false
+	}
+	return entry.Response, true
+}
+
+// cacheResponse caches the response to prompt when CacheDir is set. Callers
+// use it once the response has parsed, so a truncated or malformed answer
+// is never replayed.
+func (c *BaseClient) cacheResponse(prompt, response string) {
+	if c.config.CacheDir == "" {
+		return
+	}
+	if err := c.storeResponse(cacheKey(c.config, prompt), response); err != nil {
+		c.logf("Failed to cache LLM response: %v\n", err)
+	}
+}
+
+// storeResponse caches a response under key
+func (c *BaseClient) storeResponse(key, response string) error {
+	if err := os.MkdirAll(c.config.CacheDir, 0755); err != nil {
+		return fmt.Errorf("failed to create LLM cache directory: %w", err)
+	}
+	data, err := json.MarshalIndent(cacheEntry{
+		Provider:    c.config.Provider,
+		Model:       c.config.Model,
+		Temperature: c.config.Temperature,
+		Response:    response,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.cachePath(key), data, 0644)
+}
+
+// logf writes to the LLM log when one is open
+func (c *BaseClient) logf(format string, args ...interface{}) {
+	if c.logger != nil {
+		c.logger.Printf(format, args...)
+	}
+}
@@ -60,6 +60,8 @@ Respond in JSON format matching the AnalysisResult.DataPatterns structure.`,
 		return nil, fmt.Errorf("failed to parse LLM response: %w", err)
 	}
 
+	c.cacheResponse(prompt, response)
+
 	c.logger.LogLLMInteraction("AnalyzeColumn", map[string]interface{}{
 		"table":  tableName,
 		"column": columnName,
@@ -154,6 +156,8 @@ Respond in JSON matching EnhancedAnalysisResult structure.`,
 		return nil, fmt.Errorf("failed to parse LLM response: %w", err)
 	}
 
+	c.cacheResponse(prompt, response)
+
 	c.logger.LogLLMInteraction("AnalyzeRelationships", map[string]interface{}{
 		"table":  tableName,
 		"schema": schema,
@@ -235,6 +239,8 @@ Example structure (based on your API template):
 		return nil, fmt.Errorf("failed to parse LLM response: %w", err)
 	}
 
+	c.cacheResponse(prompt, response)
+
 	c.logger.LogLLMInteraction("AnalyzeBusinessRules", map[string]interface{}{
 		"table":    tableName,
 		"endpoint": endpoint,
@@ -271,6 +277,8 @@ Respond with a boolean value (true/false) and any validation errors.`,
 	// Parse the boolean response
 	valid := strings.TrimSpace(strings.ToLower(response)) == "true"
 
+	c.cacheResponse(prompt, response)
+
 	c.logger.LogLLMInteraction("ValidateTestData", map[string]interface{}{
 		"table":    tableName,
 		"testData": testData,
@@ -311,6 +319,8 @@ Respond with a JSON object containing the test data.`,
 		return nil, fmt.Errorf("failed to parse LLM response: %w", err)
 	}
 
+	c.cacheResponse(prompt, response)
+
 	c.logger.LogLLMInteraction("GenerateTestData", map[string]interface{}{
 		"table":    tableName,
 		"analysis": analysis,
@@ -319,8 +329,19 @@ Respond with a JSON object containing the test data.`,
 	return testData, nil
 }
 
-// callLLM handles the LLM API call based on the configured provider
+// callLLM handles the LLM API call based on the configured provider,
+// answering from the on-disk cache when CacheDir is set and RefreshCache
+// isn't. Responses are stored by cacheResponse after they parse.
 func (c *BaseClient) callLLM(ctx context.Context, prompt string) (string, error) {
+	if c.config.CacheDir != "" && !c.config.RefreshCache {
+		key := cacheKey(c.config, prompt)
+		if response, ok := c.cachedResponse(key); ok {
+			c.logf("LLM cache hit %s\n", key)
+			return response, nil
+		}
+		c.logf("LLM cache miss %s\n", key)
+	}
+
 	// Create a new client based on the provider
 	client, err := NewClient(c.config, c.logger)
 	if err != nil {
@@ -8,7 +8,17 @@ type Config struct {
 	// APIKey is the API key for the LLM provider
 	APIKey string `json:"api_key"`
 
-	// Model specifies which model to use (e.g., "gpt-4", "claude-2")
+	// BaseURL points the client at another endpoint such as a local proxy or
+	// an Azure OpenAI resource; empty uses the provider's public API
+	BaseURL string `json:"base_url,omitempty"`
+
+	// APIVersion is the Azure OpenAI api-version; Deployment is the Azure
+	// deployment to call, defaulting to the model name. Both can also be given
+	// in a deployment-style BaseURL.
+	APIVersion string `json:"api_version,omitempty"`
+	Deployment string `json:"deployment,omitempty"`
+
+	// Model specifies which model to use (e.g., "gpt-4o-mini", "claude-sonnet-4-5")
 	Model string `json:"model"`
 
 	// Temperature controls the randomness of the output (0.0 to 1.0)
@@ -17,6 +27,15 @@ type Config struct {
 	// MaxTokens limits the length of the generated response
 	MaxTokens int `json:"max_tokens"`
 
+	// CacheDir stores responses keyed by a hash of the prompt, provider,
+	// base URL, model, temperature and max tokens, so identical prompts
+	// aren't sent again; empty disables the cache
+	CacheDir string `json:"cache_dir,omitempty"`
+
+	// RefreshCache queries the model even for cached prompts, replacing
+	// their entries in CacheDir with the new responses
+	RefreshCache bool `json:"-"`
+
 	// AnalysisConfig contains specific configuration for analysis tasks
 	AnalysisConfig struct {
 		// SampleSize is the number of rows to analyze for patterns
@@ -12,6 +12,8 @@ func NewClient(config *Config, logger *logger.Logger) (LLMClient, error) {
 	case "openai":
 		fmt.Printf("Creating OpenAI client with config: %+v\n", config.APIKey)
 		return NewOpenAIClient(config, logger), nil
+	case "anthropic":
+		return NewAnthropicClient(config, logger), nil
 	default:
 		return nil, fmt.Errorf("unsupported LLM provider: %s", config.Provider)
 	}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"strings"
 
 	"auto-api-tester/internal/logger"
 
@@ -18,13 +20,63 @@ type OpenAIClient struct {
 
 // NewOpenAIClient creates a new OpenAI client
 func NewOpenAIClient(config *Config, logger *logger.Logger) *OpenAIClient {
-	client := openai.NewClient(config.APIKey)
+	client := openai.NewClientWithConfig(openAIClientConfig(config))
 	return &OpenAIClient{
 		BaseClient: NewBaseClient(config, logger),
 		client:     client,
 	}
 }
 
+// azureDefaultAPIVersion is used for Azure endpoints that don't name a version
+const azureDefaultAPIVersion = "2024-06-01"
+
+// openAIClientConfig builds the go-openai config for config.BaseURL. Azure
+// OpenAI resources (*.openai.azure.com, or any URL when APIVersion or
+// Deployment is set) use api-key auth and deployment paths; a BaseURL of the
+// form https://<resource>.openai.azure.com/openai/deployments/<name>?api-version=<v>
+// supplies the deployment and version itself.
+func openAIClientConfig(config *Config) openai.ClientConfig {
+	if config.BaseURL == "" {
+		return openai.DefaultConfig(config.APIKey)
+	}
+
+	base, err := url.Parse(config.BaseURL)
+	if err != nil || !isAzureEndpoint(base, config) {
+		clientConfig := openai.DefaultConfig(config.APIKey)
+		clientConfig.BaseURL = strings.TrimRight(config.BaseURL, "/")
+		return clientConfig
+	}
+
+	deployment, apiVersion := config.Deployment, config.APIVersion
+	if path, name, ok := strings.Cut(base.Path, "/openai/deployments/"); ok {
+		base.Path = path
+		if deployment == "" {
+			deployment, _, _ = strings.Cut(name, "/")
+		}
+	}
+	if apiVersion == "" {
+		apiVersion = base.Query().Get("api-version")
+	}
+	if apiVersion == "" {
+		apiVersion = azureDefaultAPIVersion
+	}
+	base.RawQuery = ""
+
+	clientConfig := openai.DefaultAzureConfig(config.APIKey, strings.TrimRight(base.String(), "/"))
+	clientConfig.APIVersion = apiVersion
+	if deployment != "" {
+		clientConfig.AzureModelMapperFunc = func(string) string { return deployment }
+	}
+	return clientConfig
+}
+
+// isAzureEndpoint reports whether base is an Azure OpenAI resource
+func isAzureEndpoint(base *url.URL, config *Config) bool {
+	return strings.HasSuffix(base.Hostname(), ".openai.azure.com") ||
+		strings.Contains(base.Path, "/openai/deployments/") ||
+		config.APIVersion != "" || config.Deployment != ""
+}
+
 // callLLM implements the actual LLM API call for OpenAI
 func (c *OpenAIClient) callLLM(ctx context.Context, prompt string) (string, error) {
 	resp, err := c.client.CreateChatCompletion(
@@ -0,0 +1,204 @@
+package mockserver
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Spec is the bundled OpenAPI document describing the mock endpoints
+//
+//go:embed spec.json
+var Spec []byte
+
+// SpecPath is where the mock server serves Spec, one of the URLs the parser tries
+const SpecPath = "/swagger/v1/swagger.json"
+
+// User is the resource served by the mock endpoints
+type User struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Server is an in-memory implementation of the bundled spec
+type Server struct {
+	mu     sync.Mutex
+	users  map[int]User
+	nextID int
+}
+
+// New creates a mock server seeded with a sample user
+func New() *Server {
+	s := &Server{users: make(map[int]User), nextID: 1}
+	s.create(User{Name: "Ada Lovelace", Email: "ada@example.com", Role: "admin"})
+	return s
+}
+
+// Handler returns the HTTP handler serving the spec and the mock endpoints
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+SpecPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(Spec)
+	})
+	mux.HandleFunc("GET /api/health", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("GET /api/users", s.listUsers)
+	mux.HandleFunc("POST /api/users", s.createUser)
+	mux.HandleFunc("GET /api/users/{id}", s.getUser)
+	mux.HandleFunc("PUT /api/users/{id}", s.updateUser)
+	mux.HandleFunc("DELETE /api/users/{id}", s.deleteUser)
+	return mux
+}
+
+// ListenAndServe serves the mock API on addr until the server fails
+func (s *Server) ListenAndServe(addr string) error {
+	return http.ListenAndServe(addr, s.Handler())
+}
+
+func (s *Server) listUsers(w http.ResponseWriter, r *http.Request) {
+	limit := 100
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = parsed
+	}
+
+	s.mu.Lock()
+	users := make([]User, 0, len(s.users))
+	for _, user := range s.users {
+		users = append(users, user)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	if len(users) > limit {
+		users = users[:limit]
+	}
+	writeJSON(w, http.StatusOK, users)
+}
+
+func (s *Server) createUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := decodeUser(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusCreated, s.create(user))
+}
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	user, exists := s.users[id]
+	s.mu.Unlock()
+
+	if !exists {
+		writeError(w, http.StatusNotFound, "user not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, user)
+}
+
+func (s *Server) updateUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	update, ok := decodeUser(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, exists := s.users[id]
+	if !exists {
+		writeError(w, http.StatusNotFound, "user not found")
+		return
+	}
+	user.Name = update.Name
+	user.Email = update.Email
+	user.Role = update.Role
+	s.users[id] = user
+	writeJSON(w, http.StatusOK, user)
+}
+
+func (s *Server) deleteUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	_, exists := s.users[id]
+	delete(s.users, id)
+	s.mu.Unlock()
+
+	if !exists {
+		writeError(w, http.StatusNotFound, "user not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// create stores a new user with a fresh id
+func (s *Server) create(user User) User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user.ID = s.nextID
+	user.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	s.nextID++
+	s.users[user.ID] = user
+	return user
+}
+
+// decodeUser reads a NewUser body, writing a 400 response when it is invalid
+func decodeUser(w http.ResponseWriter, r *http.Request) (User, bool) {
+	var user User
+	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+		return user, false
+	}
+	if user.Name == "" || user.Email == "" {
+		writeError(w, http.StatusBadRequest, "name and email are required")
+		return user, false
+	}
+	return user, true
+}
+
+// pathID parses the {id} path segment, writing a 400 response when it is invalid
+func pathID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "id must be an integer")
+		return 0, false
+	}
+	return id, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,46 @@
+package parser
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// FetchAttempt records the outcome of fetching the spec from one candidate URL
+type FetchAttempt struct {
+	URL string
+	// StatusCode is zero when no response was received
+	StatusCode int
+	Err        error
+}
+
+// SpecFetchError is returned when the spec could not be fetched from any candidate URL
+type SpecFetchError struct {
+	Attempts []FetchAttempt
+}
+
+func (e *SpecFetchError) Error() string {
+	var b strings.Builder
+	b.WriteString("failed to fetch OpenAPI documentation from any known URL:")
+	for _, attempt := range e.Attempts {
+		fmt.Fprintf(&b, "\n  %s: ", attempt.URL)
+		if attempt.StatusCode != 0 {
+			fmt.Fprintf(&b, "%d %s", attempt.StatusCode, http.StatusText(attempt.StatusCode))
+			if attempt.StatusCode == http.StatusUnauthorized || attempt.StatusCode == http.StatusForbidden {
+				b.WriteString(" (the spec may exist here but require authentication)")
+			}
+			continue
+		}
+		b.WriteString(attempt.Err.Error())
+	}
+	return b.String()
+}
+
+// statusError reports a non-200 response from a spec URL
+type statusError struct {
+	StatusCode int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
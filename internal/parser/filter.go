@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Filter selects the operations extracted from the spec. Each non-empty
+// field must match for an operation to be kept; the values within a field
+// are alternatives.
+type Filter struct {
+	// IncludePaths are globs, one of which the spec path must match
+	IncludePaths []string
+	// ExcludePaths are globs the spec path must match none of
+	ExcludePaths []string
+	// Tags are spec tags, one of which the operation must have
+	Tags []string
+	// Methods are HTTP methods, one of which the operation must use
+	Methods []string
+}
+
+// Validate checks that every path glob is well formed
+func (f Filter) Validate() error {
+	for _, pattern := range append(append([]string{}, f.IncludePaths...), f.ExcludePaths...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid path pattern %q: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Empty reports whether the filter keeps every operation
+func (f Filter) Empty() bool {
+	return len(f.IncludePaths) == 0 && len(f.ExcludePaths) == 0 && len(f.Tags) == 0 && len(f.Methods) == 0
+}
+
+// Matches reports whether the operation at specPath passes the filter
+func (f Filter) Matches(specPath, method string, tags []string) bool {
+	if len(f.IncludePaths) > 0 && !slices.ContainsFunc(f.IncludePaths, func(pattern string) bool {
+		return matchPath(pattern, specPath)
+	}) {
+		return false
+	}
+	if slices.ContainsFunc(f.ExcludePaths, func(pattern string) bool {
+		return matchPath(pattern, specPath)
+	}) {
+		return false
+	}
+	if len(f.Methods) > 0 && !slices.ContainsFunc(f.Methods, func(m string) bool {
+		return strings.EqualFold(m, method)
+	}) {
+		return false
+	}
+	if len(f.Tags) > 0 && !slices.ContainsFunc(tags, func(tag string) bool {
+		return slices.Contains(f.Tags, tag)
+	}) {
+		return false
+	}
+	return true
+}
+
+// matchPath reports whether pattern matches specPath or one of its parent
+// paths, so /v2/orders/* also covers /v2/orders/{id}/items
+func matchPath(pattern, specPath string) bool {
+	for p := specPath; ; p = path.Dir(p) {
+		if ok, _ := path.Match(pattern, p); ok {
+			return true
+		}
+		if p == "/" || p == "." || p == "" {
+			return false
+		}
+	}
+}
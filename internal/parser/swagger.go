@@ -1,9 +1,12 @@
 package parser
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strings"
 
 	"auto-api-tester/internal/types"
@@ -11,62 +14,152 @@ import (
 	"github.com/getkin/kin-openapi/openapi3"
 )
 
+// DefaultSpecPaths are the locations, relative to the base URL, where the
+// spec is looked for
+var DefaultSpecPaths = []string{
+	"/swagger/v1/swagger.json",
+	"/swagger.json",
+	"/v1/swagger.json",
+	"/api/swagger.json",
+	"/api/v1/swagger.json",
+	"/swagger/v1/swagger",
+	"/swagger",
+}
+
+// Options controls where the parser looks for the spec
+type Options struct {
+	// Candidates are extra spec locations, either paths relative to the base
+	// URL or absolute URLs, tried alongside DefaultSpecPaths
+	Candidates []string
+	// ReplaceDefaults tries only Candidates
+	ReplaceDefaults bool
+	// Filter limits the endpoints extracted from the spec
+	Filter Filter
+	// Transport, when set, replaces http.DefaultTransport for fetching the
+	// spec, e.g. to go through a configured proxy
+	Transport http.RoundTripper
+}
+
 // SwaggerParser handles parsing of Swagger/OpenAPI specifications
 type SwaggerParser struct {
 	baseURL string
+	options Options
 	client  *http.Client
 	doc     *openapi3.T
 }
 
 // NewSwaggerParser creates a new instance of SwaggerParser
-func NewSwaggerParser(baseURL string) *SwaggerParser {
+func NewSwaggerParser(baseURL string, options Options) *SwaggerParser {
 	return &SwaggerParser{
 		baseURL: baseURL,
-		client:  &http.Client{},
+		options: options,
+		client:  &http.Client{Transport: options.Transport},
 	}
 }
 
 // ParseEndpoints fetches and parses the Swagger documentation
 func (p *SwaggerParser) ParseEndpoints() ([]types.Endpoint, error) {
-	// Try different Swagger/OpenAPI JSON URLs
-	urls := []string{
-		fmt.Sprintf("%s/swagger/v1/swagger.json", p.baseURL),
-		fmt.Sprintf("%s/swagger.json", p.baseURL),
-		fmt.Sprintf("%s/v1/swagger.json", p.baseURL),
-		fmt.Sprintf("%s/api/swagger.json", p.baseURL),
-		fmt.Sprintf("%s/api/v1/swagger.json", p.baseURL),
-		fmt.Sprintf("%s/swagger/v1/swagger", p.baseURL),
-		fmt.Sprintf("%s/swagger", p.baseURL),
-	}
-
-	var lastErr error
-	for _, url := range urls {
+	if err := p.options.Filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	urls := p.candidateURLs()
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no spec candidate URLs configured")
+	}
+
+	doc, err := p.fetchFirst(urls)
+	if err != nil {
+		return nil, err
+	}
+	p.doc = doc
+
+	return p.extractEndpoints(), nil
+}
+
+// candidateURLs lists the URLs to try: the configured candidates followed by
+// the defaults, without duplicates
+func (p *SwaggerParser) candidateURLs() []string {
+	paths := append([]string{}, p.options.Candidates...)
+	if !p.options.ReplaceDefaults {
+		paths = append(paths, DefaultSpecPaths...)
+	}
+
+	seen := make(map[string]bool, len(paths))
+	urls := make([]string, 0, len(paths))
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		url := path
+		if !strings.Contains(path, "://") {
+			url = strings.TrimSuffix(p.baseURL, "/") + "/" + strings.TrimPrefix(path, "/")
+		}
+		if !seen[url] {
+			seen[url] = true
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// fetchFirst requests every candidate URL concurrently and returns the first
+// valid document, cancelling the other requests. When all candidates fail the
+// error lists each attempt in candidate order.
+func (p *SwaggerParser) fetchFirst(urls []string) (*openapi3.T, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	type outcome struct {
+		index int
+		doc   *openapi3.T
+		err   error
+	}
+	outcomes := make(chan outcome, len(urls))
+	for i, url := range urls {
 		fmt.Printf("Trying to fetch OpenAPI documentation from: %s\n", url)
-		p.doc, lastErr = p.fetchOpenAPIDoc(url)
-		if lastErr == nil {
+		go func(i int, url string) {
+			doc, err := p.fetchOpenAPIDoc(ctx, url)
+			outcomes <- outcome{index: i, doc: doc, err: err}
+		}(i, url)
+	}
+
+	attempts := make([]FetchAttempt, len(urls))
+	for range urls {
+		result := <-outcomes
+		url := urls[result.index]
+		if result.err == nil {
 			fmt.Printf("Successfully fetched OpenAPI documentation from: %s\n", url)
-			break
+			return result.doc, nil
 		}
-		fmt.Printf("Failed to fetch from %s: %v\n", url, lastErr)
-	}
+		fmt.Printf("Failed to fetch from %s: %v\n", url, result.err)
 
-	if p.doc == nil {
-		return nil, fmt.Errorf("failed to fetch OpenAPI documentation from any known URL. Last error: %v", lastErr)
+		attempt := FetchAttempt{URL: url, Err: result.err}
+		var status *statusError
+		if errors.As(result.err, &status) {
+			attempt.StatusCode = status.StatusCode
+		}
+		attempts[result.index] = attempt
 	}
 
-	return p.extractEndpoints(), nil
+	return nil, &SpecFetchError{Attempts: attempts}
 }
 
-// fetchOpenAPIDoc fetches the OpenAPI documentation from the given URL
-func (p *SwaggerParser) fetchOpenAPIDoc(url string) (*openapi3.T, error) {
-	resp, err := p.client.Get(url)
+// fetchOpenAPIDoc fetches the OpenAPI or Swagger 2.0 documentation from the given URL
+func (p *SwaggerParser) fetchOpenAPIDoc(ctx context.Context, url string) (*openapi3.T, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	resp, err := p.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("HTTP request failed: %v", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, &statusError{StatusCode: resp.StatusCode}
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -74,39 +167,48 @@ func (p *SwaggerParser) fetchOpenAPIDoc(url string) (*openapi3.T, error) {
 		return nil, fmt.Errorf("failed to read response body: %v", err)
 	}
 
-	loader := openapi3.NewLoader()
-	doc, err := loader.LoadFromData(body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse OpenAPI doc: %v", err)
-	}
-
-	return doc, nil
+	return loadSpec(body)
 }
 
 // extractEndpoints extracts endpoints from the OpenAPI documentation
 func (p *SwaggerParser) extractEndpoints() []types.Endpoint {
 	var endpoints []types.Endpoint
+	filtered := 0
 
 	paths := p.doc.Paths.Map()
 	for path, pathItem := range paths {
 		for method, operation := range pathItem.Operations() {
-			// Combine base URL with path
-			fullPath := p.baseURL + path
+			if !p.options.Filter.Matches(path, method, operation.Tags) {
+				filtered++
+				continue
+			}
 
+			// Paths stay relative so the test data can be run against any
+			// environment; the spec's base URL is only the default
 			endpoint := types.Endpoint{
-				Path:       fullPath,
+				Path:       path,
+				BaseURL:    p.baseURL,
 				Method:     strings.ToUpper(method),
 				Parameters: make([]types.Parameter, 0),
 				Responses:  make(map[int]types.Response),
+				Tags:       operation.Tags,
+				Summary:    operation.Summary,
+			}
+
+			// Traffic weight for sampling and iteration runs
+			if weight, ok := operation.Extensions["x-weight"].(float64); ok {
+				endpoint.Weight = weight
 			}
 
 			// Extract parameters
 			for _, param := range operation.Parameters {
 				endpoint.Parameters = append(endpoint.Parameters, types.Parameter{
-					Name:     param.Value.Name,
-					In:       param.Value.In,
-					Required: param.Value.Required,
-					Schema:   param.Value.Schema,
+					Name:        param.Value.Name,
+					In:          param.Value.In,
+					Required:    param.Value.Required,
+					Schema:      param.Value.Schema,
+					Description: param.Value.Description,
+					Example:     parameterExample(param.Value),
 				})
 			}
 
@@ -140,10 +242,12 @@ func (p *SwaggerParser) extractEndpoints() []types.Endpoint {
 			// Extract responses
 			responses := operation.Responses.Map()
 			for statusCode, response := range responses {
-				code := 0
-				fmt.Sscanf(statusCode, "%d", &code)
-				if code == 0 {
-					continue
+				code := types.DefaultResponse
+				if statusCode != "default" {
+					fmt.Sscanf(statusCode, "%d", &code)
+					if code == 0 {
+						continue
+					}
 				}
 
 				description := ""
@@ -152,7 +256,7 @@ func (p *SwaggerParser) extractEndpoints() []types.Endpoint {
 				}
 
 				var schema interface{}
-				if content, ok := response.Value.Content["application/json"]; ok && content != nil {
+				if content, ok := response.Value.Content["application/json"]; ok && content != nil && content.Schema != nil {
 					schema = content.Schema
 				}
 
@@ -166,5 +270,27 @@ func (p *SwaggerParser) extractEndpoints() []types.Endpoint {
 		}
 	}
 
+	if filtered > 0 {
+		fmt.Printf("Filtered out %d of %d endpoints\n", filtered, filtered+len(endpoints))
+	}
 	return endpoints
 }
+
+// parameterExample returns a parameter's example, or the first of its named
+// examples in name order
+func parameterExample(param *openapi3.Parameter) interface{} {
+	if param.Example != nil {
+		return param.Example
+	}
+	names := make([]string, 0, len(param.Examples))
+	for name := range param.Examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if example := param.Examples[name]; example != nil && example.Value != nil && example.Value.Value != nil {
+			return example.Value.Value
+		}
+	}
+	return nil
+}
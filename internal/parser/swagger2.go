@@ -0,0 +1,37 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/oasdiff/yaml"
+)
+
+// loadSpec parses a JSON or YAML spec. A Swagger 2.0 document is converted
+// to OpenAPI 3 so the rest of the parser only deals with one version.
+func loadSpec(data []byte) (*openapi3.T, error) {
+	var header struct {
+		Swagger string `json:"swagger"`
+	}
+	if err := yaml.Unmarshal(data, &header); err == nil && strings.HasPrefix(header.Swagger, "2.") {
+		var doc2 openapi2.T
+		if err := yaml.Unmarshal(data, &doc2); err != nil {
+			return nil, fmt.Errorf("failed to parse Swagger %s doc: %v", header.Swagger, err)
+		}
+		doc, err := openapi2conv.ToV3(&doc2)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert Swagger %s doc to OpenAPI 3: %v", header.Swagger, err)
+		}
+		fmt.Printf("Converted Swagger %s spec to OpenAPI 3\n", header.Swagger)
+		return doc, nil
+	}
+
+	doc, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI doc: %v", err)
+	}
+	return doc, nil
+}
@@ -0,0 +1,57 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// githubAnnotations prints failures as GitHub Actions workflow commands, which
+// the Actions UI shows as annotations on the run
+type githubAnnotations struct {
+	out io.Writer
+}
+
+// NewGitHubAnnotations returns a sink printing an ::error command for each
+// failed test and a ::warning for each skipped one when the run finishes
+func NewGitHubAnnotations() ResultSink {
+	return githubAnnotations{out: os.Stdout}
+}
+
+// Write does nothing; annotations are printed together by Finish
+func (a githubAnnotations) Write(TestResult) error {
+	return nil
+}
+
+// Finish prints the annotations for the report's results
+func (a githubAnnotations) Finish(report Report) error {
+	for _, result := range report.Results {
+		command := "error"
+		switch {
+		case result.Skipped:
+			command = "warning"
+		case isPassed(result):
+			continue
+		}
+
+		message := result.Error
+		if message == "" {
+			message = fmt.Sprintf("%s (%s)", result.Status, statusText(result.StatusCode))
+		}
+		if _, err := fmt.Fprintf(a.out, "::%s title=%s::%s\n", command, escapeProperty(result.Name()), escapeData(message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// escapeData escapes a workflow command message
+func escapeData(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(value)
+}
+
+// escapeProperty escapes a workflow command property such as title
+func escapeProperty(value string) string {
+	return strings.NewReplacer(":", "%3A", ",", "%2C").Replace(escapeData(value))
+}
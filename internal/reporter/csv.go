@@ -0,0 +1,64 @@
+package reporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// csvHeader names the columns of the CSV report
+var csvHeader = []string{"endpoint", "method", "status", "expected_status", "duration_ms", "result", "error"}
+
+// generateCSVReport writes one row per result for importing into
+// spreadsheets. The result column is pass, fail or skipped; expected_status
+// lists the expected codes separated by spaces, empty for any 2xx.
+func (r *Reporter) generateCSVReport(report Report) error {
+	if err := os.MkdirAll(r.config.OutputDir, 0755); err != nil {
+		return err
+	}
+
+	reportPath := filepath.Join(r.config.OutputDir, fmt.Sprintf("report_%s.csv", report.Timestamp.Format("20060102_150405")))
+	file, err := os.Create(reportPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	if err := w.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, result := range report.Results {
+		outcome := "fail"
+		switch {
+		case result.Skipped:
+			outcome = "skipped"
+		case isPassed(result):
+			outcome = "pass"
+		}
+		endpoint := result.Endpoint
+		if result.Case != "" {
+			endpoint += " [" + result.Case + "]"
+		}
+		row := []string{
+			endpoint,
+			result.Method,
+			strconv.Itoa(result.StatusCode),
+			strings.Trim(fmt.Sprint(result.ExpectedStatus), "[]"),
+			strconv.FormatFloat(float64(result.Duration.Microseconds())/1000, 'f', 3, 64),
+			outcome,
+			result.Error,
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return file.Close()
+}
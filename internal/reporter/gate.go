@@ -0,0 +1,70 @@
+package reporter
+
+import "fmt"
+
+// Fail-on policies deciding which results fail the run
+const (
+	FailOnNone         = "none"
+	FailOnAny          = "any"
+	FailOnServerErrors = "5xx-only"
+)
+
+// Exit codes for a run gated by a fail-on policy. Tool errors exit with 1.
+const (
+	ExitOK           = 0
+	ExitFailures     = 2
+	ExitServerErrors = 3
+)
+
+// isServerError reports whether a test failed because the server answered
+// with a 5xx status; a test expecting the 5xx passes and isn't one
+func isServerError(result TestResult) bool {
+	return result.StatusCode >= 500 && result.StatusCode < 600 && !isPassed(result)
+}
+
+// ValidateFailOn checks that failOn names a known policy
+func ValidateFailOn(failOn string) error {
+	switch failOn {
+	case "", FailOnNone, FailOnAny, FailOnServerErrors:
+		return nil
+	}
+	return fmt.Errorf("unknown fail-on policy %q (want %s, %s or %s)", failOn, FailOnNone, FailOnAny, FailOnServerErrors)
+}
+
+// ExitCode returns the process exit code for results under the failOn policy.
+// Server errors get their own exit code so they can be told apart from
+// failures caused by test data.
+func ExitCode(results []TestResult, failOn string) (int, error) {
+	failures, serverErrors := 0, 0
+	for _, result := range results {
+		if isServerError(result) {
+			serverErrors++
+		} else if !isPassed(result) {
+			failures++
+		}
+	}
+	return exitCode(failures, serverErrors, failOn)
+}
+
+// exitCode applies the failOn policy to counts of failures and server errors
+func exitCode(failures, serverErrors int, failOn string) (int, error) {
+	if err := ValidateFailOn(failOn); err != nil {
+		return 0, err
+	}
+
+	switch failOn {
+	case FailOnAny:
+		if serverErrors > 0 {
+			return ExitServerErrors, nil
+		}
+		if failures > 0 {
+			return ExitFailures, nil
+		}
+		return ExitOK, nil
+	case FailOnServerErrors:
+		if serverErrors > 0 {
+			return ExitServerErrors, nil
+		}
+	}
+	return ExitOK, nil
+}
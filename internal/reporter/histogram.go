@@ -0,0 +1,107 @@
+package reporter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// histogramEdges are the bucket upper bounds, a 1-2-5 series so that fast
+// and slow clusters both get resolution
+var histogramEdges = []time.Duration{
+	time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	20 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	200 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2 * time.Second,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+}
+
+// histogramBucket counts the results with durations in (Lower, Upper]
+type histogramBucket struct {
+	Lower time.Duration
+	// Upper is zero for the open-ended last bucket
+	Upper time.Duration
+	Count int
+}
+
+// durationHistogram buckets result durations, trimmed to the range that has data
+func durationHistogram(results []TestResult) []histogramBucket {
+	if len(results) == 0 {
+		return nil
+	}
+
+	buckets := make([]histogramBucket, len(histogramEdges)+1)
+	var lower time.Duration
+	for i, edge := range histogramEdges {
+		buckets[i] = histogramBucket{Lower: lower, Upper: edge}
+		lower = edge
+	}
+	buckets[len(histogramEdges)] = histogramBucket{Lower: lower}
+
+	for _, result := range results {
+		i := 0
+		for i < len(histogramEdges) && result.Duration > histogramEdges[i] {
+			i++
+		}
+		buckets[i].Count++
+	}
+
+	first, last := 0, len(buckets)-1
+	for buckets[first].Count == 0 {
+		first++
+	}
+	for buckets[last].Count == 0 {
+		last--
+	}
+	return buckets[first : last+1]
+}
+
+// label describes the bucket range, e.g. "50ms–100ms"
+func (b histogramBucket) label() string {
+	if b.Upper == 0 {
+		return fmt.Sprintf("> %s", b.Lower)
+	}
+	return fmt.Sprintf("%s–%s", b.Lower, b.Upper)
+}
+
+// renderHistogram renders the buckets as CSS bars, keeping the report free of external assets
+func renderHistogram(buckets []histogramBucket) string {
+	if len(buckets) == 0 {
+		return ""
+	}
+
+	peak := 0
+	for _, bucket := range buckets {
+		if bucket.Count > peak {
+			peak = bucket.Count
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(`
+        <div class="histogram">
+            <h2>Response Time Distribution</h2>
+            <div class="histogram-bars">`)
+	for _, bucket := range buckets {
+		height := bucket.Count * 100 / peak
+		fmt.Fprintf(&b, `
+                <div class="histogram-column" title="%s: %d requests">
+                    <span class="histogram-count">%d</span>
+                    <div class="histogram-bar" style="height: %d%%"></div>
+                    <span class="histogram-label">%s</span>
+                </div>`, bucket.label(), bucket.Count, bucket.Count, height, bucket.label())
+	}
+	b.WriteString(`
+            </div>
+        </div>`)
+	return b.String()
+}
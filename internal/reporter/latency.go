@@ -0,0 +1,66 @@
+package reporter
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// LatencyStats summarizes the response times of a run's results
+type LatencyStats struct {
+	Min  time.Duration
+	Max  time.Duration
+	Mean time.Duration
+	P50  time.Duration
+	P90  time.Duration
+	P99  time.Duration
+}
+
+// latencyStats computes the latency summary of the results that were sent;
+// skipped results have no response time and are left out. Without any
+// results every statistic is zero.
+func latencyStats(results []TestResult) LatencyStats {
+	durations := make([]time.Duration, 0, len(results))
+	for _, result := range results {
+		if !result.Skipped {
+			durations = append(durations, result.Duration)
+		}
+	}
+	return durationStats(durations)
+}
+
+// durationStats computes the latency summary of durations, zero when empty
+func durationStats(durations []time.Duration) LatencyStats {
+	if len(durations) == 0 {
+		return LatencyStats{}
+	}
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	return LatencyStats{
+		Min:  sorted[0],
+		Max:  sorted[len(sorted)-1],
+		Mean: total / time.Duration(len(sorted)),
+		P50:  percentile(sorted, 50),
+		P90:  percentile(sorted, 90),
+		P99:  percentile(sorted, 99),
+	}
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// roundLatency rounds a latency for display, keeping sub-millisecond detail
+func roundLatency(d time.Duration) time.Duration {
+	return d.Round(100 * time.Microsecond)
+}
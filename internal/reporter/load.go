@@ -0,0 +1,116 @@
+package reporter
+
+import (
+	"fmt"
+	"html"
+	"time"
+)
+
+// LoadResult aggregates the requests a load run made for one test case
+type LoadResult struct {
+	Endpoint string
+	Method   string
+	Case     string `json:",omitempty"`
+
+	Requests     int
+	Errors       int
+	ServerErrors int
+	// RequestsPerSecond is Requests over the duration of the run
+	RequestsPerSecond float64
+	// ErrorRate is the fraction of Requests that didn't succeed
+	ErrorRate float64
+	Latency   LatencyStats
+	// Error is an example of the failures, the last one seen
+	Error string `json:",omitempty"`
+
+	durations []time.Duration
+}
+
+// NewLoadResult aggregates a test case's requests over a run that took elapsed
+func NewLoadResult(endpoint, method, testCase string, requests, errors, serverErrors int, durations []time.Duration, lastError string, elapsed time.Duration) LoadResult {
+	result := LoadResult{
+		Endpoint:     endpoint,
+		Method:       method,
+		Case:         testCase,
+		Requests:     requests,
+		Errors:       errors,
+		ServerErrors: serverErrors,
+		Latency:      durationStats(durations),
+		Error:        lastError,
+		durations:    durations,
+	}
+	if elapsed > 0 {
+		result.RequestsPerSecond = float64(requests) / elapsed.Seconds()
+	}
+	if requests > 0 {
+		result.ErrorRate = float64(errors) / float64(requests)
+	}
+	return result
+}
+
+// Name identifies the load result's test case like TestResult.Name
+func (l LoadResult) Name() string {
+	return TestResult{Method: l.Method, Endpoint: l.Endpoint, Case: l.Case}.Name()
+}
+
+// GenerateLoadReport writes the report of a load run: the aggregate per test
+// case in place of individual results, with the request counts as the test
+// counts and the latency over every request, and returns it
+func (r *Reporter) GenerateLoadReport(results []LoadResult, elapsed time.Duration) (*Report, error) {
+	report := Report{
+		Timestamp: time.Now(),
+		Duration:  elapsed,
+		Load:      results,
+		Results:   []TestResult{},
+	}
+	var durations []time.Duration
+	for _, result := range results {
+		report.TotalTests += result.Requests
+		report.FailedTests += result.Errors
+		report.ServerErrors += result.ServerErrors
+		durations = append(durations, result.durations...)
+	}
+	report.PassedTests = report.TotalTests - report.FailedTests
+	report.Latency = durationStats(durations)
+
+	for _, sink := range r.sinks {
+		if err := sink.Finish(report); err != nil {
+			return nil, err
+		}
+	}
+	return &report, nil
+}
+
+// LoadExitCode returns the process exit code for a load run under the
+// failOn policy, as ExitCode does for individual results
+func LoadExitCode(results []LoadResult, failOn string) (int, error) {
+	failures, serverErrors := 0, 0
+	for _, result := range results {
+		failures += result.Errors - result.ServerErrors
+		serverErrors += result.ServerErrors
+	}
+	return exitCode(failures, serverErrors, failOn)
+}
+
+// renderLoadResults renders the per test case table of a load report, empty
+// for a regular run
+func renderLoadResults(results []LoadResult) string {
+	if len(results) == 0 {
+		return ""
+	}
+	rows := ""
+	for _, result := range results {
+		rows += fmt.Sprintf(`
+                    <tr><td>%s</td><td>%d</td><td>%.1f</td><td>%.1f%%</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>`,
+			html.EscapeString(result.Name()), result.Requests, result.RequestsPerSecond, result.ErrorRate*100,
+			roundLatency(result.Latency.P50), roundLatency(result.Latency.P90), roundLatency(result.Latency.P99),
+			html.EscapeString(result.Error))
+	}
+	return fmt.Sprintf(`
+        <div class="results">
+            <h2>Load Results</h2>
+            <table class="diffs">
+                <tr><th>Test</th><th>Requests</th><th>Req/s</th><th>Errors</th><th>p50</th><th>p90</th><th>p99</th><th>Last error</th></tr>%s
+            </table>
+        </div>`, rows)
+}
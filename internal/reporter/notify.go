@@ -0,0 +1,192 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Notification policies deciding when a run is announced
+const (
+	NotifyAlways    = "always"
+	NotifyOnFailure = "failure"
+)
+
+// maxListedFailures caps the failing tests named in a notification
+const maxListedFailures = 10
+
+// NotifyConfig configures the summary posted when a run completes
+type NotifyConfig struct {
+	WebhookURL string
+	// Label names the run in the message, e.g. "staging smoke"
+	Label string
+	// ReportURL links to the report, e.g. the CI job's artifacts page
+	ReportURL string
+	// Template is a text/template rendering the request body from a
+	// Summary; empty sends the summary as JSON with a Slack/Teams "text" line
+	Template string
+	// NotifyOn is "always" (default) or "failure" to stay quiet on green runs
+	NotifyOn string
+}
+
+// Summary is the compact outcome of a run sent in notifications
+type Summary struct {
+	Label        string    `json:"label,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+	Total        int       `json:"total"`
+	Passed       int       `json:"passed"`
+	Failed       int       `json:"failed"`
+	Skipped      int       `json:"skipped"`
+	ServerErrors int       `json:"server_errors"`
+	// FailureRate is the percentage of tests that failed
+	FailureRate float64 `json:"failure_rate"`
+	// DurationSeconds is the wall time of the run
+	DurationSeconds float64 `json:"duration_seconds"`
+	// Failures names the first failing tests, up to ten
+	Failures  []string `json:"failures,omitempty"`
+	ReportURL string   `json:"report_url,omitempty"`
+}
+
+// Text is a one-line description of the summary
+func (s Summary) Text() string {
+	label := "API tests"
+	if s.Label != "" {
+		label += " (" + s.Label + ")"
+	}
+	text := fmt.Sprintf("%s: %d/%d passed, %d failed (%.1f%%) in %.1fs", label, s.Passed, s.Total, s.Failed, s.FailureRate, s.DurationSeconds)
+	if s.Skipped > 0 {
+		text += fmt.Sprintf(", %d skipped", s.Skipped)
+	}
+	if s.ReportURL != "" {
+		text += " - " + s.ReportURL
+	}
+	return text
+}
+
+// notifier posts a Summary to a webhook once the run finishes
+type notifier struct {
+	config   NotifyConfig
+	template *template.Template
+	client   *http.Client
+}
+
+// NewNotifier returns a sink that posts the run summary to config.WebhookURL
+// when the report is finished
+func NewNotifier(config NotifyConfig) (ResultSink, error) {
+	if config.WebhookURL == "" {
+		return nil, fmt.Errorf("notification needs a webhook url")
+	}
+	switch config.NotifyOn {
+	case "", NotifyAlways, NotifyOnFailure:
+	default:
+		return nil, fmt.Errorf("unknown notify_on %q (want %s or %s)", config.NotifyOn, NotifyAlways, NotifyOnFailure)
+	}
+	n := &notifier{config: config, client: &http.Client{Timeout: 10 * time.Second}}
+	if config.Template != "" {
+		tmpl, err := template.New("notification").Funcs(template.FuncMap{
+			"json": func(v interface{}) (string, error) {
+				data, err := json.Marshal(v)
+				return string(data), err
+			},
+		}).Parse(config.Template)
+		if err != nil {
+			return nil, fmt.Errorf("invalid notification template: %v", err)
+		}
+		n.template = tmpl
+	}
+	return n, nil
+}
+
+// Write does nothing; only the finished run is announced
+func (n *notifier) Write(TestResult) error {
+	return nil
+}
+
+// Finish posts the summary of report, unless only failures are announced
+// and the run had none
+func (n *notifier) Finish(report Report) error {
+	if n.config.NotifyOn == NotifyOnFailure && report.FailedTests == 0 {
+		return nil
+	}
+
+	summary := Summary{
+		Label:        n.config.Label,
+		Timestamp:    report.Timestamp,
+		Total:        report.TotalTests,
+		Passed:       report.PassedTests,
+		Failed:       report.FailedTests,
+		Skipped:      report.SkippedTests,
+		ServerErrors: report.ServerErrors,
+		ReportURL:    n.config.ReportURL,
+
+		DurationSeconds: report.Duration.Seconds(),
+	}
+	for _, result := range report.Results {
+		if len(summary.Failures) == maxListedFailures {
+			break
+		}
+		if !result.Skipped && !isPassed(result) {
+			summary.Failures = append(summary.Failures, result.Name())
+		}
+	}
+	if report.TotalTests > 0 {
+		summary.FailureRate = float64(report.FailedTests) * 100 / float64(report.TotalTests)
+	}
+
+	body, err := n.render(summary)
+	if err != nil {
+		return err
+	}
+	resp, err := n.client.Post(n.config.WebhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %v", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("notification webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// render builds the request body from the template, or the default JSON
+func (n *notifier) render(summary Summary) ([]byte, error) {
+	if n.template != nil {
+		var buf bytes.Buffer
+		if err := n.template.Execute(&buf, summary); err != nil {
+			return nil, fmt.Errorf("failed to render notification: %v", err)
+		}
+		return buf.Bytes(), nil
+	}
+	return json.Marshal(struct {
+		Text        string            `json:"text"`
+		Attachments []slackAttachment `json:"attachments"`
+		Summary
+	}{summary.Text(), []slackAttachment{summary.attachment()}, summary})
+}
+
+// slackAttachment is a Slack message attachment: a colored bar beside text
+type slackAttachment struct {
+	Color string `json:"color"`
+	Text  string `json:"text,omitempty"`
+}
+
+// attachment colors the summary by outcome and lists the failing tests
+func (s Summary) attachment() slackAttachment {
+	if s.Failed == 0 {
+		return slackAttachment{Color: "good"}
+	}
+	var text strings.Builder
+	for _, name := range s.Failures {
+		fmt.Fprintf(&text, "• %s\n", name)
+	}
+	if more := s.Failed - len(s.Failures); more > 0 {
+		fmt.Fprintf(&text, "and %d more\n", more)
+	}
+	return slackAttachment{Color: "danger", Text: strings.TrimSuffix(text.String(), "\n")}
+}
@@ -0,0 +1,91 @@
+package reporter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// endpointMetrics aggregates the results of a single endpoint
+type endpointMetrics struct {
+	method   string
+	endpoint string
+	runs     int
+	seconds  float64
+	success  bool
+}
+
+// writePrometheusMetrics writes the report in the Prometheus textfile collector
+// format. When an endpoint ran more than once its duration is the mean of all
+// runs and it only counts as successful if every run passed.
+func (r *Reporter) writePrometheusMetrics(report Report) error {
+	metrics := make(map[string]*endpointMetrics)
+	var keys []string
+	for _, result := range report.Results {
+		key := result.Method + " " + result.Endpoint
+		m, ok := metrics[key]
+		if !ok {
+			m = &endpointMetrics{method: result.Method, endpoint: result.Endpoint, success: true}
+			metrics[key] = m
+			keys = append(keys, key)
+		}
+		m.runs++
+		m.seconds += result.Duration.Seconds()
+		if !isPassed(result) {
+			m.success = false
+		}
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("# HELP api_test_request_duration_seconds Duration of the API test request.\n")
+	b.WriteString("# TYPE api_test_request_duration_seconds gauge\n")
+	for _, key := range keys {
+		m := metrics[key]
+		fmt.Fprintf(&b, "api_test_request_duration_seconds{%s} %g\n", m.labels(), m.seconds/float64(m.runs))
+	}
+
+	b.WriteString("# HELP api_test_request_success Whether the API test passed (1) or failed (0).\n")
+	b.WriteString("# TYPE api_test_request_success gauge\n")
+	for _, key := range keys {
+		m := metrics[key]
+		success := 0
+		if m.success {
+			success = 1
+		}
+		fmt.Fprintf(&b, "api_test_request_success{%s} %d\n", m.labels(), success)
+	}
+
+	writeGauge(&b, "api_test_run_tests", "Number of tests executed in the last run.", float64(report.TotalTests))
+	writeGauge(&b, "api_test_run_passed", "Number of tests that passed in the last run.", float64(report.PassedTests))
+	writeGauge(&b, "api_test_run_failed", "Number of tests that failed in the last run.", float64(report.FailedTests))
+	writeGauge(&b, "api_test_run_duration_seconds", "Duration of the last run.", report.Duration.Seconds())
+	writeGauge(&b, "api_test_run_timestamp_seconds", "Unix time the last run finished.", float64(report.Timestamp.Unix()))
+
+	// Write to a temporary file first so the collector never reads a partial file
+	if err := os.MkdirAll(filepath.Dir(r.config.MetricsFile), 0755); err != nil {
+		return err
+	}
+	tmpPath := r.config.MetricsFile + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(b.String()), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, r.config.MetricsFile)
+}
+
+// labels renders the Prometheus label set for the endpoint
+func (m *endpointMetrics) labels() string {
+	return fmt.Sprintf(`method="%s",endpoint="%s"`, escapeLabel(m.method), escapeLabel(m.endpoint))
+}
+
+// writeGauge writes a single unlabeled gauge with its metadata
+func writeGauge(b *strings.Builder, name, help string, value float64) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s gauge\n%s %g\n", name, help, name, name, value)
+}
+
+// escapeLabel escapes a Prometheus label value
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
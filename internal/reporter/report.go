@@ -4,9 +4,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"html"
+	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+
+	"auto-api-tester/internal/types"
 )
 
 // Report represents the test execution report
@@ -15,24 +22,58 @@ type Report struct {
 	TotalTests  int
 	PassedTests int
 	FailedTests int
-	Duration    time.Duration
-	Results     []TestResult
+	// ServerErrors counts the failed tests where the server returned a 5xx
+	ServerErrors int
+	// SkippedTests were not sent, e.g. because a circuit breaker was open
+	SkippedTests int
+	Duration     time.Duration
+	// Latency summarizes the response times of the results
+	Latency LatencyStats
+	// Load holds the per test case aggregates of a load run, which reports
+	// no individual results
+	Load    []LoadResult `json:",omitempty"`
+	Results []TestResult
 }
 
 // TestResult represents a single test result
 type TestResult struct {
-	Endpoint    string
-	Method      string
-	Status      int
+	Endpoint string
+	Method   string
+	// Case names the endpoint's test case when it has several
+	Case string `json:",omitempty"`
+	// Status is the outcome: SUCCESS, FAILURE, ERROR or SKIPPED
+	Status string
+	// StatusCode is the HTTP status the server returned, zero without a response
+	StatusCode int
+	// ExpectedStatus lists the codes the test passes with; empty means any 2xx
+	ExpectedStatus []int `json:",omitempty"`
+	// Skipped results were never sent; Error gives the reason
+	Skipped     bool
 	Duration    time.Duration
 	Error       string
 	RequestBody interface{}
+	// Response is the parsed body when it is JSON and nil otherwise;
+	// RawResponse always holds the body text, so HTML error pages and
+	// plain-text errors are reported as sent
 	Response    interface{}
+	RawResponse string
+	ContentType string `json:",omitempty"`
+	// ResponseHeaders are the response's headers, with sensitive values redacted
+	ResponseHeaders map[string][]string `json:",omitempty"`
+	// Timings breaks the request down into connection phases
+	Timings *types.Timings `json:",omitempty"`
+	// Diffs lists the field-level differences behind a failed content assertion
+	Diffs []types.FieldDiff `json:",omitempty"`
 }
 
 // Reporter handles the generation of test reports
 type Reporter struct {
 	config ReportingConfig
+	// sinks receive the results; unless SkipFiles is set, the first one
+	// writes the report files
+	sinks []ResultSink
+	// start is when the reporter was created, just before the run
+	start time.Time
 }
 
 // ReportingConfig holds the configuration for reporting
@@ -40,19 +81,79 @@ type ReportingConfig struct {
 	Format    []string
 	OutputDir string
 	Detailed  bool
+
+	// MetricsFile is the path of a Prometheus textfile-collector file written after each run
+	MetricsFile string
+
+	// Sort orders the results before rendering: failed-first (default),
+	// slowest-first or alphabetical
+	Sort string
+
+	// Template is an optional html/template file used instead of the built-in
+	// HTML layout; it is executed with the Report as data
+	Template string
+
+	// SkipFiles writes neither the report formats nor the metrics file, for
+	// callers that only use the returned Report
+	SkipFiles bool
+}
+
+// Formats are the report formats the reporter can write
+var Formats = []string{"json", "html", "csv"}
+
+// ValidateFormats checks that every report format is known
+func ValidateFormats(formats []string) error {
+	for _, format := range formats {
+		if !slices.Contains(Formats, format) {
+			return fmt.Errorf("unknown report format %q (want %s)", format, strings.Join(Formats, ", "))
+		}
+	}
+	return nil
+}
+
+// RunDir returns the per-run subdirectory of outputDir for a run started at start
+func RunDir(outputDir string, start time.Time) string {
+	return filepath.Join(outputDir, fmt.Sprintf("run_%s", start.Format("20060102_150405")))
 }
 
 // NewReporter creates a new instance of Reporter
 func NewReporter(config ReportingConfig) *Reporter {
-	return &Reporter{
+	r := &Reporter{
 		config: config,
+		start:  time.Now(),
+	}
+	if !config.SkipFiles {
+		r.sinks = []ResultSink{fileSink{r: r}}
 	}
+	return r
+}
+
+// AddSink adds a sink that receives results alongside the report files
+func (r *Reporter) AddSink(sink ResultSink) {
+	r.sinks = append(r.sinks, sink)
 }
 
-// GenerateReport generates the test execution report
-func (r *Reporter) GenerateReport(results []TestResult) error {
+// Write streams a completed result to every sink. Sink errors are printed
+// rather than returned so a failing backend doesn't stop the run.
+func (r *Reporter) Write(result TestResult) {
+	for _, sink := range r.sinks {
+		if err := sink.Write(result); err != nil {
+			fmt.Printf("Failed to send result to sink: %v\n", err)
+		}
+	}
+}
+
+// GenerateReport generates the test execution report and returns it. Sink
+// errors are printed rather than returned.
+func (r *Reporter) GenerateReport(results []TestResult) (*Report, error) {
+	results, err := sortResults(results, r.config.Sort)
+	if err != nil {
+		return nil, err
+	}
+
 	report := Report{
 		Timestamp:   time.Now(),
+		Duration:    time.Since(r.start),
 		TotalTests:  len(results),
 		PassedTests: 0,
 		FailedTests: 0,
@@ -61,28 +162,126 @@ func (r *Reporter) GenerateReport(results []TestResult) error {
 
 	// Calculate passed and failed tests
 	for _, result := range results {
-		if result.Status >= 200 && result.Status < 300 {
+		switch {
+		case result.Skipped:
+			report.SkippedTests++
+		case isPassed(result):
 			report.PassedTests++
-		} else {
+		default:
 			report.FailedTests++
 		}
+		if isServerError(result) {
+			report.ServerErrors++
+		}
 	}
 
-	// Generate reports in specified formats
-	for _, format := range r.config.Format {
-		switch format {
-		case "json":
-			if err := r.generateJSONReport(report); err != nil {
-				return fmt.Errorf("failed to generate JSON report: %v", err)
-			}
-		case "html":
-			if err := r.generateHTMLReport(report); err != nil {
-				return fmt.Errorf("failed to generate HTML report: %v", err)
-			}
+	report.Latency = latencyStats(results)
+
+	// A failing sink, such as an unreachable webhook, doesn't keep the
+	// others from the report
+	for _, sink := range r.sinks {
+		if err := sink.Finish(report); err != nil {
+			fmt.Printf("Failed to send report to sink: %v\n", err)
 		}
 	}
 
-	return nil
+	return &report, nil
+}
+
+// renderDiffs renders assertion differences as a path/expected/actual table
+func renderDiffs(diffs []types.FieldDiff) string {
+	rows := ""
+	for _, diff := range diffs {
+		expected, actual := diffValue(diff.Expected), diffValue(diff.Actual)
+		if diff.Problem != "" {
+			actual = diff.Problem
+		}
+		rows += fmt.Sprintf(`
+                        <tr><td><code>%s</code></td><td><pre>%s</pre></td><td><pre>%s</pre></td></tr>`,
+			html.EscapeString(diff.Path), html.EscapeString(expected), html.EscapeString(actual))
+	}
+	return fmt.Sprintf(`
+                <div class="test-details">
+                    <strong>Differences:</strong>
+                    <table class="diffs">
+                        <tr><th>Path</th><th>Expected</th><th>Actual</th></tr>%s
+                    </table>
+                </div>`, rows)
+}
+
+// diffValue renders one side of a difference, empty when it is absent
+func diffValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// formatTimings describes the phases of a request on one line
+func formatTimings(t *types.Timings) string {
+	round := func(d time.Duration) time.Duration { return d.Round(100 * time.Microsecond) }
+	connection := fmt.Sprintf("DNS %s, connect %s, TLS %s", round(t.DNS), round(t.Connect), round(t.TLS))
+	if t.ConnReused {
+		connection = "reused connection"
+	}
+	return fmt.Sprintf("%s, first byte %s, total %s", connection, round(t.TTFB), round(t.Total))
+}
+
+// formatHeaders lists headers one per line as "Name: value", sorted by name
+func formatHeaders(headers map[string][]string) string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var lines []string
+	for _, name := range names {
+		for _, value := range headers[name] {
+			lines = append(lines, name+": "+value)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// statusText describes the HTTP status of a result, e.g. "503 Service Unavailable"
+func statusText(code int) string {
+	if code == 0 {
+		return "no response"
+	}
+	return strings.TrimSpace(fmt.Sprintf("%d %s", code, http.StatusText(code)))
+}
+
+// expectedText describes the codes a result expected, e.g. " (expected 400 or
+// 422)", or returns "" when any 2xx passes
+func expectedText(codes []int) string {
+	if len(codes) == 0 {
+		return ""
+	}
+	listed := make([]string, len(codes))
+	for i, code := range codes {
+		listed[i] = strconv.Itoa(code)
+	}
+	return " (expected " + strings.Join(listed, " or ") + ")"
+}
+
+// Name identifies the test as "METHOD endpoint", followed by the case name
+// in brackets when there is one
+func (r TestResult) Name() string {
+	name := r.Method + " " + r.Endpoint
+	if r.Case != "" {
+		name += " [" + r.Case + "]"
+	}
+	return name
+}
+
+// isPassed reports whether a result counts as a passed test
+func isPassed(result TestResult) bool {
+	return result.Status == "SUCCESS"
 }
 
 // generateJSONReport generates a JSON format report
@@ -115,6 +314,10 @@ func (r *Reporter) generateHTMLReport(report Report) error {
 	// Generate report file path
 	reportPath := filepath.Join(r.config.OutputDir, fmt.Sprintf("report_%s.html", report.Timestamp.Format("20060102_150405")))
 
+	if r.config.Template != "" {
+		return r.writeTemplateReport(reportPath, report)
+	}
+
 	// Create HTML content
 	htmlContent := fmt.Sprintf(`<!DOCTYPE html>
 <html lang="en">
@@ -166,6 +369,7 @@ func (r *Reporter) generateHTMLReport(report Report) error {
         .passed { color: #28a745; }
         .failed { color: #dc3545; }
         .total { color: #007bff; }
+        .skipped { color: #6c757d; }
         .results {
             margin-top: 30px;
         }
@@ -182,6 +386,22 @@ func (r *Reporter) generateHTMLReport(report Report) error {
         .test-case.failed {
             border-left: 4px solid #dc3545;
         }
+        .diffs {
+            border-collapse: collapse;
+            width: 100%%;
+        }
+        .diffs th, .diffs td {
+            border: 1px solid #dee2e6;
+            padding: 4px 8px;
+            text-align: left;
+            vertical-align: top;
+        }
+        .diffs pre {
+            margin: 0;
+        }
+        .test-case.skipped {
+            border-left: 4px solid #6c757d;
+        }
         .test-header {
             display: flex;
             justify-content: space-between;
@@ -197,6 +417,40 @@ func (r *Reporter) generateHTMLReport(report Report) error {
             color: #666;
             font-size: 0.9em;
         }
+        .histogram {
+            margin-bottom: 30px;
+        }
+        .histogram-bars {
+            display: flex;
+            align-items: flex-end;
+            gap: 6px;
+            height: 200px;
+            padding-bottom: 40px;
+        }
+        .histogram-column {
+            flex: 1;
+            display: flex;
+            flex-direction: column;
+            justify-content: flex-end;
+            align-items: center;
+            height: 100%%;
+        }
+        .histogram-bar {
+            width: 100%%;
+            min-height: 2px;
+            background-color: #007bff;
+            border-radius: 3px 3px 0 0;
+        }
+        .histogram-count {
+            font-size: 0.8em;
+            color: #666;
+        }
+        .histogram-label {
+            font-size: 0.75em;
+            color: #666;
+            height: 0;
+            white-space: nowrap;
+        }
     </style>
 </head>
 <body>
@@ -219,41 +473,61 @@ func (r *Reporter) generateHTMLReport(report Report) error {
                 <h3>Failed Tests</h3>
                 <div class="number failed">%d</div>
             </div>
+            <div class="summary-card">
+                <h3>Skipped Tests</h3>
+                <div class="number skipped">%d</div>
+            </div>
+            <div class="summary-card">
+                <h3>Server Errors (5xx)</h3>
+                <div class="number failed">%d</div>
+            </div>
             <div class="summary-card">
                 <h3>Duration</h3>
                 <div class="number">%s</div>
             </div>
+            <div class="summary-card">
+                <h3>Latency p50 / p90 / p99</h3>
+                <div class="number">%s / %s / %s</div>
+            </div>
+            <div class="summary-card">
+                <h3>Latency min / mean / max</h3>
+                <div class="number">%s / %s / %s</div>
+            </div>
         </div>
-
+%s
         <div class="results">
             <h2>Test Results</h2>`,
 		report.Timestamp.Format("2006-01-02 15:04:05"),
 		report.TotalTests,
 		report.PassedTests,
 		report.FailedTests,
-		report.Duration.Round(time.Millisecond))
+		report.SkippedTests,
+		report.ServerErrors,
+		report.Duration.Round(time.Millisecond),
+		roundLatency(report.Latency.P50), roundLatency(report.Latency.P90), roundLatency(report.Latency.P99),
+		roundLatency(report.Latency.Min), roundLatency(report.Latency.Mean), roundLatency(report.Latency.Max),
+		renderHistogram(durationHistogram(report.Results))+renderLoadResults(report.Load))
 
 	// Add test results
 	for _, result := range report.Results {
 		statusClass := "passed"
-		// A test is considered failed if:
-		// 1. There is an error message OR
-		// 2. The status code is not in the 2xx range
-		if result.Error != "" || result.Status < 200 || result.Status >= 300 {
+		if !isPassed(result) {
 			statusClass = "failed"
 		}
+		if result.Skipped {
+			statusClass = "skipped"
+		}
 
 		htmlContent += fmt.Sprintf(`
             <div class="test-case %s">
                 <div class="test-header">
-                    <strong>%s %s</strong>
-                    <span>Status: %d</span>
+                    <strong>%s</strong>
+                    <span>Status: %s</span>
                 </div>
                 <div>Duration: %s</div>`,
 			statusClass,
-			result.Method,
-			result.Endpoint,
-			result.Status,
+			html.EscapeString(result.Name()),
+			statusText(result.StatusCode)+expectedText(result.ExpectedStatus),
 			result.Duration.Round(time.Millisecond))
 
 		// Only show error message if there is one
@@ -264,9 +538,29 @@ func (r *Reporter) generateHTMLReport(report Report) error {
                 </div>`, result.Error)
 		}
 
+		if len(result.Diffs) > 0 {
+			htmlContent += renderDiffs(result.Diffs)
+		}
+
+		if r.config.Detailed && result.Timings != nil {
+			htmlContent += fmt.Sprintf(`
+                <div>Timings: %s</div>`, formatTimings(result.Timings))
+		}
+
+		if r.config.Detailed && len(result.ResponseHeaders) > 0 {
+			htmlContent += fmt.Sprintf(`
+                <div class="test-details">
+                    <strong>Response Headers:</strong>
+                    <pre>%s</pre>
+                </div>`, html.EscapeString(formatHeaders(result.ResponseHeaders)))
+		}
+
 		if r.config.Detailed {
 			requestBody, _ := json.MarshalIndent(result.RequestBody, "", "  ")
-			response, _ := json.MarshalIndent(result.Response, "", "  ")
+			response := []byte(result.RawResponse)
+			if result.Response != nil {
+				response, _ = json.MarshalIndent(result.Response, "", "  ")
+			}
 
 			htmlContent += fmt.Sprintf(`
                 <div class="test-details">
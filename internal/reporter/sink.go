@@ -0,0 +1,134 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ResultSink receives results as tests complete and the finished report at
+// the end of the run
+type ResultSink interface {
+	Write(result TestResult) error
+	Finish(report Report) error
+}
+
+// SinkConfig configures an output sink
+type SinkConfig struct {
+	// Type selects the sink; only "webhook" is supported
+	Type string
+	URL  string
+	// Headers are sent with every webhook request, e.g. an Authorization token
+	Headers map[string]string
+}
+
+// NewSink creates the sink described by config
+func NewSink(config SinkConfig) (ResultSink, error) {
+	switch config.Type {
+	case "webhook":
+		if config.URL == "" {
+			return nil, fmt.Errorf("webhook sink needs a url")
+		}
+		return &webhookSink{
+			url:     config.URL,
+			headers: config.Headers,
+			client:  &http.Client{Timeout: 10 * time.Second},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown sink type %q (want webhook)", config.Type)
+	}
+}
+
+// fileSink writes the configured report formats and metrics file once the
+// run finishes
+type fileSink struct {
+	r *Reporter
+}
+
+// Write does nothing; report files are written in one go by Finish
+func (s fileSink) Write(TestResult) error {
+	return nil
+}
+
+// Finish writes the JSON/HTML/CSV reports and the Prometheus metrics file
+func (s fileSink) Finish(report Report) error {
+	for _, format := range s.r.config.Format {
+		switch format {
+		case "json":
+			if err := s.r.generateJSONReport(report); err != nil {
+				return fmt.Errorf("failed to generate JSON report: %v", err)
+			}
+		case "html":
+			if err := s.r.generateHTMLReport(report); err != nil {
+				return fmt.Errorf("failed to generate HTML report: %v", err)
+			}
+		case "csv":
+			if err := s.r.generateCSVReport(report); err != nil {
+				return fmt.Errorf("failed to generate CSV report: %v", err)
+			}
+		default:
+			return fmt.Errorf("unknown report format %q", format)
+		}
+	}
+
+	if s.r.config.MetricsFile != "" {
+		if err := s.r.writePrometheusMetrics(report); err != nil {
+			return fmt.Errorf("failed to write metrics file: %v", err)
+		}
+	}
+	return nil
+}
+
+// webhookEvent is the body POSTed by the webhook sink: a "result" event per
+// completed test and a "finish" event with the full report
+type webhookEvent struct {
+	Event  string      `json:"event"`
+	Result *TestResult `json:"result,omitempty"`
+	Report *Report     `json:"report,omitempty"`
+}
+
+// webhookSink POSTs each result and the final report as JSON to a URL
+type webhookSink struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// Write posts a single result
+func (s *webhookSink) Write(result TestResult) error {
+	return s.post(webhookEvent{Event: "result", Result: &result})
+}
+
+// Finish posts the finished report
+func (s *webhookSink) Finish(report Report) error {
+	return s.post(webhookEvent{Event: "finish", Report: &report})
+}
+
+func (s *webhookSink) post(event webhookEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range s.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook %s: %v", s.url, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s returned %s", s.url, resp.Status)
+	}
+	return nil
+}
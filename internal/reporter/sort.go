@@ -0,0 +1,52 @@
+package reporter
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Supported result orders
+const (
+	SortFailedFirst  = "failed-first"
+	SortSlowestFirst = "slowest-first"
+	SortAlphabetical = "alphabetical"
+)
+
+// sortResults returns a copy of the results in the requested order. Failed-first
+// puts failures on top and orders each group slowest first.
+func sortResults(results []TestResult, order string) ([]TestResult, error) {
+	sorted := make([]TestResult, len(results))
+	copy(sorted, results)
+
+	var less func(a, b TestResult) bool
+	switch order {
+	case "", SortFailedFirst:
+		less = func(a, b TestResult) bool {
+			if isPassed(a) != isPassed(b) {
+				return !isPassed(a)
+			}
+			return a.Duration > b.Duration
+		}
+	case SortSlowestFirst:
+		less = func(a, b TestResult) bool {
+			return a.Duration > b.Duration
+		}
+	case SortAlphabetical:
+		less = func(a, b TestResult) bool {
+			if a.Endpoint != b.Endpoint {
+				return a.Endpoint < b.Endpoint
+			}
+			if a.Method != b.Method {
+				return a.Method < b.Method
+			}
+			return a.Case < b.Case
+		}
+	default:
+		return nil, fmt.Errorf("unknown sort order %q (expected %s, %s or %s)", order, SortFailedFirst, SortSlowestFirst, SortAlphabetical)
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return less(sorted[i], sorted[j])
+	})
+	return sorted, nil
+}
@@ -0,0 +1,53 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// templateFuncs are available to custom HTML report templates
+var templateFuncs = template.FuncMap{
+	// json renders a value as indented JSON, e.g. {{json .Response}}
+	"json": func(value interface{}) string {
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return string(data)
+	},
+	// ms rounds a duration to milliseconds for display
+	"ms": func(d time.Duration) time.Duration {
+		return d.Round(time.Millisecond)
+	},
+	"passed":      isPassed,
+	"serverError": isServerError,
+}
+
+// renderTemplate renders the report with a user-supplied html/template file,
+// which receives the Report as its data
+func renderTemplate(path string, report Report) ([]byte, error) {
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).ParseFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse report template: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, report); err != nil {
+		return nil, fmt.Errorf("failed to render report template %s: %v", path, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeTemplateReport writes the HTML report rendered from the configured template
+func (r *Reporter) writeTemplateReport(reportPath string, report Report) error {
+	content, err := renderTemplate(r.config.Template, report)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(reportPath, content, 0644)
+}
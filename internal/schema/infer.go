@@ -0,0 +1,184 @@
+package schema
+
+import (
+	"math"
+	"sort"
+)
+
+// Infer returns a JSON Schema describing a decoded JSON value. Objects list
+// the keys present as required; array items are merged into one schema, so a
+// key is only required when every item has it.
+func Infer(value interface{}) map[string]interface{} {
+	switch v := value.(type) {
+	case nil:
+		return map[string]interface{}{"type": "null"}
+	case bool:
+		return map[string]interface{}{"type": "boolean"}
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return map[string]interface{}{"type": "integer"}
+		}
+		return map[string]interface{}{"type": "number"}
+	case string:
+		return map[string]interface{}{"type": "string"}
+	case []interface{}:
+		result := map[string]interface{}{"type": "array"}
+		var items map[string]interface{}
+		for _, item := range v {
+			items = merge(items, Infer(item))
+		}
+		if items != nil {
+			result["items"] = items
+		}
+		return result
+	case map[string]interface{}:
+		properties := make(map[string]interface{}, len(v))
+		required := make([]string, 0, len(v))
+		for key, child := range v {
+			properties[key] = Infer(child)
+			required = append(required, key)
+		}
+		sort.Strings(required)
+		result := map[string]interface{}{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			result["required"] = required
+		}
+		return result
+	}
+	return map[string]interface{}{}
+}
+
+// Document wraps an inferred schema as a standalone JSON Schema document
+func Document(value interface{}) map[string]interface{} {
+	doc := Infer(value)
+	doc["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	return doc
+}
+
+// merge combines two inferred schemas into one that accepts both
+func merge(a, b map[string]interface{}) map[string]interface{} {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+
+	typeA, okA := a["type"].(string)
+	typeB, okB := b["type"].(string)
+	switch {
+	case okA && okB && typeA == typeB:
+		switch typeA {
+		case "object":
+			return mergeObjects(a, b)
+		case "array":
+			return mergeArrays(a, b)
+		}
+		return a
+	case okA && okB && isNumeric(typeA) && isNumeric(typeB):
+		return map[string]interface{}{"type": "number"}
+	case okA && okB && (typeA == "null" || typeB == "null"):
+		// A nullable value keeps the structure of its non-null schema
+		nullable := a
+		if typeA == "null" {
+			nullable = b
+		}
+		result := make(map[string]interface{}, len(nullable))
+		for key, value := range nullable {
+			result[key] = value
+		}
+		result["type"] = unionTypes(typeA, typeB)
+		return result
+	}
+
+	types := unionTypes(a["type"], b["type"])
+	return map[string]interface{}{"type": types}
+}
+
+func mergeObjects(a, b map[string]interface{}) map[string]interface{} {
+	propsA, _ := a["properties"].(map[string]interface{})
+	propsB, _ := b["properties"].(map[string]interface{})
+	properties := make(map[string]interface{}, len(propsA)+len(propsB))
+	for key, prop := range propsA {
+		properties[key] = prop
+	}
+	for key, prop := range propsB {
+		if existing, ok := properties[key].(map[string]interface{}); ok {
+			properties[key] = merge(existing, prop.(map[string]interface{}))
+		} else {
+			properties[key] = prop
+		}
+	}
+
+	// Only keys present in both stay required
+	inB := make(map[string]bool)
+	if required, ok := b["required"].([]string); ok {
+		for _, key := range required {
+			inB[key] = true
+		}
+	}
+	var required []string
+	if requiredA, ok := a["required"].([]string); ok {
+		for _, key := range requiredA {
+			if inB[key] {
+				required = append(required, key)
+			}
+		}
+	}
+
+	result := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		result["required"] = required
+	}
+	return result
+}
+
+func mergeArrays(a, b map[string]interface{}) map[string]interface{} {
+	itemsA, _ := a["items"].(map[string]interface{})
+	itemsB, _ := b["items"].(map[string]interface{})
+	result := map[string]interface{}{"type": "array"}
+	if items := merge(itemsA, itemsB); items != nil {
+		result["items"] = items
+	} else if itemsB != nil {
+		result["items"] = itemsB
+	}
+	return result
+}
+
+func isNumeric(t string) bool {
+	return t == "integer" || t == "number"
+}
+
+// unionTypes lists the distinct types of two schema type values, dropping
+// structure that can no longer be described as one object or array schema
+func unionTypes(a, b interface{}) []string {
+	seen := make(map[string]bool)
+	var types []string
+	for _, value := range []interface{}{a, b} {
+		var list []string
+		switch v := value.(type) {
+		case string:
+			list = []string{v}
+		case []string:
+			list = v
+		}
+		for _, t := range list {
+			if !seen[t] {
+				seen[t] = true
+				types = append(types, t)
+			}
+		}
+	}
+	if seen["integer"] && seen["number"] {
+		delete(seen, "integer")
+		filtered := types[:0]
+		for _, t := range types {
+			if t != "integer" {
+				filtered = append(filtered, t)
+			}
+		}
+		types = filtered
+	}
+	sort.Strings(types)
+	return types
+}
@@ -14,39 +14,88 @@ import (
 
 // TestDataTemplate represents the structure of our test data file
 type TestDataTemplate struct {
-	Endpoints map[string]EndpointTestData `json:"endpoints"`
+	// BaseURL is the origin relative endpoint paths are sent to by default
+	BaseURL   string                   `json:"base_url,omitempty"`
+	Endpoints map[string]templateCases `json:"endpoints"`
 }
 
 // EndpointTestData represents test data for a specific endpoint and method
 type EndpointTestData struct {
+	Name        string                 `json:"name,omitempty"`
 	PathParams  map[string]interface{} `json:"path_params,omitempty"`
 	QueryParams map[string]interface{} `json:"query_params,omitempty"`
 	Body        interface{}            `json:"body,omitempty"`
 	Headers     map[string]string      `json:"headers,omitempty"`
+	Weight      float64                `json:"weight,omitempty"`
+
+	// ExpectedStatus is set on negative cases, which pass when the server
+	// rejects them
+	ExpectedStatus []int `json:"expected_status,omitempty"`
+
+	// ResponseHint summarizes the documented response bodies to help write
+	// assertions; it is ignored when the test data is loaded
+	ResponseHint map[string]interface{} `json:"_response_hint,omitempty"`
+}
+
+// GeneratorOptions controls how template values are generated
+type GeneratorOptions struct {
+	// ArrayItems bounds the number of items generated for array bodies.
+	// Template values use the minimum, raised to the schema's minItems.
+	ArrayItems types.ArrayBounds
+
+	// GroupByTag writes testdata_template.json5 with the entries nested under
+	// their first tag and each operation's summary as a comment
+	GroupByTag bool
+
+	// ResponseHints adds a _response_hint field to each entry with a compact
+	// summary of its response schemas per status code
+	ResponseHints bool
+
+	// LinkResources gives every endpoint of a resource the same identifier,
+	// e.g. the id of GET /users/{id} matches DELETE /users/{id} and the
+	// userId field of POST /orders
+	LinkResources bool
+
+	// Negative adds cases breaking the schema constraints of each endpoint's
+	// body and query parameters, expecting the server to reject them
+	Negative bool
 }
 
 // Generator handles the generation of test data templates
 type Generator struct {
 	outputDir string
+	options   GeneratorOptions
+	// resources is set while generating with LinkResources
+	resources *resourceIDs
 }
 
 // NewGenerator creates a new instance of Generator
-func NewGenerator(outputDir string) *Generator {
+func NewGenerator(outputDir string, options GeneratorOptions) *Generator {
+	options.ArrayItems = options.ArrayItems.WithDefaults(1, 1)
 	return &Generator{
 		outputDir: outputDir,
+		options:   options,
 	}
 }
 
 // GenerateTemplate generates a test data template file based on endpoints
 func (g *Generator) GenerateTemplate(endpoints []types.Endpoint) error {
+	if g.options.LinkResources {
+		g.resources = g.newResourceIDs(endpoints)
+	}
+	if g.options.GroupByTag {
+		return g.generateGroupedTemplate(endpoints)
+	}
+
 	template := TestDataTemplate{
-		Endpoints: make(map[string]EndpointTestData),
+		BaseURL:   templateBaseURL(endpoints),
+		Endpoints: make(map[string]templateCases),
 	}
 
 	// Process each endpoint
 	for _, endpoint := range endpoints {
 		// Generate test data for this endpoint and method
-		testData := g.generateEndpointTestData(endpoint)
+		testData := g.endpointTestCases(endpoint)
 		key := fmt.Sprintf("%s %s", endpoint.Method, endpoint.Path)
 		template.Endpoints[key] = testData
 	}
@@ -68,7 +117,17 @@ func (g *Generator) GenerateTemplate(endpoints []types.Endpoint) error {
 	}
 
 	fmt.Printf("Test data template generated at: %s\n", outputPath)
-	return nil
+	return g.writeParameterDocs(endpoints)
+}
+
+// templateBaseURL returns the base URL the endpoints were parsed with
+func templateBaseURL(endpoints []types.Endpoint) string {
+	for _, endpoint := range endpoints {
+		if endpoint.BaseURL != "" {
+			return endpoint.BaseURL
+		}
+	}
+	return ""
 }
 
 // generateEndpointTestData generates test data for a specific endpoint
@@ -80,6 +139,10 @@ func (g *Generator) generateEndpointTestData(endpoint types.Endpoint) EndpointTe
 			"Content-Type": "application/json",
 			"Accept":       "application/json",
 		},
+		Weight: endpoint.Weight,
+	}
+	if g.options.ResponseHints {
+		testData.ResponseHint = responseHints(endpoint)
 	}
 
 	// Process parameters
@@ -91,6 +154,12 @@ func (g *Generator) generateEndpointTestData(endpoint types.Endpoint) EndpointTe
 			testData.QueryParams[param.Name] = g.generateSampleValue(param)
 		case "body":
 			testData.Body = g.generateBodySchema(param.Schema)
+			if param.ContentType != "" {
+				testData.Headers["Content-Type"] = param.ContentType
+			}
+			if strings.HasPrefix(param.ContentType, "multipart/form-data") {
+				markFileFields(param.Schema, testData.Body)
+			}
 		case "header":
 			if value := g.generateSampleValue(param); value != nil {
 				testData.Headers[param.Name] = fmt.Sprint(value)
@@ -98,12 +167,28 @@ func (g *Generator) generateEndpointTestData(endpoint types.Endpoint) EndpointTe
 		}
 	}
 
+	if g.resources != nil {
+		g.resources.apply(endpoint, &testData)
+	}
+
 	return testData
 }
 
 // generateSampleValue generates a sample value based on parameter type
 func (g *Generator) generateSampleValue(param types.Parameter) interface{} {
+	// The spec's example is more realistic than anything derived from the type
+	if param.Example != nil {
+		return param.Example
+	}
+	// Parameters parsed from a spec carry kin-openapi schemas
+	if _, ok := param.Schema.(*openapi3.SchemaRef); ok {
+		return g.generateBodySchema(param.Schema)
+	}
 	if schema, ok := param.Schema.(map[string]interface{}); ok {
+		// An enum constrains the value regardless of its base type
+		if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+			return enum[0]
+		}
 		if typeStr, ok := schema["type"].(string); ok {
 			switch typeStr {
 			case "string":
@@ -125,9 +210,6 @@ func (g *Generator) generateSampleValue(param types.Parameter) interface{} {
 						return "2001:db8::1"
 					}
 				}
-				if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
-					return enum[0]
-				}
 				if pattern, ok := schema["pattern"].(string); ok {
 					// Generate a simple string that matches common patterns
 					switch {
@@ -185,6 +267,9 @@ func (g *Generator) generateSampleValue(param types.Parameter) interface{} {
 					result := make(map[string]interface{})
 					for key, prop := range properties {
 						if propMap, ok := prop.(map[string]interface{}); ok {
+							if readOnly, _ := propMap["readOnly"].(bool); readOnly {
+								continue
+							}
 							result[key] = g.generateSampleValue(types.Parameter{Schema: propMap})
 						}
 					}
@@ -211,23 +296,37 @@ func (g *Generator) generateBodySchema(schema interface{}) interface{} {
 	if schemaMap, ok := schema.(*openapi3.Schema); ok {
 		// Handle array type
 		if schemaMap.Type != nil && schemaMap.Type.Is("array") {
-			if schemaMap.Items != nil {
-				// Generate a sample array with one item using the items schema
-				itemSchema := g.generateBodySchema(schemaMap.Items)
-				return []interface{}{itemSchema}
+			count := g.arrayLength(schemaMap.MinItems, schemaMap.MaxItems)
+			items := make([]interface{}, 0, count)
+			for i := 0; i < count; i++ {
+				if schemaMap.Items != nil {
+					// Generate each item using the items schema
+					items = append(items, g.generateBodySchema(schemaMap.Items))
+				} else {
+					items = append(items, "sample_item")
+				}
 			}
-			return []interface{}{"sample_item"}
+			return items
 		}
 
 		// Handle object type
 		if schemaMap.Type != nil && schemaMap.Type.Is("object") {
 			result := make(map[string]interface{})
 			for key, prop := range schemaMap.Properties {
+				// Server-assigned fields don't belong in request bodies
+				if prop != nil && prop.Value != nil && prop.Value.ReadOnly {
+					continue
+				}
 				result[key] = g.generateBodySchema(prop)
 			}
 			return result
 		}
 
+		// An enum constrains the value regardless of its base type
+		if len(schemaMap.Enum) > 0 {
+			return schemaMap.Enum[0]
+		}
+
 		// Handle primitive types
 		if schemaMap.Type != nil {
 			switch {
@@ -250,9 +349,6 @@ func (g *Generator) generateBodySchema(schema interface{}) interface{} {
 						return "2001:db8::1"
 					}
 				}
-				if len(schemaMap.Enum) > 0 {
-					return schemaMap.Enum[0]
-				}
 				return "sample_string"
 			case schemaMap.Type.Is("number"):
 				return 123.45
@@ -265,3 +361,39 @@ func (g *Generator) generateBodySchema(schema interface{}) interface{} {
 	}
 	return nil
 }
+
+// arrayLength returns the number of items to generate for an array schema,
+// honoring the schema's minItems/maxItems over the configured size
+func (g *Generator) arrayLength(minItems uint64, maxItems *uint64) int {
+	count := g.options.ArrayItems.Min
+	if count < int(minItems) {
+		count = int(minItems)
+	}
+	if maxItems != nil && count > int(*maxItems) {
+		count = int(*maxItems)
+	}
+	return count
+}
+
+// sampleUpload is the placeholder value of a multipart file field; the
+// executor uploads the file named after @file:
+const sampleUpload = "@file:testdata/upload.bin"
+
+// markFileFields replaces the sample values of a multipart body's binary
+// fields with a file upload placeholder
+func markFileFields(schema interface{}, body interface{}) {
+	fields, ok := body.(map[string]interface{})
+	s := resolveSchema(schema)
+	if !ok || s == nil {
+		return
+	}
+	for name := range fields {
+		prop := resolveSchema(s.Properties[name])
+		if prop == nil {
+			continue
+		}
+		if prop.Format == "binary" || prop.Type != nil && prop.Type.Is("array") && prop.Items != nil && prop.Items.Value != nil && prop.Items.Value.Format == "binary" {
+			fields[name] = sampleUpload
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package generator
+
+import (
+	"math"
+	"strings"
+)
+
+// isDecimalType reports whether a column type stores exact decimals, the only
+// types whose precision and scale count decimal digits
+func isDecimalType(colType string) bool {
+	switch strings.ToLower(colType) {
+	case "numeric", "decimal":
+		return true
+	}
+	return false
+}
+
+// decimalLimit returns the largest magnitude a decimal column with the given
+// precision and scale can hold, e.g. 999.99 for numeric(5,2)
+func decimalLimit(precision, scale int) float64 {
+	return math.Pow(10, float64(precision-scale)) - math.Pow(10, -float64(scale))
+}
+
+// applyConstraints fits a generated value to the column: strings are cut to
+// MaxLength, decimals are rounded to Scale places and kept within Precision
+// digits, and numbers are clamped to MinValue and MaxValue
+func applyConstraints(value interface{}, colType string, col ColumnInfo) interface{} {
+	switch v := value.(type) {
+	case string:
+		if col.MaxLength > 0 {
+			if runes := []rune(v); len(runes) > col.MaxLength {
+				return string(runes[:col.MaxLength])
+			}
+		}
+		return v
+	case int:
+		return int(fitNumber(float64(v), true, colType, col))
+	case int64:
+		return int64(fitNumber(float64(v), true, colType, col))
+	case float64:
+		return fitNumber(v, false, colType, col)
+	}
+	return value
+}
+
+// fitNumber rounds and clamps a number to the column's decimal precision and
+// scale and its CHECK bounds; a whole number stays whole
+func fitNumber(value float64, whole bool, colType string, col ColumnInfo) float64 {
+	low, high := math.Inf(-1), math.Inf(1)
+	if min, ok := col.MinValue.(float64); ok {
+		low = min
+	}
+	if max, ok := col.MaxValue.(float64); ok {
+		high = max
+	}
+
+	if isDecimalType(colType) {
+		if col.Precision > 0 {
+			limit := decimalLimit(col.Precision, col.Scale)
+			low, high = math.Max(low, -limit), math.Min(high, limit)
+		}
+		scale := math.Pow(10, float64(col.Scale))
+		value = math.Round(value*scale) / scale
+		// Move bounds inward onto the scale's grid so clamping keeps the rounding
+		low, high = math.Ceil(low*scale)/scale, math.Floor(high*scale)/scale
+	}
+
+	if whole {
+		low, high = math.Ceil(low), math.Floor(high)
+	}
+	if low <= high {
+		value = math.Max(low, math.Min(high, value))
+	}
+	return value
+}
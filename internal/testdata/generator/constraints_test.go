@@ -0,0 +1,84 @@
+package generator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDecimalLimit(t *testing.T) {
+	tests := []struct {
+		precision, scale int
+		want             float64
+	}{
+		{5, 2, 999.99},
+		{3, 0, 999},
+		{4, 4, 0.9999},
+	}
+
+	for _, tt := range tests {
+		if got := decimalLimit(tt.precision, tt.scale); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("decimalLimit(%d, %d) = %v, want %v", tt.precision, tt.scale, got, tt.want)
+		}
+	}
+}
+
+func TestApplyConstraints(t *testing.T) {
+	varchar10 := ColumnInfo{Name: "code", Type: "varchar", MaxLength: 10}
+	numeric52 := ColumnInfo{Name: "price", Type: "numeric", Precision: 5, Scale: 2}
+	bounded := ColumnInfo{Name: "quantity", Type: "integer", MinValue: float64(1), MaxValue: float64(50)}
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		colType string
+		col     ColumnInfo
+		want    interface{}
+	}{
+		{"varchar truncated", "abcdefghijklmnop", "varchar", varchar10, "abcdefghij"},
+		{"varchar counts runes", "ééééééééééé", "varchar", varchar10, "éééééééééé"},
+		{"varchar fits", "short", "varchar", varchar10, "short"},
+		{"numeric rounded to scale", 12.3456, "numeric", numeric52, 12.35},
+		{"numeric clamped to precision", 12345.678, "numeric", numeric52, 999.99},
+		{"numeric clamped below", -12345.678, "numeric", numeric52, -999.99},
+		{"decimal type matched case-insensitively", 1.005001, "DECIMAL", numeric52, 1.01},
+		{"int clamped to max", 100, "integer", bounded, 50},
+		{"int clamped to min", -3, "integer", bounded, 1},
+		{"int within bounds", 25, "integer", bounded, 25},
+		{"int64 clamped", int64(500), "integer", bounded, int64(50)},
+		{"float not rounded outside decimals", 2.345, "real", ColumnInfo{Name: "ratio"}, 2.345},
+		{"other types untouched", true, "boolean", varchar10, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := applyConstraints(tt.value, tt.colType, tt.col)
+			if f, ok := got.(float64); ok {
+				if want, ok := tt.want.(float64); !ok || math.Abs(f-want) > 1e-9 {
+					t.Errorf("applyConstraints(%v) = %v, want %v", tt.value, got, tt.want)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("applyConstraints(%v) = %v (%T), want %v (%T)", tt.value, got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func TestFitNumberBoundsOnScaleGrid(t *testing.T) {
+	// A CHECK bound between two representable values is moved inward so the
+	// clamped value still has at most Scale decimal places
+	col := ColumnInfo{Name: "rate", Type: "numeric", Precision: 5, Scale: 2, MaxValue: 10.555}
+	if got := fitNumber(50, false, "numeric", col); math.Abs(got-10.55) > 1e-9 {
+		t.Errorf("fitNumber(50) = %v, want 10.55", got)
+	}
+
+	// Whole numbers stay whole under fractional bounds
+	col = ColumnInfo{Name: "count", Type: "integer", MinValue: 0.5, MaxValue: 9.5}
+	if got := fitNumber(0, true, "integer", col); got != 1 {
+		t.Errorf("fitNumber(0) = %v, want 1", got)
+	}
+	if got := fitNumber(20, true, "integer", col); got != 9 {
+		t.Errorf("fitNumber(20) = %v, want 9", got)
+	}
+}
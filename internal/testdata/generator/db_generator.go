@@ -3,11 +3,9 @@ package generator
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"fmt"
-	"math/rand"
-	"os"
-	"path/filepath"
+	"math"
+	"slices"
 	"strings"
 	"time"
 
@@ -17,9 +15,8 @@ import (
 
 	"auto-api-tester/internal/llm"
 	"auto-api-tester/internal/logger"
+	"auto-api-tester/internal/testdata"
 	"auto-api-tester/internal/types"
-
-	"github.com/google/uuid"
 )
 
 // DBConfig holds database connection configuration
@@ -30,32 +27,122 @@ type DBConfig struct {
 	Database string
 	User     string
 	Password string
+
+	// Schema limits table lookups to one schema. It defaults to public for
+	// postgres and the database name for mysql; otherwise empty searches all.
+	Schema string
+
+	// SSLMode is one of SSLModes and defaults to disable; SSLRootCert is the
+	// CA certificate the server's certificate is verified against
+	SSLMode     string
+	SSLRootCert string
+
+	// ConnectTimeout bounds how long connecting may take, so an unreachable
+	// host fails fast instead of stalling the run
+	ConnectTimeout time.Duration
+
+	// MaxOpenConns, MaxIdleConns and ConnMaxLifetime size the connection pool
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// Connection defaults used when DBConfig leaves the fields zero
+const (
+	DefaultConnectTimeout  = 10 * time.Second
+	DefaultMaxOpenConns    = 10
+	DefaultMaxIdleConns    = 5
+	DefaultConnMaxLifetime = 30 * time.Minute
+)
+
+// withConnectionDefaults fills the zero connection fields of config
+func withConnectionDefaults(config DBConfig) DBConfig {
+	if config.ConnectTimeout <= 0 {
+		config.ConnectTimeout = DefaultConnectTimeout
+	}
+	if config.MaxOpenConns <= 0 {
+		config.MaxOpenConns = DefaultMaxOpenConns
+	}
+	if config.MaxIdleConns <= 0 {
+		config.MaxIdleConns = DefaultMaxIdleConns
+	}
+	if config.MaxIdleConns > config.MaxOpenConns {
+		config.MaxIdleConns = config.MaxOpenConns
+	}
+	if config.ConnMaxLifetime <= 0 {
+		config.ConnMaxLifetime = DefaultConnMaxLifetime
+	}
+	if config.SSLMode == "" {
+		config.SSLMode = SSLDisable
+	}
+	return config
+}
+
+// defaultSchema returns the schema searched when DBConfig.Schema is empty
+func defaultSchema(config DBConfig) string {
+	switch config.Type {
+	case "postgres":
+		return "public"
+	case "mysql":
+		return config.Database
+	}
+	return ""
+}
+
+// Options controls how the DB generator shapes generated data
+type Options struct {
+	// ArrayItems bounds the number of items generated for array bodies,
+	// unless an endpoint sets its own array_items
+	ArrayItems types.ArrayBounds
+
+	// Seed makes generated values reproducible; zero picks a time-based seed,
+	// which is printed so the run can be replayed
+	Seed int64
+
+	// FieldRules map column name patterns to generators, checked before the
+	// built-in name patterns
+	FieldRules []types.FieldRule
+
+	// Provider produces values for columns recognised by name, such as email
+	// or company; nil keeps the synthetic values
+	Provider ValueProvider
 }
 
 // DBGenerator handles test data generation from database
 type DBGenerator struct {
 	config       DBConfig
+	options      Options
 	db           *sql.DB
 	templatePath string
 	outputPath   string
 	analyzer     *TableAnalyzer
 	llmClient    llm.LLMClient
+	rand         *lockedRand
 }
 
 // NewDBGenerator creates a new instance of DBGenerator
-func NewDBGenerator(dbConfig DBConfig, llmConfig llm.Config, templatePath, outputPath string) *DBGenerator {
-	// Initialize random number generator
-	rand.Seed(time.Now().UnixNano())
-
+func NewDBGenerator(dbConfig DBConfig, llmConfig llm.Config, options Options, templatePath, outputPath string) *DBGenerator {
 	logger, _ := logger.NewLogger("db_generator")
 
 	llmClient, _ := llm.NewClient(&llmConfig, logger)
 
+	options.ArrayItems = options.ArrayItems.WithDefaults(1, 3)
+	if options.Seed == 0 {
+		options.Seed = time.Now().UnixNano()
+		fmt.Printf("Using random seed %d (pass -seed %d to reproduce this data)\n", options.Seed, options.Seed)
+	}
+	if dbConfig.Schema == "" {
+		dbConfig.Schema = defaultSchema(dbConfig)
+	}
+	dbConfig = withConnectionDefaults(dbConfig)
+
 	return &DBGenerator{
 		config:       dbConfig,
+		options:      options,
 		templatePath: templatePath,
 		outputPath:   outputPath,
 		llmClient:    llmClient,
+		rand:         newLockedRand(options.Seed),
 	}
 }
 
@@ -68,7 +155,7 @@ func (g *DBGenerator) GenerateTestData() error {
 	defer g.db.Close()
 
 	// 2. Initialize table analyzer
-	g.analyzer = NewTableAnalyzer(g.db)
+	g.analyzer = NewTableAnalyzer(g.db, g.config.Type, g.config.Schema)
 
 	// 3. Load template
 	template, err := g.loadTemplate()
@@ -76,39 +163,88 @@ func (g *DBGenerator) GenerateTestData() error {
 		return fmt.Errorf("failed to load template: %v", err)
 	}
 
-	// 4. Generate test data for each endpoint
-	for endpoint, data := range template.Endpoints {
-		// Parse endpoint string (e.g., "GET /api/users")
-		method, path := parseEndpointString(endpoint)
+	// 4. Generate test data for each entry, keyed, grouped or listed under
+	// tests, writing the results back in place
+	entries, err := template.Entries()
+	if err != nil {
+		return fmt.Errorf("failed to load template: %v", err)
+	}
 
-		// Generate test data based on endpoint type and database schema
-		testData, err := g.generateEndpointData(method, path, data)
+	var failures []types.GenerationFailure
+	total := 0
+	for _, entry := range entries {
+		// Negative cases keep the invalid input they were generated with
+		if entry.ExpectsRejection() {
+			continue
+		}
+		total++
+		// Generate test data for the case based on endpoint type and database schema
+		testData, err := g.generateEndpointData(entry.Method, entry.Path, entry.EndpointTestData)
 		if err != nil {
-			fmt.Printf("Warning: Failed to generate test data for %s: %v\n", endpoint, err)
+			fmt.Printf("Warning: Failed to generate test data for %s: %v\n", entry.ID(), err)
+			failures = append(failures, types.GenerationFailure{Endpoint: entry.ID(), Error: err.Error()})
 			continue
 		}
 
 		// Update template with generated data
-		template.Endpoints[endpoint] = testData
+		entry.EndpointTestData = testData
+		template.Update(entry)
+	}
+
+	// Record the entries still needing manual attention, replacing any
+	// failures carried over from a previous run
+	template.Metadata = nil
+	if len(failures) > 0 {
+		template.Metadata = &types.TemplateMetadata{GenerationFailures: failures}
 	}
+	printGenerationSummary(total, failures)
 
 	// 5. Save generated test data
 	return g.saveTestData(template)
 }
 
-// connect establishes database connection
+// printGenerationSummary reports how many test cases were generated and which failed
+func printGenerationSummary(total int, failures []types.GenerationFailure) {
+	fmt.Printf("Generated %d/%d endpoints; %d failed\n", total-len(failures), total, len(failures))
+	for _, failure := range failures {
+		fmt.Printf("  %s: %s\n", failure.Endpoint, failure.Error)
+	}
+}
+
+// connect establishes database connection, giving up after ConnectTimeout
 func (g *DBGenerator) connect() error {
+	if g.config.Type != "sqlite" {
+		if err := validateSSL(g.config); err != nil {
+			return err
+		}
+	}
+
 	var dsn string
+	// Drivers also get the timeout for dialing, which some of them don't
+	// abandon when the ping's context expires
+	timeoutSeconds := int(math.Ceil(g.config.ConnectTimeout.Seconds()))
 	switch g.config.Type {
 	case "postgres":
-		dsn = fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-			g.config.Host, g.config.Port, g.config.User, g.config.Password, g.config.Database)
+		dsn = fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s connect_timeout=%d",
+			g.config.Host, g.config.Port, g.config.User, g.config.Password, g.config.Database, timeoutSeconds) +
+			postgresSSLParams(g.config)
 	case "mysql":
-		dsn = fmt.Sprintf("%s:%s@tcp(%s:%d)/%s",
-			g.config.User, g.config.Password, g.config.Host, g.config.Port, g.config.Database)
+		tlsParam, err := mysqlTLSParam(g.config)
+		if err != nil {
+			return err
+		}
+		dsn = fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?timeout=%s&tls=%s",
+			g.config.User, g.config.Password, g.config.Host, g.config.Port, g.config.Database, g.config.ConnectTimeout, tlsParam)
 	case "sqlserver":
-		dsn = fmt.Sprintf("server=%s;port=%d;user id=%s;password=%s;database=%s",
-			g.config.Host, g.config.Port, g.config.User, g.config.Password, g.config.Database)
+		dsn = fmt.Sprintf("server=%s;port=%d;user id=%s;password=%s;database=%s;dial timeout=%d",
+			g.config.Host, g.config.Port, g.config.User, g.config.Password, g.config.Database, timeoutSeconds) +
+			sqlServerSSLParams(g.config)
+	case "sqlite":
+		// Database is the path of the SQLite file
+		if !slices.Contains(sql.Drivers(), "sqlite") {
+			return fmt.Errorf("sqlite support is not built in; rebuild with -tags sqlite")
+		}
+		dsn = g.config.Database
 	default:
 		return fmt.Errorf("unsupported database type: %s", g.config.Type)
 	}
@@ -117,51 +253,38 @@ func (g *DBGenerator) connect() error {
 	if err != nil {
 		return err
 	}
+	db.SetMaxOpenConns(g.config.MaxOpenConns)
+	db.SetMaxIdleConns(g.config.MaxIdleConns)
+	db.SetConnMaxLifetime(g.config.ConnMaxLifetime)
 
 	// Test connection
-	if err := db.Ping(); err != nil {
-		return err
+	ctx, cancel := context.WithTimeout(context.Background(), g.config.ConnectTimeout)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		target := fmt.Sprintf("%s:%d", g.config.Host, g.config.Port)
+		if g.config.Type == "sqlite" {
+			target = g.config.Database
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("%s database at %s did not respond within %s", g.config.Type, target, g.config.ConnectTimeout)
+		}
+		return fmt.Errorf("%s database at %s: %v", g.config.Type, target, err)
 	}
 
 	g.db = db
 	return nil
 }
 
-// loadTemplate loads the test data template
-func (g *DBGenerator) loadTemplate() (*types.TestDataTemplate, error) {
-	data, err := os.ReadFile(g.templatePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read template file: %v", err)
-	}
-
-	var template types.TestDataTemplate
-	if err := json.Unmarshal(data, &template); err != nil {
-		return nil, fmt.Errorf("failed to parse template file: %v", err)
-	}
-
-	return &template, nil
+// loadTemplate loads the test data template, which may be the JSON5 of a
+// grouped template
+func (g *DBGenerator) loadTemplate() (*testdata.TestData, error) {
+	return testdata.LoadFile(g.templatePath)
 }
 
 // saveTestData saves the generated test data
-func (g *DBGenerator) saveTestData(template *types.TestDataTemplate) error {
-	// Create output directory if it doesn't exist
-	outputDir := filepath.Dir(g.outputPath)
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %v", err)
-	}
-
-	// Marshal template to JSON
-	data, err := json.MarshalIndent(template, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal test data: %v", err)
-	}
-
-	// Write to file
-	if err := os.WriteFile(g.outputPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write test data file: %v", err)
-	}
-
-	return nil
+func (g *DBGenerator) saveTestData(template *testdata.TestData) error {
+	return testdata.SaveFile(g.outputPath, template)
 }
 
 // parseEndpointString parses an endpoint string into method and path
@@ -218,70 +341,62 @@ func (g *DBGenerator) analyzeEndpointTables(method, path string) ([]string, erro
 	// Get the last part of the path as the potential table name
 	tableName := strings.ToLower(parts[len(parts)-1])
 
-	// Query to get the actual table name from database
-	checkQuery := `
-		SELECT table_name 
-		FROM information_schema.tables 
-		WHERE LOWER(table_name) = LOWER($1)
-		LIMIT 1
-	`
-	var actualTableName string
-	err := g.db.QueryRow(checkQuery, tableName).Scan(&actualTableName)
+	// Look up the actual table name in the database
+	actualTableName, err := g.analyzer.FindTable(tableName)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			// Table not found, use LLM to suggest alternatives
-			if g.llmClient == nil {
-				return nil, fmt.Errorf("table '%s' not found and LLM client is not available", tableName)
-			}
+		return nil, fmt.Errorf("failed to query table: %v", err)
+	}
+	if actualTableName == "" {
+		// Table not found, use LLM to suggest alternatives
+		if g.llmClient == nil {
+			return nil, fmt.Errorf("table '%s' not found and LLM client is not available", tableName)
+		}
 
-			fmt.Printf("Table '%s' not found. Using LLM to suggest alternatives...\n", tableName)
+		fmt.Printf("Table '%s' not found. Using LLM to suggest alternatives...\n", tableName)
 
-			// Get schema information for LLM analysis
-			schemaInfo := g.getSchemaInfo()
+		// Get schema information for LLM analysis
+		schemaInfo := g.getSchemaInfo()
 
-			// Use LLM to analyze relationships and suggest similar tables
-			analysis, err := g.llmClient.AnalyzeRelationships(context.Background(), tableName, schemaInfo)
-			if err != nil {
-				return nil, fmt.Errorf("failed to analyze relationships with LLM: %v", err)
-			}
+		// Use LLM to analyze relationships and suggest similar tables
+		analysis, err := g.llmClient.AnalyzeRelationships(context.Background(), tableName, schemaInfo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze relationships with LLM: %v", err)
+		}
 
-			// Present suggestions to user with more details
-			fmt.Printf("\nSuggested tables for endpoint %s %s:\n", method, path)
+		// Present suggestions to user with more details
+		fmt.Printf("\nSuggested tables for endpoint %s %s:\n", method, path)
 
-			// Display similar tables
-			fmt.Println("\nSimilar tables found:")
-			for i, similar := range analysis.SimilarTables {
-				fmt.Printf("%d. %s and %s\n", i+1, similar.Table1, similar.Table2)
-				fmt.Printf("   Reason: %s\n", similar.Reasoning)
-			}
+		// Display similar tables
+		fmt.Println("\nSimilar tables found:")
+		for i, similar := range analysis.SimilarTables {
+			fmt.Printf("%d. %s and %s\n", i+1, similar.Table1, similar.Table2)
+			fmt.Printf("   Reason: %s\n", similar.Reasoning)
+		}
 
-			// Display foreign key relationships
-			fmt.Println("\nForeign key relationships:")
-			for i, fk := range analysis.ForeignKeysAndDependencies {
-				fmt.Printf("%d. %s.%s -> %s.%s\n", i+1,
-					fk.Table, fk.ForeignKey,
-					fk.References.Table, fk.References.Column)
-			}
+		// Display foreign key relationships
+		fmt.Println("\nForeign key relationships:")
+		for i, fk := range analysis.ForeignKeysAndDependencies {
+			fmt.Printf("%d. %s.%s -> %s.%s\n", i+1,
+				fk.Table, fk.ForeignKey,
+				fk.References.Table, fk.References.Column)
+		}
 
-			fmt.Printf("\n0. Enter custom table name\n")
+		fmt.Printf("\n0. Enter custom table name\n")
 
-			// Get user input
-			var choice int
-			fmt.Print("\nSelect a table (enter number): ")
-			fmt.Scanln(&choice)
+		// Get user input
+		var choice int
+		fmt.Print("\nSelect a table (enter number): ")
+		fmt.Scanln(&choice)
 
-			if choice == 0 {
-				// Get custom table name
-				fmt.Print("Enter custom table name: ")
-				fmt.Scanln(&tableName)
-			} else if choice > 0 && choice <= len(analysis.SimilarTables) {
-				// Use the first table from the selected similar tables pair
-				tableName = analysis.SimilarTables[choice-1].Table1
-			} else {
-				return nil, fmt.Errorf("invalid selection")
-			}
+		if choice == 0 {
+			// Get custom table name
+			fmt.Print("Enter custom table name: ")
+			fmt.Scanln(&tableName)
+		} else if choice > 0 && choice <= len(analysis.SimilarTables) {
+			// Use the first table from the selected similar tables pair
+			tableName = analysis.SimilarTables[choice-1].Table1
 		} else {
-			return nil, fmt.Errorf("failed to query table: %v", err)
+			return nil, fmt.Errorf("invalid selection")
 		}
 	}
 	// Find related tables
@@ -300,56 +415,33 @@ func (g *DBGenerator) analyzeEndpointTables(method, path string) ([]string, erro
 func (g *DBGenerator) getSchemaInfo() map[string]interface{} {
 	schemaInfo := make(map[string]interface{})
 
-	// Query to get tables with a limit to reduce token usage
-	rows, err := g.db.Query(`
-		SELECT table_name 
-		FROM information_schema.tables 
-		WHERE table_schema = 'public'
-		LIMIT 10  -- Limit to most relevant tables
-	`)
+	// Limit the tables to reduce token usage
+	tableNames, err := g.analyzer.catalog.tableNames()
 	if err != nil {
 		return schemaInfo
 	}
-	defer rows.Close()
+	if len(tableNames) > 10 {
+		tableNames = tableNames[:10]
+	}
 
 	// Get table information
-	for rows.Next() {
-		var tableName string
-		if err := rows.Scan(&tableName); err != nil {
-			continue
-		}
-
-		// Get only essential columns for each table
-		colRows, err := g.db.Query(`
-			SELECT column_name, data_type
-			FROM information_schema.columns
-			WHERE table_name = $1
-			AND column_name IN (
-				SELECT column_name 
-				FROM information_schema.key_column_usage 
-				WHERE table_name = $1
-				UNION
-				SELECT column_name 
-				FROM information_schema.constraint_column_usage 
-				WHERE table_name = $1
-			)
-		`, tableName)
+	for _, tableName := range tableNames {
+		tableColumns, err := g.analyzer.catalog.columns(tableName)
 		if err != nil {
 			continue
 		}
 
+		// Keep only the key columns of each table
 		columns := make([]map[string]string, 0)
-		for colRows.Next() {
-			var colName, dataType string
-			if err := colRows.Scan(&colName, &dataType); err != nil {
+		for _, col := range tableColumns {
+			if !col.IsPrimary && !col.IsForeign && !col.IsUnique {
 				continue
 			}
 			columns = append(columns, map[string]string{
-				"name": colName,
-				"type": dataType,
+				"name": col.Name,
+				"type": col.Type,
 			})
 		}
-		colRows.Close()
 
 		if len(columns) > 0 {
 			schemaInfo[tableName] = columns
@@ -372,12 +464,21 @@ func (g *DBGenerator) getSampleRecord(tableName string) (map[string]interface{},
 	columns := make([]string, len(tableInfo.Columns))
 	for i, col := range tableInfo.Columns {
 		// Quote column names to handle case sensitivity
-		columns[i] = fmt.Sprintf(`"%s"`, col.Name)
+		columns[i] = quoteName(g.config.Type, col.Name)
 	}
 	fmt.Println("table name in getSampleRecord", tableName)
+	// Pick the row from the seeded source rather than the database's RANDOM()
+	orderColumns := tableInfo.PrimaryKey
+	if len(orderColumns) == 0 && len(tableInfo.Columns) > 0 {
+		orderColumns = []string{tableInfo.Columns[0].Name}
+	}
+	pick, err := g.randomRowClause(tableName, orderColumns)
+	if err != nil {
+		return nil, err
+	}
 	// Quote the table name to handle case sensitivity
-	query := fmt.Sprintf(`SELECT %s FROM "%s" ORDER BY RANDOM() LIMIT 1`,
-		strings.Join(columns, ", "), tableName)
+	query := fmt.Sprintf(`SELECT %s FROM %s %s`,
+		strings.Join(columns, ", "), quoteName(g.config.Type, tableName), pick)
 
 	// Execute query
 	rows, err := g.db.Query(query)
@@ -412,7 +513,7 @@ func (g *DBGenerator) getSampleRecord(tableName string) (map[string]interface{},
 	for i, col := range columnNames {
 		val := values[i]
 		if val != nil {
-			record[col] = val
+			record[col] = scannedValue(val)
 		}
 	}
 
@@ -481,26 +582,82 @@ func (g *DBGenerator) generatePostData(path string, data types.EndpointTestData,
 			return data, fmt.Errorf("failed to analyze sample record: %v", err)
 		}
 
-		// Generate request body based on the analysis, sample record, and endpoint template
-		// generatedBody, err := g.generateBodyFromTemplate(data.Body, sampleRecord, analysis)
-		// if err != nil {
-		// 	return data, err
-		// }
-		data.Body = analysis
+		tableInfo, err := g.analyzer.analyzeTable(tables[0])
+		if err != nil {
+			return data, fmt.Errorf("failed to analyze table %s: %v", tables[0], err)
+		}
+
+		// The LLM answers with one object; bulk endpoints repeat it as the
+		// template of each item
+		var template interface{} = analysis
+		if _, isArray := data.Body.([]interface{}); isArray {
+			if _, isArray := analysis.([]interface{}); !isArray {
+				template = []interface{}{analysis}
+			}
+		}
+
+		// Generate request body based on the generated object, keeping unique
+		// columns distinct across the items of bulk bodies
+		body, err := g.generateBodyFromTemplate(template, sampleRecord, analysis, newUniqueTracker(tableInfo), g.arrayBounds(data))
+		if err != nil {
+			return data, err
+		}
+		body, err = g.enforceUniqueItems(tables[0], body)
+		if err != nil {
+			return data, fmt.Errorf("failed to enforce unique constraints: %v", err)
+		}
+		if body != nil {
+			data.Body = body
+		}
+		return data, nil
+	}
+
+	// Without an LLM, build the body from the table and its related tables
+	if _, isArray := data.Body.([]interface{}); !isArray {
+		body, err := g.generateBodyFromDB(tables)
+		if err != nil {
+			return data, err
+		}
+		if generated, ok := body.(map[string]interface{}); ok && len(generated) > 0 {
+			data.Body = generated
+		}
+		return data, nil
+	}
+
+	// Bulk endpoints get a separately generated object per item
+	count := g.arrayLength(g.arrayBounds(data))
+	items := make([]interface{}, 0, count)
+	for i := 0; i < count; i++ {
+		body, err := g.generateBodyFromDB(tables)
+		if err != nil {
+			return data, err
+		}
+		if generated, ok := body.(map[string]interface{}); ok && len(generated) > 0 {
+			items = append(items, generated)
+		}
+	}
+	if len(items) == 0 {
+		return data, nil
 	}
+	body, err := g.enforceUniqueItems(tables[0], items)
+	if err != nil {
+		return data, fmt.Errorf("failed to enforce unique constraints: %v", err)
+	}
+	data.Body = body
 
 	return data, nil
 }
 
 // generateBodyFromTemplate generates a request body based on the template, sample record, and analysis
-func (g *DBGenerator) generateBodyFromTemplate(template interface{}, sampleRecord map[string]interface{}, analysis *llm.AnalysisResult) (interface{}, error) {
+// The tracker, when not nil, keeps unique columns distinct across all generated items.
+func (g *DBGenerator) generateBodyFromTemplate(template interface{}, sampleRecord map[string]interface{}, analysis interface{}, tracker *uniqueTracker, bounds types.ArrayBounds) (interface{}, error) {
 	switch t := template.(type) {
 	case map[string]interface{}:
 		// Handle object template
-		return g.generateObjectFromTemplate(t, sampleRecord, analysis)
+		return g.generateObjectFromTemplate(t, sampleRecord, analysis, tracker, bounds)
 	case []interface{}:
 		// Handle array template
-		return g.generateArrayFromTemplate(t, sampleRecord, analysis)
+		return g.generateArrayFromTemplate(t, sampleRecord, analysis, tracker, bounds)
 	default:
 		// If template is nil or not a map/array, use sample record directly
 		return g.generateBodyFromSample(sampleRecord, analysis)
@@ -508,32 +665,39 @@ func (g *DBGenerator) generateBodyFromTemplate(template interface{}, sampleRecor
 }
 
 // generateObjectFromTemplate generates an object based on the template structure
-func (g *DBGenerator) generateObjectFromTemplate(template map[string]interface{}, sampleRecord map[string]interface{}, analysis *llm.AnalysisResult) (map[string]interface{}, error) {
+func (g *DBGenerator) generateObjectFromTemplate(template map[string]interface{}, sampleRecord map[string]interface{}, analysis interface{}, tracker *uniqueTracker, bounds types.ArrayBounds) (map[string]interface{}, error) {
 	result := make(map[string]interface{})
 
-	// Process each field in the template
-	for field, templateValue := range template {
+	// Process each field in the template, in a fixed order so seeded runs
+	// draw the same values for the same fields
+	for _, field := range sortedKeys(template) {
+		templateValue := template[field]
 		// If template has a specific value, use it
 		if templateValue != nil {
 			// Check if the value is a nested object or array
 			switch v := templateValue.(type) {
 			case map[string]interface{}:
 				// Recursively generate nested object
-				nestedObj, err := g.generateObjectFromTemplate(v, sampleRecord, analysis)
+				nestedObj, err := g.generateObjectFromTemplate(v, sampleRecord, analysis, tracker, bounds)
 				if err != nil {
 					return nil, err
 				}
 				result[field] = nestedObj
 			case []interface{}:
 				// Generate array
-				nestedArr, err := g.generateArrayFromTemplate(v, sampleRecord, analysis)
+				nestedArr, err := g.generateArrayFromTemplate(v, sampleRecord, analysis, tracker, bounds)
 				if err != nil {
 					return nil, err
 				}
 				result[field] = nestedArr
 			default:
-				// Use template value directly
-				result[field] = templateValue
+				// Use template value directly, unless an earlier item
+				// already used it for a unique column
+				value, err := g.ensureUnique(tracker, field, templateValue)
+				if err != nil {
+					return nil, err
+				}
+				result[field] = value
 			}
 			continue
 		}
@@ -543,6 +707,10 @@ func (g *DBGenerator) generateObjectFromTemplate(template map[string]interface{}
 		if err != nil {
 			return nil, err
 		}
+		value, err = g.ensureUnique(tracker, field, value)
+		if err != nil {
+			return nil, err
+		}
 		result[field] = value
 	}
 
@@ -550,7 +718,7 @@ func (g *DBGenerator) generateObjectFromTemplate(template map[string]interface{}
 }
 
 // generateArrayFromTemplate generates an array based on the template structure
-func (g *DBGenerator) generateArrayFromTemplate(template []interface{}, sampleRecord map[string]interface{}, analysis *llm.AnalysisResult) ([]interface{}, error) {
+func (g *DBGenerator) generateArrayFromTemplate(template []interface{}, sampleRecord map[string]interface{}, analysis interface{}, tracker *uniqueTracker, bounds types.ArrayBounds) ([]interface{}, error) {
 	if len(template) == 0 {
 		// If template is empty, generate a single item based on sample record
 		item, err := g.generateBodyFromSample(sampleRecord, analysis)
@@ -564,17 +732,17 @@ func (g *DBGenerator) generateArrayFromTemplate(template []interface{}, sampleRe
 	templateItem := template[0]
 	result := make([]interface{}, 0)
 
-	// Generate 1-3 items based on the template structure
-	numItems := rand.Intn(3) + 1
+	// Generate items based on the template structure
+	numItems := g.arrayLength(bounds)
 	for i := 0; i < numItems; i++ {
 		var item interface{}
 		var err error
 
 		switch v := templateItem.(type) {
 		case map[string]interface{}:
-			item, err = g.generateObjectFromTemplate(v, sampleRecord, analysis)
+			item, err = g.generateObjectFromTemplate(v, sampleRecord, analysis, tracker, bounds)
 		case []interface{}:
-			item, err = g.generateArrayFromTemplate(v, sampleRecord, analysis)
+			item, err = g.generateArrayFromTemplate(v, sampleRecord, analysis, tracker, bounds)
 		default:
 			item, err = g.generateValueFromSample("", sampleRecord, analysis)
 		}
@@ -588,6 +756,19 @@ func (g *DBGenerator) generateArrayFromTemplate(template []interface{}, sampleRe
 	return result, nil
 }
 
+// arrayBounds returns the array size bounds for an endpoint
+func (g *DBGenerator) arrayBounds(data types.EndpointTestData) types.ArrayBounds {
+	if data.ArrayItems != nil {
+		return data.ArrayItems.WithDefaults(g.options.ArrayItems.Min, g.options.ArrayItems.Max)
+	}
+	return g.options.ArrayItems
+}
+
+// arrayLength picks how many items to generate for an array within bounds
+func (g *DBGenerator) arrayLength(bounds types.ArrayBounds) int {
+	return bounds.Min + g.rand.Intn(bounds.Max-bounds.Min+1)
+}
+
 // generatePutData generates test data for PUT endpoints
 func (g *DBGenerator) generatePutData(path string, data types.EndpointTestData, tables []string, sampleRecord map[string]interface{}) (types.EndpointTestData, error) {
 	// Similar to POST, but we need to ensure we have an ID
@@ -620,7 +801,7 @@ func (g *DBGenerator) generateValueFromSample(param string, sampleRecord map[str
 }
 
 // generateBodyFromSample generates a request body based on the sample record and analysis
-func (g *DBGenerator) generateBodyFromSample(sampleRecord map[string]interface{}, analysis *llm.AnalysisResult) (interface{}, error) {
+func (g *DBGenerator) generateBodyFromSample(sampleRecord map[string]interface{}, analysis interface{}) (interface{}, error) {
 	// Create a new map for the generated body
 	// generatedBody := make(map[string]interface{})
 
@@ -702,7 +883,7 @@ func (g *DBGenerator) generateValueFromDB(param string, tables []string) (interf
 	case 2:
 		if len(analysis.DataPatterns.ValueRange) > 0 {
 			// Use a random value from the range
-			value = analysis.DataPatterns.ValueRange[rand.Intn(len(analysis.DataPatterns.ValueRange))]
+			value = analysis.DataPatterns.ValueRange[g.rand.Intn(len(analysis.DataPatterns.ValueRange))]
 		} else {
 			value, err = g.generateValueForType(analysis.DataPatterns.DataType, true, param, ColumnInfo{})
 		}
@@ -744,23 +925,24 @@ func (g *DBGenerator) generateBodyFromDB(tables []string) (interface{}, error) {
 
 	// Create a map to hold the generated data
 	data := make(map[string]interface{})
+	tracker := newUniqueTracker(tableInfo)
+
+	entries, err := template.Entries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load template: %v", err)
+	}
 
 	// Get the template fields for this endpoint
 	var templateFields map[string]interface{}
-	for endpoint, endpointData := range template.Endpoints {
-		// Extract the path from the endpoint string (e.g., "POST http://localhost:8080/Customer" -> "Customer")
-		endpointParts := strings.Split(endpoint, " ")
-		if len(endpointParts) < 2 {
-			continue
-		}
-		path := endpointParts[len(endpointParts)-1]
-		pathParts := strings.Split(path, "/")
+	for _, entry := range entries {
+		// Extract the table from the path (e.g., "http://localhost:8080/Customer" -> "Customer")
+		pathParts := strings.Split(entry.Path, "/")
 		endpointTable := strings.ToLower(pathParts[len(pathParts)-1])
 
 		// Compare the endpoint table name with the main table name (both in lowercase)
 		if endpointTable == strings.ToLower(mainTable) {
 			// Handle both array and object body formats
-			switch body := endpointData.Body.(type) {
+			switch body := entry.Body.(type) {
 			case map[string]interface{}:
 				templateFields = body
 			case []interface{}:
@@ -770,7 +952,11 @@ func (g *DBGenerator) generateBodyFromDB(tables []string) (interface{}, error) {
 					}
 				}
 			}
-			break
+			// Entries without a body, such as GETs of the same table, don't
+			// describe the fields
+			if templateFields != nil {
+				break
+			}
 		}
 	}
 
@@ -779,8 +965,23 @@ func (g *DBGenerator) generateBodyFromDB(tables []string) (interface{}, error) {
 		return data, nil
 	}
 
-	// Generate values only for fields present in the template
-	for fieldName, defaultValue := range templateFields {
+	// Pick one referenced row per foreign key up front, so the columns of a
+	// composite key stay consistent with each other
+	fkValues := g.foreignKeyValues(tableInfo, templateFields)
+
+	// Generate values only for fields present in the template, visiting them
+	// in a fixed order, so a seed always draws the same values for each field
+	for _, fieldName := range sortedKeys(templateFields) {
+		defaultValue := templateFields[fieldName]
+		// Nested objects are filled from related tables once the flat fields,
+		// including the foreign keys they follow, are known
+		switch defaultValue.(type) {
+		case map[string]interface{}, []interface{}:
+			if _, ok := findRelatedTable(fieldName, tableInfo, tables[1:]); ok {
+				continue
+			}
+		}
+
 		// Find the column in the table
 		var col *ColumnInfo
 		for _, c := range tableInfo.Columns {
@@ -811,15 +1012,12 @@ func (g *DBGenerator) generateBodyFromDB(tables []string) (interface{}, error) {
 			continue
 		}
 
-		// Handle foreign key relationships
+		// Handle foreign key relationships with the values of the referenced
+		// row picked for the column's key
 		if col.IsForeign {
-			// Get a valid ID from the referenced table
-			refValue, err := g.getValidForeignKeyValue(col.References, col.Name)
-			if err != nil {
-				fmt.Printf("Warning: Failed to get foreign key value for %s: %v\n", col.Name, err)
-				continue
+			if refValue, ok := fkValues[strings.ToLower(col.Name)]; ok {
+				data[col.Name] = refValue
 			}
-			data[col.Name] = refValue
 			continue
 		}
 
@@ -836,73 +1034,92 @@ func (g *DBGenerator) generateBodyFromDB(tables []string) (interface{}, error) {
 			continue
 		}
 
-		// Apply max length constraint for string types
-		if strValue, ok := value.(string); ok && col.MaxLength > 0 {
-			if len(strValue) > col.MaxLength {
-				value = strValue[:col.MaxLength]
-			}
+		value, err = g.ensureUnique(tracker, fieldName, value)
+		if err != nil {
+			fmt.Printf("Warning: Failed to generate unique value for %s: %v\n", col.Name, err)
+			continue
 		}
 
 		// Add to data map
 		data[fieldName] = value
 	}
 
+	g.generateNestedValues(data, templateFields, tableInfo, tables[1:], 0)
+
 	return data, nil
 }
 
-// generateValueForType generates a value based on the column type and constraints
+// generateValueForType generates a value based on the column type and
+// constraints, fitted to the column's length, precision, scale and bounds
 func (g *DBGenerator) generateValueForType(colType string, nullable bool, columnName string, col ColumnInfo) (interface{}, error) {
+	value, err := g.valueForType(colType, nullable, columnName, col)
+	if err != nil || value == nil {
+		return value, err
+	}
+	return applyConstraints(value, colType, col), nil
+}
+
+// valueForType picks a value for a column from its constraints, name and type
+func (g *DBGenerator) valueForType(colType string, nullable bool, columnName string, col ColumnInfo) (interface{}, error) {
 	// Only return nil if the field is explicitly nullable and has a high chance
-	if nullable && rand.Float32() < 0.1 { // Reduced chance of null from 0.2 to 0.1
+	if nullable && g.rand.Float32() < 0.1 { // Reduced chance of null from 0.2 to 0.1
 		return nil, nil
 	}
 
+	// Values the database constrains take precedence over any guess
+	if value, ok := g.constrainedValue(colType, col); ok {
+		return value, nil
+	}
+
 	// Generate value based on column name first (for common patterns)
 	columnName = strings.ToLower(columnName)
+	if value, ok := g.formatValue(columnName); ok {
+		return value, nil
+	}
 	switch {
 	case strings.Contains(columnName, "email"):
-		return fmt.Sprintf("user_%d@example.com", rand.Intn(1000)), nil
+		return g.provide("email"), nil
 	case strings.Contains(columnName, "phone"):
-		return fmt.Sprintf("+1-%d-%d-%d", rand.Intn(900)+100, rand.Intn(900)+100, rand.Intn(9000)+1000), nil
+		return g.provide("phone"), nil
 	case strings.Contains(columnName, "first_name"):
-		return fmt.Sprintf("John%d", rand.Intn(100)), nil
+		return g.provide("first_name"), nil
 	case strings.Contains(columnName, "last_name"):
-		return fmt.Sprintf("Doe%d", rand.Intn(100)), nil
+		return g.provide("last_name"), nil
 	case strings.Contains(columnName, "address"):
-		return fmt.Sprintf("%d Main St", rand.Intn(1000)+1), nil
+		return g.provide("address"), nil
 	case strings.Contains(columnName, "city"):
-		return fmt.Sprintf("City%d", rand.Intn(100)), nil
+		return g.provide("city"), nil
 	case strings.Contains(columnName, "country"):
-		return fmt.Sprintf("Country%d", rand.Intn(100)), nil
+		return g.provide("country"), nil
 	case strings.Contains(columnName, "postal_code"), strings.Contains(columnName, "zip"):
-		return fmt.Sprintf("%d%d", rand.Intn(90000)+10000, rand.Intn(1000)+100), nil
+		return g.provide("postal_code"), nil
 	case strings.Contains(columnName, "date_of_birth"):
 		// Generate a date between 18 and 80 years ago
-		years := rand.Intn(62) + 18
-		return time.Now().AddDate(-years, 0, 0).Format("2006-01-02"), nil
+		years := g.rand.Intn(62) + 18
+		return referenceTime.AddDate(-years, 0, 0).Format("2006-01-02"), nil
 	case strings.Contains(columnName, "username"):
-		return fmt.Sprintf("user_%d", rand.Intn(1000)), nil
+		return g.provide("username"), nil
 	case strings.Contains(columnName, "vat"):
-		return fmt.Sprintf("VAT%d", rand.Intn(1000000)), nil
+		return fmt.Sprintf("VAT%d", g.rand.Intn(1000000)), nil
 	case strings.Contains(columnName, "system_name"):
-		return fmt.Sprintf("system_%d", rand.Intn(1000)), nil
+		return fmt.Sprintf("system_%d", g.rand.Intn(1000)), nil
 	case strings.Contains(columnName, "timezone"):
 		return "UTC", nil
 	case strings.Contains(columnName, "gender"):
 		genders := []string{"M", "F", "O"}
-		return genders[rand.Intn(len(genders))], nil
+		return genders[g.rand.Intn(len(genders))], nil
 	case strings.Contains(columnName, "company"):
-		return fmt.Sprintf("Company%d", rand.Intn(1000)), nil
+		return g.provide("company"), nil
 	case strings.Contains(columnName, "county"):
-		return fmt.Sprintf("County%d", rand.Intn(100)), nil
+		return g.provide("county"), nil
 	case strings.Contains(columnName, "comment"):
-		return fmt.Sprintf("value_%d", rand.Intn(1000)), nil
+		return fmt.Sprintf("value_%d", g.rand.Intn(1000)), nil
 	case strings.Contains(columnName, "guid"):
-		return uuid.New().String(), nil
+		return g.newUUID(), nil
 	case strings.Contains(columnName, "id"):
-		return rand.Intn(1000) + 1, nil
+		return g.rand.Intn(1000) + 1, nil
 	case strings.Contains(columnName, "created") || strings.Contains(columnName, "updated"):
-		return time.Now().Format(time.RFC3339), nil
+		return referenceTime.Format(time.RFC3339), nil
 	case strings.Contains(columnName, "deleted"):
 		return false, nil
 	case strings.Contains(columnName, "active"):
@@ -912,11 +1129,15 @@ func (g *DBGenerator) generateValueForType(colType string, nullable bool, column
 	// If no specific pattern found, generate based on type
 	switch strings.ToLower(colType) {
 	case "integer", "int", "int4", "bigint", "int8":
-		return rand.Intn(1000) + 1, nil
+		return g.rand.Intn(1000) + 1, nil
 	case "numeric", "decimal", "real", "double precision", "float", "float4", "float8":
-		return rand.Float64() * 1000, nil
+		upper := 1000.0
+		if isDecimalType(colType) && col.Precision > 0 {
+			upper = math.Min(upper, decimalLimit(col.Precision, col.Scale))
+		}
+		return g.rand.Float64() * upper, nil
 	case "boolean", "bool":
-		return rand.Float32() < 0.7, nil
+		return g.rand.Float32() < 0.7, nil
 	case "character varying", "varchar", "text", "char", "character":
 		length := col.MaxLength
 		if length == 0 {
@@ -925,63 +1146,132 @@ func (g *DBGenerator) generateValueForType(colType string, nullable bool, column
 		const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 		b := make([]byte, length)
 		for i := range b {
-			b[i] = charset[rand.Intn(len(charset))]
+			b[i] = charset[g.rand.Intn(len(charset))]
 		}
 		return string(b), nil
 	case "timestamp", "timestamp with time zone", "timestamptz", "timestamp without time zone":
-		return time.Now().Add(time.Duration(rand.Intn(1000)) * time.Hour).Format(time.RFC3339), nil
+		return referenceTime.Add(time.Duration(g.rand.Intn(1000)) * time.Hour).Format(time.RFC3339), nil
 	case "date":
-		return time.Now().AddDate(0, 0, rand.Intn(365)).Format("2006-01-02"), nil
+		return referenceTime.AddDate(0, 0, g.rand.Intn(365)).Format("2006-01-02"), nil
 	case "time", "time with time zone", "timetz":
-		return time.Now().Add(time.Duration(rand.Intn(24)) * time.Hour).Format("15:04:05"), nil
+		return referenceTime.Add(time.Duration(g.rand.Intn(24)) * time.Hour).Format("15:04:05"), nil
 	case "uuid":
-		return uuid.New().String(), nil
+		return g.newUUID(), nil
 	case "user-defined":
 		// For user-defined types, try to generate a reasonable value based on the column name
 		if strings.Contains(columnName, "date") || strings.Contains(columnName, "time") {
-			return time.Now().Format(time.RFC3339), nil
+			return referenceTime.Format(time.RFC3339), nil
 		}
 		if strings.Contains(columnName, "name") {
-			return fmt.Sprintf("Name%d", rand.Intn(1000)), nil
+			return fmt.Sprintf("Name%d", g.rand.Intn(1000)), nil
 		}
 		if strings.Contains(columnName, "code") {
-			return fmt.Sprintf("CODE%d", rand.Intn(1000)), nil
+			return fmt.Sprintf("CODE%d", g.rand.Intn(1000)), nil
 		}
 		if strings.Contains(columnName, "id") {
-			return rand.Intn(1000) + 1, nil
+			return g.rand.Intn(1000) + 1, nil
 		}
 		// Default for user-defined types
-		return fmt.Sprintf("value_%d", rand.Intn(1000)), nil
+		return fmt.Sprintf("value_%d", g.rand.Intn(1000)), nil
 	default:
 		// For unknown types, try to generate a reasonable value
 		if strings.Contains(strings.ToLower(colType), "char") || strings.Contains(strings.ToLower(colType), "text") {
-			return fmt.Sprintf("text_%d", rand.Intn(1000)), nil
+			return fmt.Sprintf("text_%d", g.rand.Intn(1000)), nil
 		}
 		if strings.Contains(strings.ToLower(colType), "int") || strings.Contains(strings.ToLower(colType), "number") {
-			return rand.Intn(1000), nil
+			return g.rand.Intn(1000), nil
 		}
 		if strings.Contains(strings.ToLower(colType), "date") || strings.Contains(strings.ToLower(colType), "time") {
-			return time.Now().Format(time.RFC3339), nil
+			return referenceTime.Format(time.RFC3339), nil
+		}
+		return fmt.Sprintf("value_%d", g.rand.Intn(1000)), nil
+	}
+}
+
+// constrainedValue picks one of a column's enum values, or a number within
+// the bounds of its CHECK constraints
+func (g *DBGenerator) constrainedValue(colType string, col ColumnInfo) (interface{}, bool) {
+	if len(col.EnumValues) > 0 {
+		return col.EnumValues[g.rand.Intn(len(col.EnumValues))], true
+	}
+	if col.MinValue == nil && col.MaxValue == nil {
+		return nil, false
+	}
+
+	min, max := 1.0, 1000.0
+	lower, hasLower := col.MinValue.(float64)
+	upper, hasUpper := col.MaxValue.(float64)
+	switch {
+	case hasLower && hasUpper:
+		min, max = lower, upper
+	case hasLower:
+		min, max = lower, lower+1000
+	case hasUpper:
+		min = math.Min(1, upper-1000)
+		max = upper
+	}
+	if min > max {
+		return nil, false
+	}
+
+	switch strings.ToLower(colType) {
+	case "integer", "int", "int2", "int4", "int8", "smallint", "bigint":
+		low, high := int(math.Ceil(min)), int(math.Floor(max))
+		if low > high {
+			return nil, false
+		}
+		return low + g.rand.Intn(high-low+1), true
+	case "numeric", "decimal", "real", "double precision", "float", "float4", "float8":
+		value := min + g.rand.Float64()*(max-min)
+		if col.Scale > 0 {
+			scale := math.Pow(10, float64(col.Scale))
+			value = math.Max(min, math.Min(max, math.Round(value*scale)/scale))
+		}
+		return value, true
+	}
+	return nil, false
+}
+
+// foreignKeyValues picks a referenced row for every foreign key with a
+// column in the template and returns the key's values by lower-case column
+// name. All columns of a composite key come from the same row, so the
+// combination exists in the referenced table.
+func (g *DBGenerator) foreignKeyValues(tableInfo TableInfo, templateFields map[string]interface{}) map[string]interface{} {
+	values := make(map[string]interface{})
+	for _, fk := range tableInfo.ForeignKeys {
+		used := false
+		for field := range templateFields {
+			if fk.referencedColumn(field) != "" {
+				used = true
+				break
+			}
+		}
+		if !used {
+			continue
+		}
+
+		row, err := g.getValidForeignKeyValues(fk)
+		if err != nil {
+			fmt.Printf("Warning: Failed to get foreign key value for %s: %v\n", strings.Join(fk.Columns, ", "), err)
+			continue
+		}
+		for i, column := range fk.Columns {
+			values[strings.ToLower(column)] = row[i]
 		}
-		return fmt.Sprintf("value_%d", rand.Intn(1000)), nil
 	}
+	return values
 }
 
-// getValidForeignKeyValue gets a valid ID from the referenced table
-func (g *DBGenerator) getValidForeignKeyValue(refTable, columnName string) (interface{}, error) {
+// getValidForeignKeyValues gets the referenced columns of one row of the
+// foreign key's table, in the order of the key's columns
+func (g *DBGenerator) getValidForeignKeyValues(fk ForeignKeyInfo) ([]interface{}, error) {
+	refTable := fk.ReferencedTable
 	// First check if the table exists
-	checkQuery := `
-		SELECT EXISTS (
-			SELECT FROM information_schema.tables 
-			WHERE LOWER(table_name) = LOWER($1)
-		)
-	`
-	var exists bool
-	err := g.db.QueryRow(checkQuery, refTable).Scan(&exists)
+	existing, err := g.analyzer.FindTable(refTable)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check if table exists: %v", err)
 	}
-	if !exists {
+	if existing == "" {
 		if g.llmClient == nil {
 			return nil, fmt.Errorf("referenced table '%s' not found and LLM client is not available", refTable)
 		}
@@ -1021,15 +1311,29 @@ func (g *DBGenerator) getValidForeignKeyValue(refTable, columnName string) (inte
 		}
 	}
 
-	// Query to get a random valid ID from the referenced table
-	// Quote both table name and column name to handle case sensitivity
-	query := fmt.Sprintf(`SELECT "%s" FROM "%s" ORDER BY RANDOM() LIMIT 1`, columnName, refTable)
-	var value interface{}
-	err = g.db.QueryRow(query).Scan(&value)
+	// Query a random row of the referenced table for all the key's columns
+	// Quote both table name and column names to handle case sensitivity
+	values := make([]interface{}, len(fk.ReferencedColumns))
+	valuePtrs := make([]interface{}, len(values))
+	for i := range values {
+		valuePtrs[i] = &values[i]
+	}
+	pick, err := g.randomRowClause(refTable, fk.ReferencedColumns)
+	if err == nil {
+		query := fmt.Sprintf(`SELECT %s FROM %s %s`, quoteColumns(g.config.Type, fk.ReferencedColumns), quoteName(g.config.Type, refTable), pick)
+		err = g.db.QueryRow(query).Scan(valuePtrs...)
+	}
 	if err != nil {
 		if g.llmClient == nil {
 			return nil, fmt.Errorf("failed to get value from table '%s' and LLM client is not available", refTable)
 		}
+		// A suggested value can't be checked against the other columns of
+		// a composite key, so only single-column keys fall back to the LLM
+		if len(fk.ReferencedColumns) != 1 {
+			return nil, fmt.Errorf("failed to get a row from table '%s' for composite key (%s): %v", refTable, strings.Join(fk.Columns, ", "), err)
+		}
+		columnName := fk.ReferencedColumns[0]
+		var value interface{}
 
 		fmt.Printf("Failed to get value from table '%s'. Using LLM to suggest value...\n", refTable)
 
@@ -1059,7 +1363,7 @@ func (g *DBGenerator) getValidForeignKeyValue(refTable, columnName string) (inte
 		case 2:
 			if len(analysis.DataPatterns.ValueRange) > 0 {
 				// Use a random value from the range
-				value = analysis.DataPatterns.ValueRange[rand.Intn(len(analysis.DataPatterns.ValueRange))]
+				value = analysis.DataPatterns.ValueRange[g.rand.Intn(len(analysis.DataPatterns.ValueRange))]
 			} else {
 				value, err = g.generateValueForType(analysis.DataPatterns.DataType, true, columnName, ColumnInfo{})
 			}
@@ -1076,7 +1380,11 @@ func (g *DBGenerator) getValidForeignKeyValue(refTable, columnName string) (inte
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate value: %v", err)
 		}
+		values[0] = value
 	}
 
-	return value, nil
+	for i, value := range values {
+		values[i] = scannedValue(value)
+	}
+	return values, nil
 }
@@ -0,0 +1,192 @@
+package generator
+
+import (
+	"bytes"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"auto-api-tester/internal/llm"
+	"auto-api-tester/internal/testdata"
+
+	_ "modernc.org/sqlite"
+)
+
+const seedSchema = `
+CREATE TABLE customers (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name VARCHAR(40) NOT NULL,
+	email VARCHAR(80) NOT NULL UNIQUE,
+	age INTEGER CHECK (age BETWEEN 18 AND 99),
+	status TEXT,
+	balance NUMERIC(7,2)
+);
+CREATE TABLE orders (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	customer_id INTEGER NOT NULL REFERENCES customers(id),
+	total NUMERIC(7,2),
+	note TEXT
+);
+INSERT INTO customers (name, email, age, status, balance) VALUES
+	('Ada', 'ada@example.com', 36, 'active', 10.50),
+	('Grace', 'grace@example.com', 45, 'inactive', 99.99),
+	('Linus', 'linus@example.com', 28, 'active', 0);
+INSERT INTO orders (customer_id, total, note) VALUES
+	(1, 12.00, 'first'),
+	(2, 7.25, 'second'),
+	(3, 3.10, 'third');
+`
+
+const seedTemplate = `{
+	"endpoints": {
+		"POST /customers": [
+			{"body": [{"name": "", "email": "", "age": null, "status": null, "balance": null, "nickname": null}], "array_items": {"min": 2, "max": 4}}
+		],
+		"POST /orders": [
+			{"body": {"customer_id": null, "total": null, "note": null, "customer": {"name": "", "email": ""}}}
+		]
+	}
+}`
+
+// seedDatabase writes a SQLite database with the seed schema and rows to dir
+// and returns its path
+func seedDatabase(t *testing.T, dir string) string {
+	t.Helper()
+	dbPath := filepath.Join(dir, "seed.db")
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(seedSchema); err != nil {
+		t.Fatal(err)
+	}
+	return dbPath
+}
+
+// generateFrom runs the generator with seed 42 on template, returning the
+// test data it writes
+func generateFrom(t *testing.T, dbPath, templatePath, outputPath string) []byte {
+	t.Helper()
+	g := NewDBGenerator(DBConfig{Type: "sqlite", Database: dbPath}, llm.Config{}, Options{Seed: 42}, templatePath, outputPath)
+	if err := g.GenerateTestData(); err != nil {
+		t.Fatalf("GenerateTestData: %v", err)
+	}
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestGenerateTestDataIsReproducibleWithSeed(t *testing.T) {
+	dir := t.TempDir()
+	// NewDBGenerator creates its log directory in the working directory
+	t.Chdir(dir)
+	dbPath := seedDatabase(t, dir)
+
+	templatePath := filepath.Join(dir, "template.json")
+	if err := os.WriteFile(templatePath, []byte(seedTemplate), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	first := generateFrom(t, dbPath, templatePath, filepath.Join(dir, "first.json"))
+	for _, name := range []string{"second.json", "third.json"} {
+		if again := generateFrom(t, dbPath, templatePath, filepath.Join(dir, name)); !bytes.Equal(first, again) {
+			t.Fatalf("seed 42 generated different data:\n%s\n---\n%s", first, again)
+		}
+	}
+}
+
+func TestGenerateTestDataKeepsTestsAndGroups(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	dbPath := seedDatabase(t, dir)
+
+	templatePath := filepath.Join(dir, "template.json")
+	template := `{
+		"groups": {
+			"orders": {
+				"POST /orders": {"body": {"customer_id": null, "note": null}}
+			}
+		},
+		"tests": [
+			{"method": "POST", "path": "/customers", "name": "listed", "body": {"name": "", "email": ""}}
+		]
+	}`
+	if err := os.WriteFile(templatePath, []byte(template), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	outputPath := filepath.Join(dir, "testdata.json")
+	generateFrom(t, dbPath, templatePath, outputPath)
+	data, err := testdata.LoadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := data.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID()
+		body, ok := entry.Body.(map[string]interface{})
+		if !ok {
+			t.Fatalf("%s: body = %#v, want an object", entry.ID(), entry.Body)
+		}
+		for field, value := range body {
+			if value == nil || value == "" {
+				t.Errorf("%s: field %s was not generated", entry.ID(), field)
+			}
+		}
+	}
+	want := []string{"POST /orders", "POST /customers [listed]"}
+	if !slices.Equal(ids, want) {
+		t.Errorf("entries = %v, want %v", ids, want)
+	}
+	if len(data.Groups["orders"]) != 1 || len(data.Tests) != 1 {
+		t.Errorf("groups = %v, tests = %v; want both kept in place", data.Groups, data.Tests)
+	}
+}
+
+func TestGenerateTestDataReadsGroupedJSON5Template(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	dbPath := seedDatabase(t, dir)
+
+	// The shape -group-by-tag writes: comments, trailing commas and groups
+	templatePath := filepath.Join(dir, "testdata_template.json5")
+	template := `{
+		"groups": {
+			// Customer accounts
+			"customers": {
+				/* Create a customer */
+				"POST /customers": {
+					"body": {"name": "", "email": "",},
+				},
+			},
+		},
+	}`
+	if err := os.WriteFile(templatePath, []byte(template), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	outputPath := filepath.Join(dir, "testdata.json")
+	generateFrom(t, dbPath, templatePath, outputPath)
+	data, err := testdata.LoadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := data.Groups["customers"]["POST /customers"]
+	if len(cases) != 1 {
+		t.Fatalf("groups = %v, want the POST /customers entry under customers", data.Groups)
+	}
+	body, _ := cases[0].Body.(map[string]interface{})
+	if email, _ := body["email"].(string); email == "" {
+		t.Errorf("body = %v, want a generated email", cases[0].Body)
+	}
+}
@@ -0,0 +1,58 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// quoteName quotes a table or column name the way the database type expects:
+// backticks for mysql, brackets for sqlserver and double quotes otherwise
+func quoteName(dbType, name string) string {
+	switch dbType {
+	case "mysql":
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	case "sqlserver":
+		return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+	}
+	return quoteIdentifier(name)
+}
+
+// quoteColumns joins column names into a quoted, comma-separated list
+func quoteColumns(dbType string, columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = quoteName(dbType, column)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// placeholder returns the bind parameter for the nth query argument,
+// counting from 1: ? for mysql, @pN for sqlserver and $N otherwise
+func placeholder(dbType string, n int) string {
+	switch dbType {
+	case "mysql":
+		return "?"
+	case "sqlserver":
+		return fmt.Sprintf("@p%d", n)
+	}
+	return fmt.Sprintf("$%d", n)
+}
+
+// selectFirst returns a query selecting the first row of "SELECT columns
+// FROM rest", using TOP for sqlserver, which has no LIMIT
+func selectFirst(dbType, columns, rest string) string {
+	if dbType == "sqlserver" {
+		return fmt.Sprintf("SELECT TOP 1 %s FROM %s", columns, rest)
+	}
+	return fmt.Sprintf("SELECT %s FROM %s LIMIT 1", columns, rest)
+}
+
+// scannedValue converts a value scanned into an interface{} to one that
+// marshals as the column's content: drivers such as MySQL's return text
+// columns as []byte, which JSON would encode as base64
+func scannedValue(value interface{}) interface{} {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	return value
+}
@@ -0,0 +1,21 @@
+package generator
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestScannedValueMarshalsTextAsString(t *testing.T) {
+	record := map[string]interface{}{
+		"name":  scannedValue([]byte("Ada")),
+		"age":   scannedValue(int64(36)),
+		"email": scannedValue(nil),
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"age":36,"email":null,"name":"Ada"}`; string(data) != want {
+		t.Errorf("record = %s, want %s", data, want)
+	}
+}
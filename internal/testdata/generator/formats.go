@@ -0,0 +1,168 @@
+package generator
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"auto-api-tester/internal/types"
+)
+
+// formatGenerators produce format-valid values for well-known field kinds.
+// They back both the built-in column name patterns and configured field rules.
+var formatGenerators = map[string]func(r *lockedRand) string{
+	"iban":        generateIBAN,
+	"credit_card": generateCreditCard,
+	"isbn":        generateISBN,
+	"slug":        generateSlug,
+	"color":       generateColor,
+	"email": func(r *lockedRand) string {
+		return fmt.Sprintf("user_%d@example.com", r.Intn(1000))
+	},
+	"phone": func(r *lockedRand) string {
+		return fmt.Sprintf("+1-%d-%d-%d", r.Intn(900)+100, r.Intn(900)+100, r.Intn(9000)+1000)
+	},
+}
+
+// formatPatterns map column name fragments to the format generator used for them
+var formatPatterns = []struct {
+	fragment  string
+	generator string
+}{
+	{"iban", "iban"},
+	{"credit_card", "credit_card"},
+	{"card_number", "credit_card"},
+	{"isbn", "isbn"},
+	{"slug", "slug"},
+	{"colour", "color"},
+	{"color", "color"},
+}
+
+// ValidateFieldRules checks that every rule names a known generator or
+// supplies values or a template
+func ValidateFieldRules(rules []types.FieldRule) error {
+	for i, rule := range rules {
+		if rule.Pattern == "" {
+			return fmt.Errorf("field rule %d: pattern is required", i)
+		}
+		switch {
+		case rule.Generator != "":
+			if _, ok := formatGenerators[rule.Generator]; !ok {
+				return fmt.Errorf("field rule %q: unknown generator %q", rule.Pattern, rule.Generator)
+			}
+		case len(rule.Values) == 0 && rule.Template == "":
+			return fmt.Errorf("field rule %q: one of generator, values or template is required", rule.Pattern)
+		}
+	}
+	return nil
+}
+
+// formatValue returns a value for columnName from the first matching configured
+// rule or built-in pattern
+func (g *DBGenerator) formatValue(columnName string) (string, bool) {
+	name := strings.ToLower(columnName)
+	for _, rule := range g.options.FieldRules {
+		if strings.Contains(name, strings.ToLower(rule.Pattern)) {
+			return g.applyRule(rule), true
+		}
+	}
+	for _, pattern := range formatPatterns {
+		if strings.Contains(name, pattern.fragment) {
+			return formatGenerators[pattern.generator](g.rand), true
+		}
+	}
+	return "", false
+}
+
+// applyRule generates a value from a configured field rule
+func (g *DBGenerator) applyRule(rule types.FieldRule) string {
+	switch {
+	case rule.Generator != "":
+		return formatGenerators[rule.Generator](g.rand)
+	case len(rule.Values) > 0:
+		return rule.Values[g.rand.Intn(len(rule.Values))]
+	}
+	return fillTemplate(rule.Template, g.rand)
+}
+
+// fillTemplate replaces # with a random digit and ? with a random uppercase letter
+func fillTemplate(template string, r *lockedRand) string {
+	var b strings.Builder
+	for _, c := range template {
+		switch c {
+		case '#':
+			b.WriteByte(byte('0' + r.Intn(10)))
+		case '?':
+			b.WriteByte(byte('A' + r.Intn(26)))
+		default:
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+func randomDigits(r *lockedRand, n int) string {
+	digits := make([]byte, n)
+	for i := range digits {
+		digits[i] = byte('0' + r.Intn(10))
+	}
+	return string(digits)
+}
+
+// generateIBAN returns a German IBAN with valid ISO 13616 check digits
+func generateIBAN(r *lockedRand) string {
+	bban := randomDigits(r, 18)
+	// Check digits: move "DE00" to the end, map D=13 E=14, and take 98 - mod 97
+	numeric, _ := new(big.Int).SetString(bban+"131400", 10)
+	check := 98 - new(big.Int).Mod(numeric, big.NewInt(97)).Int64()
+	return fmt.Sprintf("DE%02d%s", check, bban)
+}
+
+// generateCreditCard returns a 16-digit Visa test number passing the Luhn check
+func generateCreditCard(r *lockedRand) string {
+	number := "4" + randomDigits(r, 14)
+	return number + string(byte('0'+luhnCheckDigit(number)))
+}
+
+// luhnCheckDigit computes the digit that makes number+digit pass the Luhn check
+func luhnCheckDigit(number string) int {
+	sum := 0
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		// Double every second digit counting from the check digit's position
+		if (len(number)-1-i)%2 == 0 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return (10 - sum%10) % 10
+}
+
+// generateISBN returns an ISBN-13 with a valid check digit
+func generateISBN(r *lockedRand) string {
+	digits := "978" + randomDigits(r, 9)
+	sum := 0
+	for i, c := range digits {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(c-'0') * weight
+	}
+	return digits + string(byte('0'+(10-sum%10)%10))
+}
+
+var slugWords = []string{"alpha", "bravo", "delta", "echo", "nova", "orbit", "pixel", "quartz", "river", "summit"}
+
+// generateSlug returns a URL slug such as "river-pixel-42"
+func generateSlug(r *lockedRand) string {
+	return fmt.Sprintf("%s-%s-%d", slugWords[r.Intn(len(slugWords))], slugWords[r.Intn(len(slugWords))], r.Intn(100))
+}
+
+// generateColor returns a hex color such as "#1a2b3c"
+func generateColor(r *lockedRand) string {
+	return fmt.Sprintf("#%06x", r.Intn(0x1000000))
+}
@@ -0,0 +1,130 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// mysqlCatalog extends the information_schema catalog with the enum values
+// MySQL keeps in column_type and its CHECK constraints
+type mysqlCatalog struct {
+	informationSchema
+}
+
+// mysqlUnknownTable is the error MySQL before 8.0.16 reports for
+// information_schema.check_constraints, which it doesn't have
+const mysqlUnknownTable = 1109
+
+// mysqlIdentifier matches a backquoted name in a CHECK clause
+var mysqlIdentifier = regexp.MustCompile("`((?:[^`]|``)+)`")
+
+// columns adds the allowed values of enum and CHECK-constrained columns to
+// the information_schema column list
+func (c mysqlCatalog) columns(tableName string) ([]ColumnInfo, error) {
+	columns, err := c.informationSchema.columns(tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*ColumnInfo, len(columns))
+	for i := range columns {
+		byName[strings.ToLower(columns[i].Name)] = &columns[i]
+	}
+
+	if err := c.enumValues(tableName, byName); err != nil {
+		return nil, err
+	}
+	if err := c.tableChecks(tableName, byName); err != nil {
+		return nil, err
+	}
+	return columns, nil
+}
+
+// enumValues fills EnumValues for enum columns from their column_type, such
+// as enum('active','inactive'), in declaration order
+func (c mysqlCatalog) enumValues(tableName string, columns map[string]*ColumnInfo) error {
+	filter, args := c.inSchema("table_schema", tableName)
+	query := fmt.Sprintf(`
+		SELECT column_name, column_type
+		FROM information_schema.columns
+		WHERE data_type = 'enum'
+		AND LOWER(table_name) = LOWER(%s)
+		%s
+	`, c.param(1), filter)
+	rows, err := c.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var column, columnType string
+		if err := rows.Scan(&column, &columnType); err != nil {
+			return err
+		}
+		col, ok := columns[strings.ToLower(column)]
+		if !ok {
+			continue
+		}
+		col.EnumValues = nil
+		for _, match := range checkLiteral.FindAllStringSubmatch(columnType, -1) {
+			col.EnumValues = append(col.EnumValues, strings.ReplaceAll(match[1], "''", "'"))
+		}
+	}
+	return rows.Err()
+}
+
+// tableChecks applies the CHECK constraints of a table that refer to a single
+// column. MySQL doesn't record which columns a check uses, so they are read
+// from the backquoted names in its clause.
+func (c mysqlCatalog) tableChecks(tableName string, columns map[string]*ColumnInfo) error {
+	filter, args := c.inSchema("tc.table_schema", tableName)
+	query := fmt.Sprintf(`
+		SELECT cc.check_clause
+		FROM information_schema.table_constraints tc
+		JOIN information_schema.check_constraints cc
+			ON cc.constraint_schema = tc.constraint_schema
+			AND cc.constraint_name = tc.constraint_name
+		WHERE tc.constraint_type = 'CHECK'
+		AND LOWER(tc.table_name) = LOWER(%s)
+		%s
+	`, c.param(1), filter)
+	rows, err := c.db.Query(query, args...)
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlUnknownTable {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var clause string
+		if err := rows.Scan(&clause); err != nil {
+			return err
+		}
+		// String literals come back escaped, as in _utf8mb4\'active\'
+		clause = strings.ReplaceAll(clause, `\'`, `'`)
+
+		var names []string
+		for _, match := range mysqlIdentifier.FindAllStringSubmatch(clause, -1) {
+			name := strings.ToLower(strings.ReplaceAll(match[1], "``", "`"))
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+		if len(names) != 1 {
+			continue
+		}
+		if col, ok := columns[names[0]]; ok {
+			applyCheckConstraint(col, col.Name, clause)
+		}
+	}
+	return rows.Err()
+}
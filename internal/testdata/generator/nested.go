@@ -0,0 +1,189 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxNestingDepth bounds how deep nested objects are filled from related tables
+const maxNestingDepth = 3
+
+// relatedTable describes the table a nested body object represents
+type relatedTable struct {
+	Name string
+	// Columns and ReferencedColumns link the parent row to the related row
+	// when the parent holds a foreign key to it, pairing up by position
+	Columns           []string
+	ReferencedColumns []string
+}
+
+// normalizeName reduces a table or field name for loose matching, so that
+// "customer", "Customers" and "customer_id" all compare equal
+func normalizeName(name string) string {
+	name = strings.ToLower(name)
+	name = strings.NewReplacer("_", "", "-", "").Replace(name)
+	if len(name) > 2 && strings.HasSuffix(name, "id") {
+		name = strings.TrimSuffix(name, "id")
+	}
+	return strings.TrimSuffix(name, "s")
+}
+
+// findRelatedTable matches a nested template field to a related table, first
+// through the parent's foreign keys and then by table name
+func findRelatedTable(field string, tableInfo TableInfo, related []string) (relatedTable, bool) {
+	target := normalizeName(field)
+	for _, fk := range tableInfo.ForeignKeys {
+		match := normalizeName(fk.ReferencedTable) == target
+		for _, column := range fk.Columns {
+			match = match || normalizeName(column) == target
+		}
+		if match {
+			return relatedTable{Name: fk.ReferencedTable, Columns: fk.Columns, ReferencedColumns: fk.ReferencedColumns}, true
+		}
+	}
+	for _, table := range related {
+		if normalizeName(table) == target {
+			return relatedTable{Name: table}, true
+		}
+	}
+	return relatedTable{}, false
+}
+
+// generateNestedValues fills the nested objects and arrays of objects in the
+// template from related tables' records, matching the template's nesting
+func (g *DBGenerator) generateNestedValues(data map[string]interface{}, templateFields map[string]interface{}, tableInfo TableInfo, related []string, depth int) {
+	if depth >= maxNestingDepth {
+		return
+	}
+
+	for _, fieldName := range sortedKeys(templateFields) {
+		templateValue := templateFields[fieldName]
+		var nested map[string]interface{}
+		isArray := false
+		switch value := templateValue.(type) {
+		case map[string]interface{}:
+			nested = value
+		case []interface{}:
+			if len(value) > 0 {
+				nested, _ = value[0].(map[string]interface{})
+				isArray = true
+			}
+		}
+		if nested == nil {
+			continue
+		}
+
+		table, ok := findRelatedTable(fieldName, tableInfo, related)
+		if !ok {
+			continue
+		}
+
+		record, err := g.relatedRecord(table, data)
+		if err != nil {
+			fmt.Printf("Warning: Failed to get related record for %s from %s: %v\n", fieldName, table.Name, err)
+			continue
+		}
+
+		object, err := g.populateFromRecord(nested, table.Name, record, depth+1)
+		if err != nil {
+			fmt.Printf("Warning: Failed to populate %s from %s: %v\n", fieldName, table.Name, err)
+			continue
+		}
+
+		if isArray {
+			data[fieldName] = []interface{}{object}
+		} else {
+			data[fieldName] = object
+		}
+	}
+}
+
+// relatedRecord returns the related row the parent's foreign key points to,
+// or a random row when the parent lacks a value for any of the key's columns
+func (g *DBGenerator) relatedRecord(table relatedTable, parent map[string]interface{}) (map[string]interface{}, error) {
+	if len(table.Columns) == 0 {
+		return g.getSampleRecord(table.Name)
+	}
+	values := make([]interface{}, len(table.Columns))
+	for i, column := range table.Columns {
+		for key, value := range parent {
+			if strings.EqualFold(key, column) {
+				values[i] = value
+				break
+			}
+		}
+		if values[i] == nil {
+			return g.getSampleRecord(table.Name)
+		}
+	}
+	return g.getRecordByColumns(table.Name, table.ReferencedColumns, values)
+}
+
+// populateFromRecord builds a nested object with the template's fields, taking
+// values from the related record and descending into deeper related tables
+func (g *DBGenerator) populateFromRecord(template map[string]interface{}, tableName string, record map[string]interface{}, depth int) (map[string]interface{}, error) {
+	object := make(map[string]interface{}, len(template))
+	for fieldName, templateValue := range template {
+		object[fieldName] = templateValue
+		for column, value := range record {
+			if strings.EqualFold(column, fieldName) {
+				object[fieldName] = scannedValue(value)
+				break
+			}
+		}
+	}
+
+	if depth < maxNestingDepth {
+		tableInfo, err := g.analyzer.analyzeTable(tableName)
+		if err != nil {
+			return nil, err
+		}
+		related, err := g.analyzer.FindRelatedTables(tableName)
+		if err != nil {
+			return nil, err
+		}
+		g.generateNestedValues(object, template, tableInfo, related, depth)
+	}
+
+	return object, nil
+}
+
+// getRecordByColumns fetches the row of tableName whose columns equal values
+func (g *DBGenerator) getRecordByColumns(tableName string, columns []string, keyValues []interface{}) (map[string]interface{}, error) {
+	conditions := make([]string, len(columns))
+	for i, column := range columns {
+		conditions[i] = quoteName(g.config.Type, column) + " = " + placeholder(g.config.Type, i+1)
+	}
+	query := selectFirst(g.config.Type, "*", quoteName(g.config.Type, tableName)+" WHERE "+strings.Join(conditions, " AND "))
+	rows, err := g.db.Query(query, keyValues...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query table %s: %v", tableName, err)
+	}
+	defer rows.Close()
+
+	columnNames, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get column names: %v", err)
+	}
+
+	values := make([]interface{}, len(columnNames))
+	valuePtrs := make([]interface{}, len(columnNames))
+	for i := range values {
+		valuePtrs[i] = &values[i]
+	}
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("no record in %s with (%s) = %v", tableName, strings.Join(columns, ", "), keyValues)
+	}
+	if err := rows.Scan(valuePtrs...); err != nil {
+		return nil, fmt.Errorf("failed to scan row: %v", err)
+	}
+
+	record := make(map[string]interface{})
+	for i, col := range columnNames {
+		if values[i] != nil {
+			record[col] = scannedValue(values[i])
+		}
+	}
+	return record, nil
+}
@@ -0,0 +1,152 @@
+package generator
+
+import (
+	"fmt"
+)
+
+// postgresCatalog extends the information_schema catalog with the enum labels
+// and CHECK constraints that only pg_catalog exposes
+type postgresCatalog struct {
+	informationSchema
+}
+
+// columns adds the allowed values of enum, domain and CHECK-constrained
+// columns to the information_schema column list
+func (c postgresCatalog) columns(tableName string) ([]ColumnInfo, error) {
+	columns, err := c.informationSchema.columns(tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*ColumnInfo, len(columns))
+	for i := range columns {
+		byName[columns[i].Name] = &columns[i]
+	}
+
+	if err := c.enumLabels(tableName, byName); err != nil {
+		return nil, err
+	}
+	if err := c.domainChecks(tableName, byName); err != nil {
+		return nil, err
+	}
+	if err := c.tableChecks(tableName, byName); err != nil {
+		return nil, err
+	}
+	return columns, nil
+}
+
+// enumLabels fills EnumValues for columns of an enum type, in declaration order
+func (c postgresCatalog) enumLabels(tableName string, columns map[string]*ColumnInfo) error {
+	filter, args := c.inSchema("c.table_schema", tableName)
+	query := fmt.Sprintf(`
+		SELECT c.column_name, e.enumlabel
+		FROM information_schema.columns c
+		JOIN pg_type t ON t.typname = c.udt_name
+		JOIN pg_namespace n ON n.oid = t.typnamespace AND n.nspname = c.udt_schema
+		JOIN pg_enum e ON e.enumtypid = t.oid
+		WHERE LOWER(c.table_name) = LOWER($1)
+		%s
+		ORDER BY c.column_name, e.enumsortorder
+	`, filter)
+	rows, err := c.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var column, label string
+		if err := rows.Scan(&column, &label); err != nil {
+			return err
+		}
+		if col, ok := columns[column]; ok {
+			col.EnumValues = append(col.EnumValues, label)
+		}
+	}
+	return rows.Err()
+}
+
+// domainChecks applies the CHECK constraints of a column's domain, which
+// refer to the value as VALUE
+func (c postgresCatalog) domainChecks(tableName string, columns map[string]*ColumnInfo) error {
+	filter, args := c.inSchema("c.table_schema", tableName)
+	query := fmt.Sprintf(`
+		SELECT c.column_name, c.domain_name, pg_get_constraintdef(con.oid)
+		FROM information_schema.columns c
+		JOIN pg_type t ON t.typname = c.domain_name
+		JOIN pg_namespace n ON n.oid = t.typnamespace AND n.nspname = c.domain_schema
+		JOIN pg_constraint con ON con.contypid = t.oid AND con.contype = 'c'
+		WHERE LOWER(c.table_name) = LOWER($1)
+		%s
+	`, filter)
+	rows, err := c.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var column, domain, definition string
+		if err := rows.Scan(&column, &domain, &definition); err != nil {
+			return err
+		}
+		if col, ok := columns[column]; ok {
+			col.DomainName = domain
+			applyCheckConstraint(col, "value", definition)
+		}
+	}
+	return rows.Err()
+}
+
+// tableChecks applies the single-column CHECK constraints of a table
+func (c postgresCatalog) tableChecks(tableName string, columns map[string]*ColumnInfo) error {
+	filter, args := c.inSchema("n.nspname", tableName)
+	query := fmt.Sprintf(`
+		SELECT a.attname, pg_get_constraintdef(con.oid)
+		FROM pg_constraint con
+		JOIN pg_class rel ON rel.oid = con.conrelid
+		JOIN pg_namespace n ON n.oid = rel.relnamespace
+		JOIN pg_attribute a ON a.attrelid = rel.oid AND a.attnum = con.conkey[1]
+		WHERE con.contype = 'c'
+		AND array_length(con.conkey, 1) = 1
+		AND LOWER(rel.relname) = LOWER($1)
+		%s
+	`, filter)
+	rows, err := c.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var column, definition string
+		if err := rows.Scan(&column, &definition); err != nil {
+			return err
+		}
+		if col, ok := columns[column]; ok {
+			applyCheckConstraint(col, column, definition)
+		}
+	}
+	return rows.Err()
+}
+
+// applyCheckConstraint records a CHECK definition on a column along with the
+// enum values or bounds it implies, keeping bounds set by earlier constraints
+func applyCheckConstraint(col *ColumnInfo, name, definition string) {
+	if col.CheckConstraint == "" {
+		col.CheckConstraint = definition
+	} else {
+		col.CheckConstraint += " AND " + definition
+	}
+
+	enum, min, max := parseCheckConstraint(name, definition)
+	if len(enum) > 0 && len(col.EnumValues) == 0 {
+		col.EnumValues = enum
+	}
+	if min != nil {
+		col.MinValue = min
+	}
+	if max != nil {
+		col.MaxValue = max
+	}
+}
@@ -0,0 +1,110 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rand is the random source a ValueProvider draws from. It is the generator's
+// seeded source, so provided values are reproducible with -seed.
+type Rand interface {
+	Intn(n int) int
+}
+
+// ValueProvider produces values for the kinds of column recognised by name,
+// such as "email", "first_name" or "company". It returns false for a kind it
+// doesn't cover, which then gets the built-in synthetic value.
+type ValueProvider interface {
+	Value(kind string, r Rand) (interface{}, bool)
+}
+
+// syntheticValues are the built-in values for each named kind: cheap,
+// obviously generated values such as John42 or City7
+var syntheticValues = map[string]func(r *lockedRand) interface{}{
+	"email": func(r *lockedRand) interface{} { return formatGenerators["email"](r) },
+	"phone": func(r *lockedRand) interface{} { return formatGenerators["phone"](r) },
+	"first_name": func(r *lockedRand) interface{} {
+		return fmt.Sprintf("John%d", r.Intn(100))
+	},
+	"last_name": func(r *lockedRand) interface{} {
+		return fmt.Sprintf("Doe%d", r.Intn(100))
+	},
+	"address": func(r *lockedRand) interface{} {
+		return fmt.Sprintf("%d Main St", r.Intn(1000)+1)
+	},
+	"city": func(r *lockedRand) interface{} {
+		return fmt.Sprintf("City%d", r.Intn(100))
+	},
+	"country": func(r *lockedRand) interface{} {
+		return fmt.Sprintf("Country%d", r.Intn(100))
+	},
+	"postal_code": func(r *lockedRand) interface{} {
+		return fmt.Sprintf("%d%d", r.Intn(90000)+10000, r.Intn(1000)+100)
+	},
+	"username": func(r *lockedRand) interface{} {
+		return fmt.Sprintf("user_%d", r.Intn(1000))
+	},
+	"company": func(r *lockedRand) interface{} {
+		return fmt.Sprintf("Company%d", r.Intn(1000))
+	},
+	"county": func(r *lockedRand) interface{} {
+		return fmt.Sprintf("County%d", r.Intn(100))
+	},
+}
+
+// provide returns a value of the named kind from the configured provider,
+// falling back to the synthetic value
+func (g *DBGenerator) provide(kind string) interface{} {
+	if g.options.Provider != nil {
+		if value, ok := g.options.Provider.Value(kind, g.rand); ok {
+			return value
+		}
+	}
+	return syntheticValues[kind](g.rand)
+}
+
+// RealisticProvider produces plausible names, addresses and contact details
+// from built-in word lists, for APIs that validate them. Emails use the
+// reserved example.com domain and phone numbers the fictional 555 exchange.
+type RealisticProvider struct{}
+
+var (
+	fakeFirstNames = []string{"James", "Mary", "Robert", "Patricia", "John", "Jennifer", "Michael", "Linda", "David", "Elizabeth", "William", "Barbara", "Richard", "Susan", "Joseph", "Jessica", "Thomas", "Sarah", "Daniel", "Karen", "Olivia", "Liam", "Emma", "Noah", "Sofia", "Lucas", "Amelia", "Mateo", "Chloe", "Hannah"}
+	fakeLastNames  = []string{"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Rodriguez", "Martinez", "Hernandez", "Lopez", "Wilson", "Anderson", "Thomas", "Taylor", "Moore", "Jackson", "Martin", "Lee", "Thompson", "White", "Harris", "Clark", "Lewis", "Walker", "Young", "Allen", "King", "Wright"}
+	fakeStreets    = []string{"Main St", "Oak Ave", "Maple Dr", "Cedar Ln", "Pine St", "Elm St", "Washington Ave", "Lake Rd", "Hill St", "Park Ave", "River Rd", "Sunset Blvd", "Church St", "Highland Ave", "Spring St"}
+	fakeCities     = []string{"Springfield", "Portland", "Austin", "Denver", "Columbus", "Madison", "Richmond", "Raleigh", "Boise", "Omaha", "Tucson", "Savannah", "Burlington", "Sacramento", "Albany"}
+	fakeCounties   = []string{"Orange County", "King County", "Cook County", "Travis County", "Marion County", "Franklin County", "Jefferson County", "Lincoln County", "Madison County", "Washington County"}
+	fakeCountries  = []string{"United States", "Canada", "United Kingdom", "Germany", "France", "Spain", "Italy", "Netherlands", "Sweden", "Australia", "New Zealand", "Japan", "Brazil", "Mexico", "Ireland"}
+	fakeCompanies  = []string{"Acme", "Globex", "Initech", "Umbrella", "Stark", "Wayne", "Hooli", "Vandelay", "Soylent", "Cyberdyne", "Tyrell", "Wonka", "Oscorp", "Aperture", "Massive Dynamic"}
+	fakeSuffixes   = []string{"Inc.", "LLC", "Ltd.", "Group", "Corp.", "Industries", "Holdings", "Labs"}
+)
+
+// Value implements ValueProvider
+func (RealisticProvider) Value(kind string, r Rand) (interface{}, bool) {
+	pick := func(words []string) string { return words[r.Intn(len(words))] }
+	switch kind {
+	case "first_name":
+		return pick(fakeFirstNames), true
+	case "last_name":
+		return pick(fakeLastNames), true
+	case "email":
+		return fmt.Sprintf("%s.%s%d@example.com", strings.ToLower(pick(fakeFirstNames)), strings.ToLower(pick(fakeLastNames)), r.Intn(100)), true
+	case "username":
+		return fmt.Sprintf("%s%s%d", strings.ToLower(pick(fakeFirstNames)[:1]), strings.ToLower(pick(fakeLastNames)), r.Intn(100)), true
+	case "phone":
+		return fmt.Sprintf("+1-%d%02d-555-%04d", r.Intn(8)+2, r.Intn(100), r.Intn(100)+100), true
+	case "address":
+		return fmt.Sprintf("%d %s", r.Intn(9900)+100, pick(fakeStreets)), true
+	case "city":
+		return pick(fakeCities), true
+	case "county":
+		return pick(fakeCounties), true
+	case "country":
+		return pick(fakeCountries), true
+	case "postal_code":
+		return fmt.Sprintf("%05d", r.Intn(99000)+1000), true
+	case "company":
+		return fmt.Sprintf("%s %s", pick(fakeCompanies), pick(fakeSuffixes)), true
+	}
+	return nil, false
+}
@@ -0,0 +1,97 @@
+package generator
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// referenceTime anchors generated dates and timestamps so that output depends
+// only on the seed, the template and the database, not on when it ran
+var referenceTime = time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+// lockedRand is a *rand.Rand that is safe for concurrent use, so generation
+// stays reproducible from a single seed when values are produced in parallel
+type lockedRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+// newLockedRand creates a generator from seed, using the current time when seed is zero
+func newLockedRand(seed int64) *lockedRand {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &lockedRand{r: rand.New(rand.NewSource(seed))}
+}
+
+// Intn returns a non-negative pseudo-random number in [0,n)
+func (l *lockedRand) Intn(n int) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Intn(n)
+}
+
+// Float64 returns a pseudo-random number in [0.0,1.0)
+func (l *lockedRand) Float64() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Float64()
+}
+
+// Read fills p with pseudo-random bytes, so seeded UUIDs can be drawn from it
+func (l *lockedRand) Read(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Read(p)
+}
+
+// Float32 returns a pseudo-random number in [0.0,1.0)
+func (l *lockedRand) Float32() float32 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Float32()
+}
+
+// newUUID returns a random UUID drawn from the generator's seeded source
+func (g *DBGenerator) newUUID() string {
+	id, err := uuid.NewRandomFromReader(g.rand)
+	if err != nil {
+		return uuid.New().String()
+	}
+	return id.String()
+}
+
+// randomRowClause returns the ORDER BY clause selecting one row of table at a
+// seeded offset, so the same seed and data always pick the same row. The rows
+// are ordered by all of orderColumns, e.g. every column of a composite key.
+func (g *DBGenerator) randomRowClause(tableName string, orderColumns []string) (string, error) {
+	var count int
+	if err := g.db.QueryRow(fmt.Sprintf(`SELECT COUNT(*) FROM %s`, quoteName(g.config.Type, tableName))).Scan(&count); err != nil {
+		return "", fmt.Errorf("failed to count rows of %s: %v", tableName, err)
+	}
+	offset := 0
+	if count > 0 {
+		offset = g.rand.Intn(count)
+	}
+	orderBy := quoteColumns(g.config.Type, orderColumns)
+	if g.config.Type == "sqlserver" {
+		return fmt.Sprintf(`ORDER BY %s OFFSET %d ROWS FETCH NEXT 1 ROWS ONLY`, orderBy, offset), nil
+	}
+	return fmt.Sprintf(`ORDER BY %s LIMIT 1 OFFSET %d`, orderBy, offset), nil
+}
+
+// sortedKeys returns the keys of m in order, keeping seeded generation stable
+// across Go's randomized map iteration
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,321 @@
+package generator
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// sqliteCatalog reads table structure from sqlite_master and the table PRAGMAs
+type sqliteCatalog struct {
+	db *sql.DB
+}
+
+// sqliteColumn is a row of PRAGMA table_info
+type sqliteColumn struct {
+	name     string
+	declType string
+	notNull  bool
+	dflt     interface{}
+	pk       int
+}
+
+// tableNames lists the user tables, skipping SQLite's internal ones
+func (c sqliteCatalog) tableNames() ([]string, error) {
+	rows, err := c.db.Query(`
+		SELECT LOWER(name)
+		FROM sqlite_master
+		WHERE type = 'table'
+		AND name NOT LIKE 'sqlite_%'
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var tableName string
+		if err := rows.Scan(&tableName); err != nil {
+			return nil, err
+		}
+		tables = append(tables, tableName)
+	}
+	return tables, rows.Err()
+}
+
+// findTable looks a table up by name, ignoring case
+func (c sqliteCatalog) findTable(name string) (string, error) {
+	var tableName string
+	err := c.db.QueryRow(`
+		SELECT name
+		FROM sqlite_master
+		WHERE type = 'table'
+		AND LOWER(name) = LOWER($1)
+		LIMIT 1
+	`, name).Scan(&tableName)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return tableName, err
+}
+
+// tableInfo reads PRAGMA table_info for a table
+func (c sqliteCatalog) tableInfo(tableName string) ([]sqliteColumn, error) {
+	rows, err := c.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", quoteIdentifier(tableName)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []sqliteColumn
+	for rows.Next() {
+		var col sqliteColumn
+		var cid, notNull int
+		if err := rows.Scan(&cid, &col.name, &col.declType, &notNull, &col.dflt, &col.pk); err != nil {
+			return nil, err
+		}
+		col.notNull = notNull != 0
+		columns = append(columns, col)
+	}
+	return columns, rows.Err()
+}
+
+// columns maps PRAGMA table_info onto ColumnInfo, marking unique and foreign
+// key columns from the table's indexes and foreign key list
+func (c sqliteCatalog) columns(tableName string) ([]ColumnInfo, error) {
+	info, err := c.tableInfo(tableName)
+	if err != nil {
+		return nil, err
+	}
+	uniques, err := c.uniqueColumns(tableName)
+	if err != nil {
+		return nil, err
+	}
+	fks, err := c.foreignKeys(tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	pkCount := 0
+	for _, col := range info {
+		if col.pk > 0 {
+			pkCount++
+		}
+	}
+
+	columns := make([]ColumnInfo, 0, len(info))
+	for _, col := range info {
+		column := ColumnInfo{
+			Name:      col.name,
+			Nullable:  !col.notNull && col.pk == 0,
+			Default:   col.dflt,
+			IsPrimary: col.pk > 0,
+			IsUnique:  col.pk > 0 && pkCount == 1 || uniques[col.name],
+		}
+		column.Type, column.MaxLength, column.Precision, column.Scale = parseSQLiteType(col.declType)
+		// A lone INTEGER PRIMARY KEY aliases the rowid, which SQLite assigns
+		column.IsAutoIncrement = column.IsPrimary && pkCount == 1 && column.Type == "integer"
+		for _, fk := range fks {
+			if fk.referencedColumn(col.name) != "" {
+				column.IsForeign = true
+				column.References = fk.ReferencedTable
+				break
+			}
+		}
+		columns = append(columns, column)
+	}
+
+	// Match the column order of the information_schema catalog
+	sort.Slice(columns, func(i, j int) bool { return columns[i].Name < columns[j].Name })
+	return columns, nil
+}
+
+// uniqueColumns returns the columns covered on their own by a unique index
+func (c sqliteCatalog) uniqueColumns(tableName string) (map[string]bool, error) {
+	indexes, err := queryMaps(c.db, fmt.Sprintf("PRAGMA index_list(%s)", quoteIdentifier(tableName)))
+	if err != nil {
+		return nil, err
+	}
+
+	uniques := make(map[string]bool)
+	for _, index := range indexes {
+		if fmt.Sprint(index["unique"]) != "1" {
+			continue
+		}
+		indexColumns, err := queryMaps(c.db, fmt.Sprintf("PRAGMA index_info(%s)", quoteIdentifier(fmt.Sprint(index["name"]))))
+		if err != nil {
+			return nil, err
+		}
+		if len(indexColumns) == 1 {
+			uniques[fmt.Sprint(indexColumns[0]["name"])] = true
+		}
+	}
+	return uniques, nil
+}
+
+// primaryKey returns the primary key columns of a table in key order, which
+// PRAGMA table_info gives as each column's 1-based position in the key
+func (c sqliteCatalog) primaryKey(tableName string) ([]string, error) {
+	info, err := c.tableInfo(tableName)
+	if err != nil {
+		return nil, err
+	}
+	var keyColumns []sqliteColumn
+	for _, col := range info {
+		if col.pk > 0 {
+			keyColumns = append(keyColumns, col)
+		}
+	}
+	sort.Slice(keyColumns, func(i, j int) bool { return keyColumns[i].pk < keyColumns[j].pk })
+
+	pk := make([]string, len(keyColumns))
+	for i, col := range keyColumns {
+		pk[i] = col.name
+	}
+	return pk, nil
+}
+
+// foreignKeys reads PRAGMA foreign_key_list, which lists the columns of each
+// key in order under a shared id; a reference without columns points at the
+// referenced table's primary key
+func (c sqliteCatalog) foreignKeys(tableName string) ([]ForeignKeyInfo, error) {
+	rows, err := queryMaps(c.db, fmt.Sprintf("PRAGMA foreign_key_list(%s)", quoteIdentifier(tableName)))
+	if err != nil {
+		return nil, err
+	}
+
+	var fks []ForeignKeyInfo
+	for _, row := range rows {
+		id := fmt.Sprint(row["id"])
+		if n := len(fks); n == 0 || fks[n-1].Name != id {
+			fks = append(fks, ForeignKeyInfo{Name: id, ReferencedTable: fmt.Sprint(row["table"])})
+		}
+		fk := &fks[len(fks)-1]
+		fk.Columns = append(fk.Columns, fmt.Sprint(row["from"]))
+		if to, ok := row["to"]; ok && to != nil {
+			fk.ReferencedColumns = append(fk.ReferencedColumns, fmt.Sprint(to))
+		}
+	}
+
+	for i := range fks {
+		if len(fks[i].ReferencedColumns) == len(fks[i].Columns) {
+			continue
+		}
+		if fks[i].ReferencedColumns, err = c.primaryKey(fks[i].ReferencedTable); err != nil {
+			return nil, err
+		}
+	}
+	return fks, nil
+}
+
+// relatedTables returns the tables tableName references and the tables that
+// reference it
+func (c sqliteCatalog) relatedTables(tableName string) ([]string, error) {
+	tables, err := c.tableNames()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var related []string
+	add := func(name string) {
+		if !strings.EqualFold(name, tableName) && !seen[strings.ToLower(name)] {
+			seen[strings.ToLower(name)] = true
+			related = append(related, name)
+		}
+	}
+	for _, table := range tables {
+		fks, err := c.foreignKeys(table)
+		if err != nil {
+			return nil, err
+		}
+		for _, fk := range fks {
+			switch {
+			case strings.EqualFold(table, tableName):
+				add(fk.ReferencedTable)
+			case strings.EqualFold(fk.ReferencedTable, tableName):
+				add(table)
+			}
+		}
+	}
+	return related, nil
+}
+
+// parseSQLiteType splits a declared column type such as VARCHAR(255) or
+// DECIMAL(10,2) into the lower-case type names the generator understands and
+// its length, precision and scale
+func parseSQLiteType(declType string) (typeName string, maxLength, precision, scale int) {
+	typeName = strings.ToLower(strings.TrimSpace(declType))
+	if open := strings.IndexByte(typeName, '('); open >= 0 {
+		args := strings.TrimSuffix(strings.TrimSpace(typeName[open+1:]), ")")
+		typeName = strings.TrimSpace(typeName[:open])
+		first, second, hasScale := strings.Cut(args, ",")
+		fmt.Sscanf(strings.TrimSpace(first), "%d", &precision)
+		if hasScale {
+			fmt.Sscanf(strings.TrimSpace(second), "%d", &scale)
+		} else {
+			maxLength = precision
+		}
+	}
+
+	switch typeName {
+	case "":
+		// Columns declared without a type accept anything; treat them as text
+		typeName = "text"
+	case "datetime":
+		typeName = "timestamp"
+	case "double":
+		typeName = "real"
+	}
+	if hasLength(typeName) {
+		precision = 0
+	} else {
+		maxLength = 0
+	}
+	return typeName, maxLength, precision, scale
+}
+
+// hasLength reports whether a type's single argument is a length rather than a precision
+func hasLength(typeName string) bool {
+	return strings.Contains(typeName, "char") || strings.Contains(typeName, "text")
+}
+
+// quoteIdentifier quotes a table or index name for use in a PRAGMA
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// queryMaps runs a query and returns its rows keyed by column name, which
+// tolerates PRAGMA results whose columns vary between SQLite versions
+func queryMaps(db *sql.DB, query string) ([]map[string]interface{}, error) {
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []map[string]interface{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			row[column] = scannedValue(values[i])
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,7 @@
+//go:build sqlite
+
+package generator
+
+// The pure-Go SQLite driver is opt-in so default builds don't compile it;
+// build with -tags sqlite to read SQLite files
+import _ "modernc.org/sqlite" // for sqlite
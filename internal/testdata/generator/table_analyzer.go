@@ -3,14 +3,20 @@ package generator
 import (
 	"database/sql"
 	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 )
 
 // TableInfo represents information about a database table
 type TableInfo struct {
-	Name        string
-	Columns     []ColumnInfo
-	PrimaryKey  string
+	Name    string
+	Columns []ColumnInfo
+	// PrimaryKey lists the key's columns in key order; a composite key has
+	// more than one
+	PrimaryKey  []string
 	ForeignKeys []ForeignKeyInfo
 }
 
@@ -37,21 +43,60 @@ type ColumnInfo struct {
 	Comment         string
 }
 
-// ForeignKeyInfo represents information about a foreign key relationship
+// ForeignKeyInfo represents a foreign key constraint. Columns and
+// ReferencedColumns pair up by position, so a composite key lists each of its
+// columns alongside the column it references.
 type ForeignKeyInfo struct {
-	Column           string
-	ReferencedTable  string
-	ReferencedColumn string
+	Name              string
+	Columns           []string
+	ReferencedTable   string
+	ReferencedColumns []string
+}
+
+// referencedColumn returns the column of the referenced table that column
+// points at, or "" when column is not part of the key
+func (fk ForeignKeyInfo) referencedColumn(column string) string {
+	for i, name := range fk.Columns {
+		if strings.EqualFold(name, column) && i < len(fk.ReferencedColumns) {
+			return fk.ReferencedColumns[i]
+		}
+	}
+	return ""
+}
+
+// schemaCatalog reads table structure from a database's system catalog,
+// which differs between information_schema databases and SQLite
+type schemaCatalog interface {
+	tableNames() ([]string, error)
+	// findTable returns the stored name of a table matched case-insensitively,
+	// or "" when there is no such table
+	findTable(name string) (string, error)
+	columns(tableName string) ([]ColumnInfo, error)
+	primaryKey(tableName string) ([]string, error)
+	foreignKeys(tableName string) ([]ForeignKeyInfo, error)
+	relatedTables(tableName string) ([]string, error)
 }
 
 // TableAnalyzer handles database schema analysis
 type TableAnalyzer struct {
-	db *sql.DB
+	db      *sql.DB
+	catalog schemaCatalog
 }
 
-// NewTableAnalyzer creates a new instance of TableAnalyzer
-func NewTableAnalyzer(db *sql.DB) *TableAnalyzer {
-	return &TableAnalyzer{db: db}
+// NewTableAnalyzer creates a new instance of TableAnalyzer for a database of
+// the given type (postgres, mysql, sqlserver or sqlite), limited to schema
+// when it is set
+func NewTableAnalyzer(db *sql.DB, dbType, schema string) *TableAnalyzer {
+	var catalog schemaCatalog = informationSchema{db: db, dbType: dbType, schema: schema}
+	switch dbType {
+	case "postgres":
+		catalog = postgresCatalog{informationSchema{db: db, dbType: dbType, schema: schema}}
+	case "mysql":
+		catalog = mysqlCatalog{informationSchema{db: db, dbType: dbType, schema: schema}}
+	case "sqlite":
+		catalog = sqliteCatalog{db: db}
+	}
+	return &TableAnalyzer{db: db, catalog: catalog}
 }
 
 // AnalyzeTables analyzes all tables in the database
@@ -59,7 +104,7 @@ func (ta *TableAnalyzer) AnalyzeTables() (map[string]TableInfo, error) {
 	tables := make(map[string]TableInfo)
 
 	// Get list of tables
-	tableNames, err := ta.getTableNames()
+	tableNames, err := ta.catalog.tableNames()
 	if err != nil {
 		return nil, err
 	}
@@ -76,30 +121,15 @@ func (ta *TableAnalyzer) AnalyzeTables() (map[string]TableInfo, error) {
 	return tables, nil
 }
 
-// getTableNames retrieves all table names from the database
-func (ta *TableAnalyzer) getTableNames() ([]string, error) {
-	var tables []string
-	query := `
-		SELECT LOWER(table_name) 
-		FROM information_schema.tables 
-		WHERE table_schema = 'public'
-		AND table_type = 'BASE TABLE'
-	`
-	rows, err := ta.db.Query(query)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var tableName string
-		if err := rows.Scan(&tableName); err != nil {
-			return nil, err
-		}
-		tables = append(tables, tableName)
-	}
+// FindTable returns the stored name of tableName matched case-insensitively,
+// or "" when the database has no such table
+func (ta *TableAnalyzer) FindTable(tableName string) (string, error) {
+	return ta.catalog.findTable(tableName)
+}
 
-	return tables, nil
+// FindRelatedTables finds tables related to a given table through foreign keys
+func (ta *TableAnalyzer) FindRelatedTables(tableName string) ([]string, error) {
+	return ta.catalog.relatedTables(tableName)
 }
 
 // analyzeTable analyzes a single table's structure
@@ -109,21 +139,21 @@ func (ta *TableAnalyzer) analyzeTable(tableName string) (TableInfo, error) {
 	}
 
 	// Get column information
-	columns, err := ta.getColumnInfo(tableName)
+	columns, err := ta.catalog.columns(tableName)
 	if err != nil {
 		return info, err
 	}
 	info.Columns = columns
 
 	// Get primary key
-	pk, err := ta.getPrimaryKey(tableName)
+	pk, err := ta.catalog.primaryKey(tableName)
 	if err != nil {
 		return info, err
 	}
 	info.PrimaryKey = pk
 
 	// Get foreign keys
-	fks, err := ta.getForeignKeys(tableName)
+	fks, err := ta.catalog.foreignKeys(tableName)
 	if err != nil {
 		return info, err
 	}
@@ -132,10 +162,79 @@ func (ta *TableAnalyzer) analyzeTable(tableName string) (TableInfo, error) {
 	return info, nil
 }
 
-// getColumnInfo retrieves column information for a table
-func (ta *TableAnalyzer) getColumnInfo(tableName string) ([]ColumnInfo, error) {
+// informationSchema reads the catalog of databases with information_schema views
+type informationSchema struct {
+	db *sql.DB
+	// dbType picks the driver's bind parameter syntax
+	dbType string
+	// schema restricts lookups to one schema; empty searches them all
+	schema string
+}
+
+// inSchema returns the condition restricting column to the configured schema,
+// numbered after args, and args extended with the schema. Without a schema
+// the condition is empty.
+func (c informationSchema) inSchema(column string, args ...interface{}) (string, []interface{}) {
+	if c.schema == "" {
+		return "", args
+	}
+	return fmt.Sprintf("AND %s = %s", column, c.param(len(args)+1)), append(args, c.schema)
+}
+
+// param returns the bind parameter for the nth query argument in the
+// driver's syntax
+func (c informationSchema) param(n int) string {
+	return placeholder(c.dbType, n)
+}
+
+// tableNames retrieves all table names from the database
+func (c informationSchema) tableNames() ([]string, error) {
+	var tables []string
+	filter, args := c.inSchema("table_schema")
+	query := fmt.Sprintf(`
+		SELECT LOWER(table_name) 
+		FROM information_schema.tables 
+		WHERE table_type = 'BASE TABLE'
+		%s
+	`, filter)
+	rows, err := c.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tableName string
+		if err := rows.Scan(&tableName); err != nil {
+			return nil, err
+		}
+		tables = append(tables, tableName)
+	}
+
+	return tables, nil
+}
+
+// findTable looks a table up by name, ignoring case
+func (c informationSchema) findTable(name string) (string, error) {
+	filter, args := c.inSchema("table_schema", name)
+	query := selectFirst(c.dbType, "table_name", fmt.Sprintf(`
+		information_schema.tables 
+		WHERE LOWER(table_name) = LOWER(%s)
+		%s
+	`, c.param(1), filter))
+	var tableName string
+	err := c.db.QueryRow(query, args...).Scan(&tableName)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return tableName, err
+}
+
+// columns retrieves column information for a table
+func (c informationSchema) columns(tableName string) ([]ColumnInfo, error) {
 	var columns []ColumnInfo
-	query := `
+	filter, args := c.inSchema("c.table_schema", tableName)
+	query := fmt.Sprintf(`
 		SELECT 
 			c.column_name,
 			c.data_type,
@@ -145,10 +244,11 @@ func (ta *TableAnalyzer) getColumnInfo(tableName string) ([]ColumnInfo, error) {
 			c.numeric_precision,
 			c.numeric_scale
 		FROM information_schema.columns c
-		WHERE LOWER(c.table_name) = LOWER($1)
+		WHERE LOWER(c.table_name) = LOWER(%s)
+		%s
 		ORDER BY c.column_name
-	`
-	rows, err := ta.db.Query(query, tableName)
+	`, c.param(1), filter)
+	rows, err := c.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -186,65 +286,85 @@ func (ta *TableAnalyzer) getColumnInfo(tableName string) ([]ColumnInfo, error) {
 		columns = append(columns, col)
 	}
 
-	// Get primary key information
-	pkQuery := `
+	// Constraint lookups filter on the constraint's table schema
+	tcFilter, tcArgs := c.inSchema("tc.table_schema", tableName)
+
+	// Get primary key information; the columns of a composite key are only
+	// unique together, so just a single-column key marks its column unique
+	pkQuery := fmt.Sprintf(`
 		SELECT kcu.column_name
 		FROM information_schema.table_constraints tc
 		JOIN information_schema.key_column_usage kcu
 			ON tc.constraint_name = kcu.constraint_name
 		WHERE tc.constraint_type = 'PRIMARY KEY'
-		AND LOWER(tc.table_name) = LOWER($1)
-	`
-	rows, err = ta.db.Query(pkQuery, tableName)
+		AND LOWER(tc.table_name) = LOWER(%s)
+		%s
+	`, c.param(1), tcFilter)
+	rows, err = c.db.Query(pkQuery, tcArgs...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	var pkColumns []string
 	for rows.Next() {
 		var pkColumn string
 		if err := rows.Scan(&pkColumn); err != nil {
 			return nil, err
 		}
+		pkColumns = append(pkColumns, pkColumn)
+	}
+	for _, pkColumn := range pkColumns {
 		// Mark column as primary key
 		for i := range columns {
 			if columns[i].Name == pkColumn {
 				columns[i].IsPrimary = true
+				columns[i].IsUnique = len(pkColumns) == 1
 				break
 			}
 		}
 	}
 
-	// Get foreign key information
-	fkQuery := `
-		SELECT
-			kcu.column_name,
-			ccu.table_name AS foreign_table_name,
-			ccu.column_name AS foreign_column_name
-		FROM information_schema.table_constraints AS tc
-		JOIN information_schema.key_column_usage AS kcu
+	// Get unique constraint information
+	uniqueQuery := fmt.Sprintf(`
+		SELECT kcu.column_name
+		FROM information_schema.table_constraints tc
+		JOIN information_schema.key_column_usage kcu
 			ON tc.constraint_name = kcu.constraint_name
-		JOIN information_schema.constraint_column_usage AS ccu
-			ON ccu.constraint_name = tc.constraint_name
-		WHERE tc.constraint_type = 'FOREIGN KEY'
-		AND LOWER(tc.table_name) = LOWER($1)
-	`
-	rows, err = ta.db.Query(fkQuery, tableName)
+		WHERE tc.constraint_type = 'UNIQUE'
+		AND LOWER(tc.table_name) = LOWER(%s)
+		%s
+	`, c.param(1), tcFilter)
+	rows, err = c.db.Query(uniqueQuery, tcArgs...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var fkColumn, refTable, refColumn string
-		if err := rows.Scan(&fkColumn, &refTable, &refColumn); err != nil {
+		var uniqueColumn string
+		if err := rows.Scan(&uniqueColumn); err != nil {
 			return nil, err
 		}
-		// Mark column as foreign key
+		// Mark column as unique
 		for i := range columns {
-			if columns[i].Name == fkColumn {
+			if columns[i].Name == uniqueColumn {
+				columns[i].IsUnique = true
+				break
+			}
+		}
+	}
+
+	// Mark foreign key columns with the table they reference
+	fks, err := c.foreignKeys(tableName)
+	if err != nil {
+		return nil, err
+	}
+	for i := range columns {
+		for _, fk := range fks {
+			if fk.referencedColumn(columns[i].Name) != "" {
 				columns[i].IsForeign = true
-				columns[i].References = refTable
+				columns[i].References = fk.ReferencedTable
 				break
 			}
 		}
@@ -253,131 +373,201 @@ func (ta *TableAnalyzer) getColumnInfo(tableName string) ([]ColumnInfo, error) {
 	return columns, nil
 }
 
-// parseCheckConstraint extracts min/max values from check constraints
-func parseCheckConstraint(constraint string) (min, max interface{}) {
-	constraint = strings.ToLower(constraint)
+// checkCast matches type casts such as ::text or ::character varying[] in
+// constraint definitions
+var checkCast = regexp.MustCompile(`::[a-z_]+( varying| precision| with(out)? time zone)?(\[\])?`)
+
+// checkLiteral matches a quoted string in a constraint definition
+var checkLiteral = regexp.MustCompile(`'((?:[^']|'')*)'`)
+
+// checkNumber matches a numeric literal
+const checkNumber = `(-?\d+(?:\.\d+)?)`
+
+// parseCheckConstraint extracts the allowed values of column from a CHECK
+// clause: the literals of an IN list or = ANY (ARRAY[...]), and the bounds of
+// comparisons and BETWEEN against numbers. Strict bounds are tightened, by one
+// for whole numbers and by a digit past the last decimal otherwise, so any
+// value in [min, max] satisfies the constraint.
+func parseCheckConstraint(column, constraint string) (enum []string, min, max interface{}) {
+	clause := strings.ToLower(constraint)
+	clause = checkCast.ReplaceAllString(clause, "")
+	clause = strings.NewReplacer("(", " ", ")", " ", `"`, "", "`", "").Replace(clause)
+	name := regexp.QuoteMeta(strings.ToLower(column))
+
+	// Enumerations: col IN ('a', 'b') or col = ANY ARRAY['a', 'b']
+	if regexp.MustCompile(`\b` + name + `\s+(in\s|=\s*any\s)`).MatchString(clause) {
+		for _, match := range checkLiteral.FindAllStringSubmatch(constraint, -1) {
+			enum = append(enum, strings.ReplaceAll(match[1], "''", "'"))
+		}
+		return enum, nil, nil
+	}
 
-	// Handle range constraints
-	if strings.Contains(constraint, "between") {
-		var minVal, maxVal float64
-		fmt.Sscanf(constraint, "check (%s between %f and %f)", &minVal, &maxVal)
-		return minVal, maxVal
+	if m := regexp.MustCompile(`\b` + name + `\s+between\s+` + checkNumber + `\s+and\s+` + checkNumber).FindStringSubmatch(clause); m != nil {
+		return nil, parseBound(m[1], 0), parseBound(m[2], 0)
 	}
 
-	// Handle >= and <= constraints
-	if strings.Contains(constraint, ">=") {
-		var minVal float64
-		fmt.Sscanf(constraint, "check (%s >= %f)", &minVal)
-		return minVal, nil
+	var lower, upper interface{}
+	forward := regexp.MustCompile(`\b` + name + `\s*(>=|>|<=|<)\s*` + checkNumber)
+	for _, m := range forward.FindAllStringSubmatch(clause, -1) {
+		applyBound(m[1], m[2], &lower, &upper)
 	}
-	if strings.Contains(constraint, "<=") {
-		var maxVal float64
-		fmt.Sscanf(constraint, "check (%s <= %f)", &maxVal)
-		return nil, maxVal
+	// Reversed comparisons such as 0 <= col flip the operator
+	reversed := regexp.MustCompile(checkNumber + `\s*(>=|>|<=|<)\s*` + name + `\b`)
+	flip := map[string]string{">=": "<=", ">": "<", "<=": ">=", "<": ">"}
+	for _, m := range reversed.FindAllStringSubmatch(clause, -1) {
+		applyBound(flip[m[2]], m[1], &lower, &upper)
 	}
+	return nil, lower, upper
+}
 
-	// Handle pattern constraints
-	if strings.Contains(constraint, "like") {
-		pattern := strings.Trim(constraint, "'")
-		return nil, pattern
+// applyBound records the bound a comparison operator places on a column
+func applyBound(operator, number string, lower, upper *interface{}) {
+	switch operator {
+	case ">=":
+		*lower = parseBound(number, 0)
+	case ">":
+		*lower = parseBound(number, 1)
+	case "<=":
+		*upper = parseBound(number, 0)
+	case "<":
+		*upper = parseBound(number, -1)
 	}
+}
 
-	return nil, nil
+// parseBound parses a numeric bound, moving it by step to turn a strict
+// comparison into an inclusive one: whole numbers by step, and fractions by
+// step in the decimal place after their last digit, so > 0.5 becomes >= 0.51
+func parseBound(number string, step float64) interface{} {
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return nil
+	}
+	if step == 0 || value == math.Trunc(value) {
+		return value + step
+	}
+	_, fraction, _ := strings.Cut(number, ".")
+	unit := math.Pow(10, -float64(len(fraction)+1))
+	return math.Round((value+step*unit)/unit) * unit
 }
 
-// getPrimaryKey retrieves the primary key for a table
-func (ta *TableAnalyzer) getPrimaryKey(tableName string) (string, error) {
-	query := `
+// primaryKey retrieves the primary key columns of a table in key order
+func (c informationSchema) primaryKey(tableName string) ([]string, error) {
+	filter, args := c.inSchema("tc.table_schema", tableName)
+	query := fmt.Sprintf(`
 		SELECT kcu.column_name
 		FROM information_schema.table_constraints tc
 		JOIN information_schema.key_column_usage kcu
 			ON tc.constraint_name = kcu.constraint_name
+			AND tc.table_schema = kcu.table_schema
 		WHERE tc.constraint_type = 'PRIMARY KEY'
-		AND LOWER(tc.table_name) = LOWER($1)
-	`
-	var pk string
-	err := ta.db.QueryRow(query, tableName).Scan(&pk)
-	if err == sql.ErrNoRows {
-		return "", nil
-	}
+		AND LOWER(tc.table_name) = LOWER(%s)
+		%s
+		ORDER BY kcu.ordinal_position
+	`, c.param(1), filter)
+	rows, err := c.db.Query(query, args...)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pk []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		pk = append(pk, column)
 	}
-	return pk, nil
+	return pk, rows.Err()
 }
 
-// getForeignKeys retrieves foreign key information for a table
-func (ta *TableAnalyzer) getForeignKeys(tableName string) ([]ForeignKeyInfo, error) {
-	var fks []ForeignKeyInfo
-	query := `
-		SELECT
-			kcu.column_name,
-			ccu.table_name AS foreign_table_name,
-			ccu.column_name AS foreign_column_name,
-			rc.update_rule,
-			rc.delete_rule
-		FROM information_schema.table_constraints AS tc
-		JOIN information_schema.key_column_usage AS kcu
-			ON tc.constraint_name = kcu.constraint_name
-		JOIN information_schema.constraint_column_usage AS ccu
-			ON ccu.constraint_name = tc.constraint_name
-		JOIN information_schema.referential_constraints AS rc
-			ON rc.constraint_name = tc.constraint_name
-		WHERE tc.constraint_type = 'FOREIGN KEY'
-		AND LOWER(tc.table_name) = LOWER($1)
-	`
-	rows, err := ta.db.Query(query, tableName)
+// foreignKeys retrieves the foreign key constraints of a table. Each column
+// is paired with the referenced column at the same position of the unique
+// constraint it points at, which keeps composite keys in order.
+func (c informationSchema) foreignKeys(tableName string) ([]ForeignKeyInfo, error) {
+	query, args := c.foreignKeyQuery(tableName)
+	rows, err := c.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	var fks []ForeignKeyInfo
 	for rows.Next() {
-		var fk ForeignKeyInfo
-		var updateRule, deleteRule string
-		if err := rows.Scan(
-			&fk.Column,
-			&fk.ReferencedTable,
-			&fk.ReferencedColumn,
-			&updateRule,
-			&deleteRule,
-		); err != nil {
+		var name, column, refTable, refColumn string
+		if err := rows.Scan(&name, &column, &refTable, &refColumn); err != nil {
 			return nil, err
 		}
-		fks = append(fks, fk)
+		if n := len(fks); n > 0 && fks[n-1].Name == name {
+			fks[n-1].Columns = append(fks[n-1].Columns, column)
+			fks[n-1].ReferencedColumns = append(fks[n-1].ReferencedColumns, refColumn)
+			continue
+		}
+		fks = append(fks, ForeignKeyInfo{
+			Name:              name,
+			Columns:           []string{column},
+			ReferencedTable:   refTable,
+			ReferencedColumns: []string{refColumn},
+		})
 	}
 
-	return fks, nil
+	return fks, rows.Err()
 }
 
-// FindRelatedTables finds tables related to a given table through foreign keys
-func (ta *TableAnalyzer) FindRelatedTables(tableName string) ([]string, error) {
-	var relatedTables []string
-	query := `
-		SELECT DISTINCT ccu.table_name
+// foreignKeyQuery returns the query listing a table's foreign key columns as
+// constraint name, column, referenced table and referenced column
+func (c informationSchema) foreignKeyQuery(tableName string) (string, []interface{}) {
+	if c.dbType == "mysql" {
+		// MySQL names every primary key PRIMARY, so the unique constraint's
+		// name doesn't identify the referenced table; key_column_usage
+		// names the referenced column itself
+		filter, args := c.inSchema("table_schema", tableName)
+		return fmt.Sprintf(`
+			SELECT constraint_name, column_name, referenced_table_name, referenced_column_name
+			FROM information_schema.key_column_usage
+			WHERE referenced_table_name IS NOT NULL
+			AND LOWER(table_name) = LOWER(%s)
+			%s
+			ORDER BY constraint_name, ordinal_position
+		`, c.param(1), filter), args
+	}
+
+	filter, args := c.inSchema("tc.table_schema", tableName)
+	return fmt.Sprintf(`
+		SELECT
+			tc.constraint_name,
+			kcu.column_name,
+			ref.table_name AS foreign_table_name,
+			ref.column_name AS foreign_column_name
 		FROM information_schema.table_constraints AS tc
 		JOIN information_schema.key_column_usage AS kcu
 			ON tc.constraint_name = kcu.constraint_name
-		JOIN information_schema.constraint_column_usage AS ccu
-			ON ccu.constraint_name = tc.constraint_name
+			AND tc.table_schema = kcu.table_schema
+		JOIN information_schema.referential_constraints AS rc
+			ON rc.constraint_name = tc.constraint_name
+			AND rc.constraint_schema = tc.table_schema
+		JOIN information_schema.key_column_usage AS ref
+			ON ref.constraint_name = rc.unique_constraint_name
+			AND ref.constraint_schema = rc.unique_constraint_schema
+			AND ref.ordinal_position = kcu.position_in_unique_constraint
 		WHERE tc.constraint_type = 'FOREIGN KEY'
-		AND (tc.table_name = $1 OR ccu.table_name = $1)
-	`
-	rows, err := ta.db.Query(query, tableName)
+		AND LOWER(tc.table_name) = LOWER(%s)
+		%s
+		ORDER BY tc.constraint_name, kcu.ordinal_position
+	`, c.param(1), filter), args
+}
+
+// relatedTables returns the tables a table references through its foreign keys
+func (c informationSchema) relatedTables(tableName string) ([]string, error) {
+	fks, err := c.foreignKeys(tableName)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var relatedTable string
-		if err := rows.Scan(&relatedTable); err != nil {
-			return nil, err
-		}
-		if relatedTable != tableName {
-			relatedTables = append(relatedTables, relatedTable)
+	var relatedTables []string
+	for _, fk := range fks {
+		if fk.ReferencedTable != tableName && !slices.Contains(relatedTables, fk.ReferencedTable) {
+			relatedTables = append(relatedTables, fk.ReferencedTable)
 		}
 	}
-
 	return relatedTables, nil
 }
@@ -0,0 +1,48 @@
+package generator
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParseCheckConstraint(t *testing.T) {
+	tests := []struct {
+		name       string
+		column     string
+		constraint string
+		enum       []string
+		min, max   interface{}
+	}{
+		{"postgres between", "age", "CHECK ((age >= 18) AND (age <= 99))", nil, 18.0, 99.0},
+		{"postgres any array", "status", "CHECK (((status)::text = ANY ((ARRAY['active'::character varying, 'inactive'::character varying])::text[])))", []string{"active", "inactive"}, nil, nil},
+		{"strict whole bounds", "quantity", "CHECK (quantity > 0 AND quantity < 10)", nil, 1.0, 9.0},
+		{"strict fractional bounds", "rate", "CHECK (rate > 0.5 AND rate < 2.25)", nil, 0.51, 2.249},
+		{"reversed strict fractional bound", "rate", "CHECK (0.5 < rate)", nil, 0.51, nil},
+		{"mysql between", "age", "(`age` between 18 and 99)", nil, 18.0, 99.0},
+		{"mysql in list", "status", "(`status` in (_utf8mb4'active',_utf8mb4'on hold'))", []string{"active", "on hold"}, nil, nil},
+		{"mysql comparison", "price", "(`price` >= 0.01)", nil, 0.01, nil},
+		{"other column", "age", "(`score` > 3)", nil, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enum, min, max := parseCheckConstraint(tt.column, tt.constraint)
+			if !reflect.DeepEqual(enum, tt.enum) {
+				t.Errorf("enum = %v, want %v", enum, tt.enum)
+			}
+			if !boundEqual(min, tt.min) || !boundEqual(max, tt.max) {
+				t.Errorf("bounds = [%v, %v], want [%v, %v]", min, max, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+// boundEqual compares parsed bounds, allowing for float rounding
+func boundEqual(got, want interface{}) bool {
+	if got == nil || want == nil {
+		return got == want
+	}
+	g, ok := got.(float64)
+	return ok && math.Abs(g-want.(float64)) < 1e-9
+}
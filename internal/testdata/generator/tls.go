@@ -0,0 +1,135 @@
+package generator
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// SSL modes accepted by DBConfig.SSLMode, named after Postgres' sslmode
+const (
+	// SSLDisable connects without TLS
+	SSLDisable = "disable"
+	// SSLRequire encrypts the connection without verifying the certificate
+	SSLRequire = "require"
+	// SSLVerifyCA also checks the certificate is signed by a trusted CA
+	SSLVerifyCA = "verify-ca"
+	// SSLVerifyFull also checks the certificate matches the host name
+	SSLVerifyFull = "verify-full"
+)
+
+// SSLModes lists the supported SSL modes
+var SSLModes = []string{SSLDisable, SSLRequire, SSLVerifyCA, SSLVerifyFull}
+
+// mysqlTLSConfigName is the name the custom CA configuration is registered
+// under with the MySQL driver
+const mysqlTLSConfigName = "auto-api-tester"
+
+// validateSSL checks the SSL mode and that the CA certificate can be read
+func validateSSL(config DBConfig) error {
+	switch config.SSLMode {
+	case SSLDisable, SSLRequire, SSLVerifyCA, SSLVerifyFull:
+	default:
+		return fmt.Errorf("unknown ssl mode %q (want %s)", config.SSLMode, strings.Join(SSLModes, ", "))
+	}
+	if config.SSLRootCert != "" {
+		if _, err := os.Stat(config.SSLRootCert); err != nil {
+			return fmt.Errorf("ssl root certificate: %v", err)
+		}
+	}
+	return nil
+}
+
+// postgresSSLParams returns the sslmode and sslrootcert DSN parameters
+func postgresSSLParams(config DBConfig) string {
+	params := " sslmode=" + config.SSLMode
+	if config.SSLRootCert != "" {
+		params += " sslrootcert=" + quotePostgresValue(config.SSLRootCert)
+	}
+	return params
+}
+
+// quotePostgresValue quotes a key/value DSN value so paths with spaces survive
+func quotePostgresValue(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+// mysqlTLSParam returns the value of the MySQL DSN's tls parameter. Verifying
+// against a CA certificate registers a TLS configuration with the driver.
+func mysqlTLSParam(config DBConfig) (string, error) {
+	switch config.SSLMode {
+	case SSLDisable:
+		return "false", nil
+	case SSLRequire:
+		return "skip-verify", nil
+	}
+	if config.SSLRootCert == "" {
+		if config.SSLMode == SSLVerifyCA {
+			return "", fmt.Errorf("ssl mode verify-ca needs a CA certificate for mysql")
+		}
+		return "true", nil
+	}
+
+	pem, err := os.ReadFile(config.SSLRootCert)
+	if err != nil {
+		return "", fmt.Errorf("failed to read ssl root certificate: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return "", fmt.Errorf("no certificates found in %s", config.SSLRootCert)
+	}
+	tlsConfig := &tls.Config{RootCAs: pool, ServerName: config.Host}
+	if config.SSLMode == SSLVerifyCA {
+		// Check the chain but not the host name, as Postgres' verify-ca does
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyPeerCertificate = verifyChain(pool)
+	}
+	if err := mysql.RegisterTLSConfig(mysqlTLSConfigName, tlsConfig); err != nil {
+		return "", err
+	}
+	return mysqlTLSConfigName, nil
+}
+
+// verifyChain checks that the server's certificate chains to a CA in pool,
+// without matching the host name
+func verifyChain(pool *x509.CertPool) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("server sent no certificate")
+		}
+		certs := make([]*x509.Certificate, len(rawCerts))
+		for i, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return err
+			}
+			certs[i] = cert
+		}
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+		_, err := certs[0].Verify(x509.VerifyOptions{Roots: pool, Intermediates: intermediates})
+		return err
+	}
+}
+
+// sqlServerSSLParams returns the encrypt and certificate DSN parameters
+func sqlServerSSLParams(config DBConfig) string {
+	switch config.SSLMode {
+	case SSLDisable:
+		return ";encrypt=disable"
+	case SSLRequire:
+		return ";encrypt=true;TrustServerCertificate=true"
+	}
+	// The driver always checks the host name, so verify-ca acts as verify-full
+	params := ";encrypt=true"
+	if config.SSLRootCert != "" {
+		params += ";certificate=" + config.SSLRootCert
+	}
+	return params
+}
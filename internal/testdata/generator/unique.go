@@ -0,0 +1,175 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxUniqueAttempts is how often a colliding value is regenerated before a
+// suffix is used to make it distinct
+const maxUniqueAttempts = 10
+
+// uniqueTracker records the values handed out for unique columns while a
+// single body is generated, so the items of a bulk payload never collide.
+// A composite primary key is tracked as a whole, since only the combination
+// of its columns has to be unique.
+type uniqueTracker struct {
+	columns map[string]ColumnInfo
+	seen    map[string]map[string]bool
+	key     []ColumnInfo
+	seenKey map[string]bool
+}
+
+// newUniqueTracker creates a tracker for the unique columns of a table
+func newUniqueTracker(table TableInfo) *uniqueTracker {
+	tracker := &uniqueTracker{
+		columns: make(map[string]ColumnInfo),
+		seen:    make(map[string]map[string]bool),
+		seenKey: make(map[string]bool),
+	}
+	for _, col := range table.Columns {
+		if col.IsUnique {
+			tracker.columns[strings.ToLower(col.Name)] = col
+		}
+	}
+	if len(table.PrimaryKey) > 1 {
+		for _, name := range table.PrimaryKey {
+			for _, col := range table.Columns {
+				if strings.EqualFold(col.Name, name) {
+					tracker.key = append(tracker.key, col)
+				}
+			}
+		}
+	}
+	return tracker
+}
+
+// ensureUniqueKey makes the composite primary key of obj differ from those of
+// earlier items by changing its last column that is not a foreign key, as
+// foreign key values must keep pointing at an existing row. Objects missing a
+// key column are left alone.
+func (g *DBGenerator) ensureUniqueKey(tracker *uniqueTracker, obj map[string]interface{}) {
+	if tracker == nil || len(tracker.key) == 0 {
+		return
+	}
+	fields := make([]string, len(tracker.key))
+	for i, col := range tracker.key {
+		for field := range obj {
+			if strings.EqualFold(field, col.Name) {
+				fields[i] = field
+			}
+		}
+		if fields[i] == "" {
+			return
+		}
+	}
+
+	tuple := func() string {
+		values := make([]string, len(fields))
+		for i, field := range fields {
+			values[i] = fmt.Sprint(obj[field])
+		}
+		return strings.Join(values, "\x00")
+	}
+
+	if tracker.seenKey[tuple()] {
+		changed := false
+		for i := len(tracker.key) - 1; i >= 0 && !changed; i-- {
+			if tracker.key[i].IsForeign {
+				continue
+			}
+			for n := len(tracker.seenKey); tracker.seenKey[tuple()]; n++ {
+				obj[fields[i]] = makeDistinct(obj[fields[i]], n)
+			}
+			changed = true
+		}
+		if !changed {
+			fmt.Printf("Warning: Items repeat the primary key (%s), whose columns are all foreign keys\n", strings.Join(fields, ", "))
+		}
+	}
+	tracker.seenKey[tuple()] = true
+}
+
+// ensureUnique returns the value unchanged unless the field maps to a unique
+// column that already used it in this body, in which case a new value is generated
+func (g *DBGenerator) ensureUnique(tracker *uniqueTracker, field string, value interface{}) (interface{}, error) {
+	if tracker == nil || value == nil {
+		return value, nil
+	}
+	key := strings.ToLower(field)
+	col, ok := tracker.columns[key]
+	if !ok {
+		return value, nil
+	}
+
+	used := tracker.seen[key]
+	if used == nil {
+		used = make(map[string]bool)
+		tracker.seen[key] = used
+	}
+
+	for attempt := 0; used[fmt.Sprint(value)] && attempt < maxUniqueAttempts; attempt++ {
+		generated, err := g.generateValueForType(col.Type, false, col.Name, col)
+		if err != nil {
+			return nil, err
+		}
+		value = generated
+	}
+	if used[fmt.Sprint(value)] {
+		value = makeDistinct(value, len(used))
+	}
+
+	used[fmt.Sprint(value)] = true
+	return value, nil
+}
+
+// makeDistinct derives a new value from one that keeps colliding
+func makeDistinct(value interface{}, n int) interface{} {
+	switch v := value.(type) {
+	case int:
+		return v + n
+	case int64:
+		return v + int64(n)
+	case float64:
+		return v + float64(n)
+	case string:
+		// Keep email addresses valid by suffixing the local part
+		if at := strings.Index(v, "@"); at > 0 {
+			return fmt.Sprintf("%s_%d%s", v[:at], n, v[at:])
+		}
+		return fmt.Sprintf("%s_%d", v, n)
+	default:
+		return fmt.Sprintf("%v_%d", v, n)
+	}
+}
+
+// enforceUniqueItems keeps unique columns distinct across the items of an
+// array body, leaving single-object bodies untouched
+func (g *DBGenerator) enforceUniqueItems(tableName string, body interface{}) (interface{}, error) {
+	items, ok := body.([]interface{})
+	if !ok || len(items) < 2 {
+		return body, nil
+	}
+
+	tableInfo, err := g.analyzer.analyzeTable(tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze table %s: %v", tableName, err)
+	}
+	tracker := newUniqueTracker(tableInfo)
+
+	for _, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, field := range sortedKeys(obj) {
+			unique, err := g.ensureUnique(tracker, field, obj[field])
+			if err != nil {
+				return nil, err
+			}
+			obj[field] = unique
+		}
+		g.ensureUniqueKey(tracker, obj)
+	}
+	return items, nil
+}
@@ -0,0 +1,88 @@
+package testdata
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"auto-api-tester/internal/types"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// maxHintDepth stops hints from expanding deeply nested or recursive schemas
+const maxHintDepth = 4
+
+// responseHints summarizes an endpoint's documented JSON responses, keyed by
+// status code ("default" for the spec's default response)
+func responseHints(endpoint types.Endpoint) map[string]interface{} {
+	hints := make(map[string]interface{})
+	for code, response := range endpoint.Responses {
+		if response.Schema == nil {
+			continue
+		}
+		key := strconv.Itoa(code)
+		if code == types.DefaultResponse {
+			key = "default"
+		}
+		hints[key] = schemaHint(response.Schema, 0)
+	}
+	if len(hints) == 0 {
+		return nil
+	}
+	return hints
+}
+
+// schemaHint reduces a schema to a compact example of its shape: objects map
+// their properties to hints, arrays hold their item hint and scalars become
+// their type name, e.g. {"id": "integer", "tags": ["string"]}
+func schemaHint(schema interface{}, depth int) interface{} {
+	var s *openapi3.Schema
+	switch v := schema.(type) {
+	case *openapi3.SchemaRef:
+		if v == nil {
+			return nil
+		}
+		s = v.Value
+	case *openapi3.Schema:
+		s = v
+	}
+	if s == nil {
+		return nil
+	}
+	if depth >= maxHintDepth {
+		return "..."
+	}
+
+	switch {
+	case s.Type != nil && s.Type.Is("object") || len(s.Properties) > 0:
+		properties := make(map[string]interface{}, len(s.Properties))
+		for name, prop := range s.Properties {
+			properties[name] = schemaHint(prop, depth+1)
+		}
+		return properties
+	case s.Type != nil && s.Type.Is("array"):
+		if s.Items == nil {
+			return []interface{}{}
+		}
+		return []interface{}{schemaHint(s.Items, depth+1)}
+	case len(s.Enum) > 0:
+		values := make([]string, 0, len(s.Enum))
+		for _, value := range s.Enum {
+			values = append(values, fmt.Sprint(value))
+		}
+		return "enum(" + strings.Join(values, "|") + ")"
+	}
+
+	name := "any"
+	if s.Type != nil && len(*s.Type) > 0 {
+		name = strings.Join(*s.Type, "|")
+	}
+	if s.Format != "" {
+		name += "(" + s.Format + ")"
+	}
+	if s.Nullable {
+		name += "?"
+	}
+	return name
+}
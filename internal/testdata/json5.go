@@ -0,0 +1,162 @@
+package testdata
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"auto-api-tester/internal/types"
+)
+
+// stripJSON5 reduces the JSON5 subset used by grouped templates - // and /* */
+// comments and trailing commas - to plain JSON. Plain JSON passes through unchanged.
+func stripJSON5(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if inString {
+			out = append(out, c)
+			switch c {
+			case '\\':
+				if i+1 < len(data) {
+					i++
+					out = append(out, data[i])
+				}
+			case '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch {
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				out = append(out, '\n')
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := strings.Index(string(data[i+2:]), "*/")
+			if end < 0 {
+				return out
+			}
+			i += end + 3
+		case c == ',':
+			// Drop a comma that only precedes whitespace, comments and a closing bracket
+			if next := nextSignificant(data[i+1:]); next != '}' && next != ']' {
+				out = append(out, c)
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// nextSignificant returns the first byte of data that is not whitespace or
+// part of a comment, or zero at the end of data
+func nextSignificant(data []byte) byte {
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := strings.Index(string(data[i+2:]), "*/")
+			if end < 0 {
+				return 0
+			}
+			i += end + 3
+		default:
+			return c
+		}
+	}
+	return 0
+}
+
+// untaggedGroup collects the operations without a tag
+const untaggedGroup = "untagged"
+
+// generateGroupedTemplate writes testdata_template.json5 with the entries
+// nested under their operation's first tag, with the summary and the
+// parameter descriptions as comments
+func (g *Generator) generateGroupedTemplate(endpoints []types.Endpoint) error {
+	type groupedEntry struct {
+		key     string
+		summary string
+		params  []parameterDoc
+		data    templateCases
+	}
+	groups := make(map[string][]groupedEntry)
+	for _, endpoint := range endpoints {
+		group := untaggedGroup
+		if len(endpoint.Tags) > 0 && endpoint.Tags[0] != "" {
+			group = endpoint.Tags[0]
+		}
+		groups[group] = append(groups[group], groupedEntry{
+			key:     fmt.Sprintf("%s %s", endpoint.Method, endpoint.Path),
+			summary: endpoint.Summary,
+			params:  parameterDocs(endpoint),
+			data:    g.endpointTestCases(endpoint),
+		})
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("{\n")
+	if baseURL := templateBaseURL(endpoints); baseURL != "" {
+		encoded, _ := json.Marshal(baseURL)
+		fmt.Fprintf(&b, "  \"base_url\": %s,\n", encoded)
+	}
+	b.WriteString("  \"groups\": {\n")
+	for _, name := range names {
+		entries := groups[name]
+		sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+
+		groupKey, _ := json.Marshal(name)
+		fmt.Fprintf(&b, "    %s: {\n", groupKey)
+		for _, entry := range entries {
+			if summary := strings.Join(strings.Fields(entry.summary), " "); summary != "" {
+				fmt.Fprintf(&b, "      // %s\n", summary)
+			}
+			for _, param := range entry.params {
+				fmt.Fprintf(&b, "      //   %s\n", param)
+			}
+			key, _ := json.Marshal(entry.key)
+			value, err := json.MarshalIndent(entry.data, "      ", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal template entry %s: %v", entry.key, err)
+			}
+			fmt.Fprintf(&b, "      %s: %s,\n", key, value)
+		}
+		b.WriteString("    },\n")
+	}
+	b.WriteString("  }\n}\n")
+
+	if err := os.MkdirAll(g.outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	outputPath := filepath.Join(g.outputDir, "testdata_template.json5")
+	if err := os.WriteFile(outputPath, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write template file: %v", err)
+	}
+
+	fmt.Printf("Test data template generated at: %s\n", outputPath)
+	return nil
+}
@@ -0,0 +1,281 @@
+package testdata
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"auto-api-tester/internal/types"
+)
+
+// DefaultServerManagedFields are stripped from learned bodies because the
+// server assigns them on create
+var DefaultServerManagedFields = []string{
+	"id", "_id",
+	"createdAt", "updatedAt", "deletedAt",
+	"created_at", "updated_at", "deleted_at",
+	"createdOn", "updatedOn", "modifiedAt", "modified_at",
+	"version", "etag",
+}
+
+// Learner builds create/update bodies from the responses of live GET requests
+type Learner struct {
+	client      *http.Client
+	stripFields map[string]bool
+}
+
+// NewLearner creates a learner that strips the given server-managed fields
+func NewLearner(timeout time.Duration, stripFields []string) *Learner {
+	strip := make(map[string]bool, len(stripFields))
+	for _, field := range stripFields {
+		strip[strings.ToLower(field)] = true
+	}
+	return &Learner{
+		client:      &http.Client{Timeout: timeout},
+		stripFields: strip,
+	}
+}
+
+// LoadFile reads a test data file
+func LoadFile(path string) (*TestData, error) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var data TestData
+	if err := json.Unmarshal(stripJSON5(file), &data); err != nil {
+		return nil, fmt.Errorf("failed to parse test data: %v", err)
+	}
+	return &data, nil
+}
+
+// SaveFile writes test data to path, creating its directory if needed
+func SaveFile(path string, data *TestData) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal test data: %v", err)
+	}
+
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("failed to write test data file: %v", err)
+	}
+	return nil
+}
+
+// LearnBodies replaces the body of every POST, PUT and PATCH entry with an
+// existing resource fetched from the matching GET endpoint. It returns the
+// keys of the entries that were updated.
+func (l *Learner) LearnBodies(data *TestData) ([]string, error) {
+	entries, err := data.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string]TestEntry, len(entries))
+	keys := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		byKey[entry.Key()] = entry
+		keys = append(keys, entry.Key())
+	}
+	sort.Strings(keys)
+
+	var learned []string
+	for _, key := range keys {
+		target := byKey[key]
+		switch target.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			continue
+		}
+		if target.ExpectsRejection() {
+			continue
+		}
+
+		for _, getKey := range l.sourceEndpoints(byKey, target.Path) {
+			resource, err := l.fetchResource(byKey[getKey], target.EndpointTestData, data.BaseURL)
+			if err != nil {
+				fmt.Printf("Skipping %s as a source for %s: %v\n", getKey, key, err)
+				continue
+			}
+			target.Body = resource
+			data.Update(target)
+			learned = append(learned, key)
+			break
+		}
+	}
+
+	return learned, nil
+}
+
+// sourceEndpoints lists the GET entries that can provide a resource for path,
+// item endpoints first
+func (l *Learner) sourceEndpoints(entries map[string]TestEntry, path string) []string {
+	var item, collection string
+	if isParamSegment(lastSegment(path)) {
+		item = path
+		collection = path[:strings.LastIndex(path, "/")]
+	} else {
+		collection = path
+	}
+
+	var sources []string
+	if item != "" {
+		if _, ok := entries["GET "+item]; ok {
+			sources = append(sources, "GET "+item)
+		}
+	} else {
+		// Any GET one parameter segment below the collection is an item endpoint
+		var items []string
+		for key, entry := range entries {
+			candidate := entry.Path
+			if entry.Method == http.MethodGet && strings.HasPrefix(candidate, collection+"/") &&
+				!strings.Contains(candidate[len(collection)+1:], "/") && isParamSegment(lastSegment(candidate)) {
+				items = append(items, key)
+			}
+		}
+		sort.Strings(items)
+		sources = append(sources, items...)
+	}
+	if _, ok := entries["GET "+collection]; ok {
+		sources = append(sources, "GET "+collection)
+	}
+	return sources
+}
+
+// fetchResource performs the GET request and returns one resource object
+// with its server-managed fields removed. A relative source path is sent to
+// the entry's base URL, or to baseURL.
+func (l *Learner) fetchResource(source TestEntry, target types.EndpointTestData, baseURL string) (map[string]interface{}, error) {
+	rawURL, err := fillPathParams(source.Path, source.PathParams, target.PathParams)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(rawURL, "://") {
+		if source.BaseURL != "" {
+			baseURL = source.BaseURL
+		}
+		if baseURL == "" {
+			return nil, fmt.Errorf("path %s is relative and the test data has no base_url", source.Path)
+		}
+		rawURL = strings.TrimSuffix(baseURL, "/") + "/" + strings.TrimPrefix(rawURL, "/")
+	}
+
+	if len(source.QueryParams) > 0 {
+		query := url.Values{}
+		for name, value := range source.QueryParams {
+			query.Set(name, fmt.Sprint(value))
+		}
+		rawURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	for name, value := range source.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %v", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return nil, fmt.Errorf("response is not JSON: %v", err)
+	}
+
+	resource, ok := firstObject(decoded)
+	if !ok {
+		return nil, fmt.Errorf("response contains no resource object")
+	}
+	return l.strip(resource), nil
+}
+
+// strip removes server-managed fields from a resource. Nested objects are
+// kept intact since their ids usually reference other resources.
+func (l *Learner) strip(resource map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(resource))
+	for key, value := range resource {
+		if !l.stripFields[strings.ToLower(key)] {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// firstObject returns the resource in a response: the object itself, the
+// first element of a list, or the first element of a wrapped list such as
+// {"data": [...]} or {"items": [...]}
+func firstObject(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, wrapper := range []string{"data", "items", "results", "content"} {
+			if list, ok := v[wrapper].([]interface{}); ok {
+				return firstObject(list)
+			}
+		}
+		return v, true
+	case []interface{}:
+		if len(v) > 0 {
+			if object, ok := v[0].(map[string]interface{}); ok {
+				return object, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// fillPathParams substitutes {name} segments from the first params map that
+// defines them
+func fillPathParams(path string, params ...map[string]interface{}) (string, error) {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if !isParamSegment(segment) {
+			continue
+		}
+		name := segment[1 : len(segment)-1]
+		found := false
+		for _, values := range params {
+			if value, ok := values[name]; ok {
+				segments[i] = url.PathEscape(fmt.Sprint(value))
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", fmt.Errorf("no value for path parameter %q", name)
+		}
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+func lastSegment(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+func isParamSegment(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
@@ -1,17 +1,236 @@
 package testdata
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
+	"auto-api-tester/internal/config"
 	"auto-api-tester/internal/types"
 )
 
-// TestData represents the test data structure
+// TestData represents the test data structure. Entries can be keyed by a
+// "METHOD path" string under endpoints, or listed under tests with separate
+// method and path fields. A keyed entry is one object or a list of cases.
 type TestData struct {
-	Endpoints map[string]types.EndpointTestData `json:"endpoints"`
+	// BaseURL is the origin relative endpoint paths are sent to when no
+	// base URL is configured
+	BaseURL   string                     `json:"base_url,omitempty"`
+	Endpoints map[string]types.TestCases `json:"endpoints,omitempty"`
+	Tests     []TestEntry                `json:"tests,omitempty"`
+
+	// Groups holds "METHOD path" keyed entries nested under a group name,
+	// such as the spec tag in a template generated with -group-by-tag
+	Groups map[string]map[string]types.TestCases `json:"groups,omitempty"`
+
+	Metadata *types.TemplateMetadata `json:"metadata,omitempty"`
+
+	// endpointOrder, groupOrder and groupEntryOrder record the order in
+	// which the keyed entries and groups are declared in the file
+	endpointOrder   []string
+	groupOrder      []string
+	groupEntryOrder map[string][]string
+}
+
+// UnmarshalJSON decodes test data, remembering the order of its keyed
+// entries so they run in the order they are written
+func (d *TestData) UnmarshalJSON(data []byte) error {
+	type plain TestData
+	if err := json.Unmarshal(data, (*plain)(d)); err != nil {
+		return err
+	}
+
+	var raw struct {
+		Endpoints json.RawMessage `json:"endpoints"`
+		Groups    json.RawMessage `json:"groups"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	d.endpointOrder = objectKeys(raw.Endpoints)
+	d.groupOrder = objectKeys(raw.Groups)
+
+	var groups map[string]json.RawMessage
+	if len(raw.Groups) > 0 {
+		if err := json.Unmarshal(raw.Groups, &groups); err != nil {
+			return err
+		}
+	}
+	d.groupEntryOrder = make(map[string][]string, len(groups))
+	for group, entries := range groups {
+		d.groupEntryOrder[group] = objectKeys(entries)
+	}
+	return nil
+}
+
+// objectKeys returns the keys of a JSON object in the order they appear,
+// or nil when data isn't an object
+func objectKeys(data json.RawMessage) []string {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil
+	}
+	var keys []string
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return keys
+		}
+		key, _ := token.(string)
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return keys
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// orderedKeys returns the keys of m in their declared order, followed by
+// any undeclared ones, such as those of test data built in code, sorted
+func orderedKeys[V any](m map[string]V, declared []string) []string {
+	keys := make([]string, 0, len(m))
+	seen := make(map[string]bool, len(m))
+	for _, key := range declared {
+		if _, ok := m[key]; ok && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	var rest []string
+	for key := range m {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
+
+// TestEntry is test data for the endpoint identified by Method and Path
+type TestEntry struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	types.EndpointTestData
+}
+
+// Key returns the "METHOD path" key identifying the entry's endpoint
+func (e TestEntry) Key() string {
+	return e.Method + " " + e.Path
+}
+
+// ID identifies the entry among all test cases: its key, followed by the
+// case name in brackets when it has one
+func (e TestEntry) ID() string {
+	if e.Name == "" {
+		return e.Key()
+	}
+	return e.Key() + " [" + e.Name + "]"
+}
+
+// caseName returns the name of case i of an endpoint with count cases;
+// unnamed cases in a list are called "case 1", "case 2", ...
+func caseName(data types.EndpointTestData, i, count int) string {
+	if data.Name == "" && count > 1 {
+		return fmt.Sprintf("case %d", i+1)
+	}
+	return data.Name
+}
+
+// Entries returns every test case in the test data in the order it is
+// written: string-keyed ones first, then grouped ones, then the tests list.
+// Entries whose order isn't known, in test data built in code, are sorted by
+// key. Malformed keys and duplicate cases are reported as errors rather than
+// skipped.
+func (d *TestData) Entries() ([]TestEntry, error) {
+	entries := make([]TestEntry, 0, len(d.Endpoints)+len(d.Tests))
+	seen := make(map[string]bool, cap(entries))
+
+	addKeyed := func(endpoints map[string]types.TestCases, declared []string) error {
+		for _, key := range orderedKeys(endpoints, declared) {
+			method, path, ok := strings.Cut(strings.TrimSpace(key), " ")
+			path = strings.TrimSpace(path)
+			if !ok || path == "" {
+				return fmt.Errorf("invalid endpoint key %q: expected \"METHOD path\"", key)
+			}
+			cases := endpoints[key]
+			for i, data := range cases {
+				data.Name = caseName(data, i, len(cases))
+				entry := TestEntry{Method: strings.ToUpper(method), Path: path, EndpointTestData: data}
+				if seen[entry.ID()] {
+					return fmt.Errorf("duplicate test data for endpoint %s", entry.ID())
+				}
+				seen[entry.ID()] = true
+				entries = append(entries, entry)
+			}
+		}
+		return nil
+	}
+
+	if err := addKeyed(d.Endpoints, d.endpointOrder); err != nil {
+		return nil, err
+	}
+	for _, group := range orderedKeys(d.Groups, d.groupOrder) {
+		if err := addKeyed(d.Groups[group], d.groupEntryOrder[group]); err != nil {
+			return nil, fmt.Errorf("group %s: %w", group, err)
+		}
+	}
+
+	for i, entry := range d.Tests {
+		if entry.Method == "" || entry.Path == "" {
+			return nil, fmt.Errorf("test entry %d: method and path are required", i)
+		}
+		entry.Method = strings.ToUpper(entry.Method)
+		if seen[entry.ID()] {
+			return nil, fmt.Errorf("duplicate test data for endpoint %s", entry.ID())
+		}
+		seen[entry.ID()] = true
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// Update replaces the data of the existing case with the same method, path
+// and case name
+func (d *TestData) Update(entry TestEntry) {
+	if updateKeyed(d.Endpoints, entry) {
+		return
+	}
+	for _, endpoints := range d.Groups {
+		if updateKeyed(endpoints, entry) {
+			return
+		}
+	}
+	for i := range d.Tests {
+		if strings.EqualFold(d.Tests[i].Method, entry.Method) && d.Tests[i].Path == entry.Path && d.Tests[i].Name == entry.Name {
+			d.Tests[i].EndpointTestData = entry.EndpointTestData
+			return
+		}
+	}
+}
+
+// updateKeyed replaces the matching case of a "METHOD path" keyed entry,
+// reporting whether there was one
+func updateKeyed(endpoints map[string]types.TestCases, entry TestEntry) bool {
+	for key, cases := range endpoints {
+		method, path, _ := strings.Cut(strings.TrimSpace(key), " ")
+		if !strings.EqualFold(method, entry.Method) || strings.TrimSpace(path) != entry.Path {
+			continue
+		}
+		for i, data := range cases {
+			if caseName(data, i, len(cases)) == entry.Name {
+				cases[i] = entry.EndpointTestData
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // Loader handles loading test data from files
@@ -24,18 +243,36 @@ func NewLoader(dir string) *Loader {
 	return &Loader{dir: dir}
 }
 
+// ErrNoTestData is returned by LoadTestData when no test data file can be read
+var ErrNoTestData = errors.New("no test data found")
+
+// envError is an unresolved ${VAR} reference in a test data file. Unlike an
+// unreadable file it stops the search for another one.
+type envError struct {
+	path string
+	err  error
+}
+
+func (e *envError) Error() string {
+	return fmt.Sprintf("%s: %v", e.path, e.err)
+}
+
 // LoadTestData loads test data from the template file
 func (l *Loader) LoadTestData() (*TestData, error) {
-	// Try loading from testdata_template.json first
-	data, err := l.loadFromFile("testdata_template.json")
-	if err != nil {
-		// If not found, try testdata.json as fallback
-		data, err = l.loadFromFile("testdata.json")
-		if err != nil {
-			return nil, fmt.Errorf("no test data found: %v", err)
+	// Try the template first, then testdata.json as fallback; either may be
+	// JSON5 as written by the grouped template generator
+	var err error
+	for _, filename := range []string{"testdata_template.json", "testdata_template.json5", "testdata.json", "testdata.json5"} {
+		var data *TestData
+		if data, err = l.loadFromFile(filename); err == nil {
+			return data, nil
+		}
+		var envErr *envError
+		if errors.As(err, &envErr) {
+			return nil, err
 		}
 	}
-	return data, nil
+	return nil, fmt.Errorf("%w: %v", ErrNoTestData, err)
 }
 
 func (l *Loader) loadFromFile(filename string) (*TestData, error) {
@@ -45,26 +282,38 @@ func (l *Loader) loadFromFile(filename string) (*TestData, error) {
 		return nil, err
 	}
 
+	// ${VAR} references are expanded in the parsed strings, so values
+	// containing quotes don't break the JSON
+	expanded, err := config.ExpandEnvJSON(stripJSON5(file))
+	if err != nil {
+		return nil, &envError{path: path, err: err}
+	}
+
 	var data TestData
-	if err := json.Unmarshal(file, &data); err != nil {
+	if err := json.Unmarshal(expanded, &data); err != nil {
 		return nil, fmt.Errorf("failed to parse test data: %v", err)
 	}
 
 	return &data, nil
 }
 
-// GetTestDataForEndpoint returns test data for a specific endpoint
+// GetTestDataForEndpoint returns test data for a specific endpoint and test case
 func (l *Loader) GetTestDataForEndpoint(endpoint types.Endpoint) (*types.EndpointTestData, error) {
 	template, err := l.LoadTestData()
 	if err != nil {
 		return nil, err
 	}
 
-	key := fmt.Sprintf("%s %s", endpoint.Method, endpoint.Path)
-	testData, exists := template.Endpoints[key]
-	if !exists {
-		return nil, fmt.Errorf("no test data found for endpoint: %s", key)
+	entries, err := template.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.Method == endpoint.Method && entry.Path == endpoint.Path && entry.Name == endpoint.Case {
+			return &entry.EndpointTestData, nil
+		}
 	}
 
-	return &testData, nil
+	return nil, fmt.Errorf("no test data found for endpoint: %s", endpoint.Key())
 }
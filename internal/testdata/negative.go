@@ -0,0 +1,200 @@
+package testdata
+
+import (
+	"encoding/json"
+	"maps"
+	"math"
+	"sort"
+	"strings"
+
+	"auto-api-tester/internal/types"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// negativeStatus is expected of a generated negative case; APIs reject
+// invalid input with either
+var negativeStatus = []int{400, 422}
+
+// templateCases is an endpoint's template entry, written as a single object
+// unless negative cases were generated alongside the valid one
+type templateCases []EndpointTestData
+
+// MarshalJSON writes a single case as an object and several as a list
+func (c templateCases) MarshalJSON() ([]byte, error) {
+	if len(c) == 1 {
+		return json.Marshal(c[0])
+	}
+	return json.Marshal([]EndpointTestData(c))
+}
+
+// endpointTestCases returns the endpoint's valid test data followed, with
+// the Negative option, by the cases the server should reject. An endpoint
+// whose schemas have no constraints keeps the valid case only.
+func (g *Generator) endpointTestCases(endpoint types.Endpoint) templateCases {
+	valid := g.generateEndpointTestData(endpoint)
+	if !g.options.Negative {
+		return templateCases{valid}
+	}
+	negative := g.negativeCases(endpoint, valid)
+	if len(negative) == 0 {
+		return templateCases{valid}
+	}
+	valid.Name = "valid"
+	return append(templateCases{valid}, negative...)
+}
+
+// negativeCases derives invalid variants of valid from the constraints of
+// the endpoint's body and query parameter schemas: an omitted required
+// field, a value outside maxLength, minLength, minimum, maximum or an enum,
+// and one field of the wrong type
+func (g *Generator) negativeCases(endpoint types.Endpoint, valid EndpointTestData) []EndpointTestData {
+	var cases []EndpointTestData
+	add := func(name string, modify func(*EndpointTestData)) {
+		data := valid
+		data.Name = name
+		data.ExpectedStatus = negativeStatus
+		data.ResponseHint = nil
+		data.QueryParams = maps.Clone(valid.QueryParams)
+		if body, ok := valid.Body.(map[string]interface{}); ok {
+			data.Body = maps.Clone(body)
+		}
+		modify(&data)
+		cases = append(cases, data)
+	}
+
+	for _, param := range endpoint.Parameters {
+		schema := resolveSchema(param.Schema)
+		if schema == nil {
+			continue
+		}
+		switch param.In {
+		case "query":
+			if param.Required {
+				add("missing "+param.Name, func(data *EndpointTestData) {
+					delete(data.QueryParams, param.Name)
+				})
+			}
+			for _, v := range violations(schema) {
+				add(param.Name+" "+v.label, func(data *EndpointTestData) {
+					data.QueryParams[param.Name] = v.value
+				})
+			}
+		case "body":
+			body, ok := valid.Body.(map[string]interface{})
+			if !ok || schema.Type == nil || !schema.Type.Is("object") {
+				continue
+			}
+			required := make(map[string]bool, len(schema.Required))
+			for _, name := range schema.Required {
+				required[name] = true
+			}
+			names := make([]string, 0, len(body))
+			for name := range body {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			wrongTyped := false
+			for _, name := range names {
+				prop := resolveSchema(schema.Properties[name])
+				if prop == nil {
+					continue
+				}
+				if required[name] {
+					add("missing "+name, func(data *EndpointTestData) {
+						delete(data.Body.(map[string]interface{}), name)
+					})
+				}
+				for _, v := range violations(prop) {
+					add(name+" "+v.label, func(data *EndpointTestData) {
+						data.Body.(map[string]interface{})[name] = v.value
+					})
+				}
+				if value := wrongType(prop); value != nil && !wrongTyped {
+					wrongTyped = true
+					add(name+" of the wrong type", func(data *EndpointTestData) {
+						data.Body.(map[string]interface{})[name] = value
+					})
+				}
+			}
+		}
+	}
+	return cases
+}
+
+// violation is a value breaking one of a schema's constraints
+type violation struct {
+	label string
+	value interface{}
+}
+
+// violations returns a value breaking each of the schema's length, range
+// and enum constraints
+func violations(schema *openapi3.Schema) []violation {
+	if schema.Type == nil {
+		return nil
+	}
+	var result []violation
+	switch {
+	case schema.Type.Is("string"):
+		if schema.MaxLength != nil {
+			result = append(result, violation{"too long", strings.Repeat("a", int(*schema.MaxLength)+1)})
+		}
+		if schema.MinLength > 0 {
+			result = append(result, violation{"too short", strings.Repeat("a", int(schema.MinLength)-1)})
+		}
+		if len(schema.Enum) > 0 {
+			result = append(result, violation{"not in enum", "not_a_valid_value"})
+		}
+	case schema.Type.Is("integer"):
+		if schema.Min != nil {
+			result = append(result, violation{"below minimum", int(math.Ceil(*schema.Min)) - 1})
+		}
+		if schema.Max != nil {
+			result = append(result, violation{"above maximum", int(math.Floor(*schema.Max)) + 1})
+		}
+	case schema.Type.Is("number"):
+		if schema.Min != nil {
+			result = append(result, violation{"below minimum", *schema.Min - 1})
+		}
+		if schema.Max != nil {
+			result = append(result, violation{"above maximum", *schema.Max + 1})
+		}
+	}
+	return result
+}
+
+// wrongType returns a value of a different type than the schema's
+func wrongType(schema *openapi3.Schema) interface{} {
+	if schema.Type == nil {
+		return nil
+	}
+	switch {
+	case schema.Type.Is("string"):
+		return 12345
+	case schema.Type.Is("integer"), schema.Type.Is("number"):
+		return "not_a_number"
+	case schema.Type.Is("boolean"):
+		return "not_a_boolean"
+	case schema.Type.Is("array"):
+		return "not_an_array"
+	case schema.Type.Is("object"):
+		return "not_an_object"
+	}
+	return nil
+}
+
+// resolveSchema returns the kin-openapi schema of a parameter or property,
+// or nil when it has none
+func resolveSchema(schema interface{}) *openapi3.Schema {
+	switch s := schema.(type) {
+	case *openapi3.SchemaRef:
+		if s != nil {
+			return s.Value
+		}
+	case *openapi3.Schema:
+		return s
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package testdata
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"auto-api-tester/internal/types"
+)
+
+// parameterDoc is what the spec says about a parameter
+type parameterDoc struct {
+	Name        string      `json:"name"`
+	In          string      `json:"in"`
+	Required    bool        `json:"required,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Example     interface{} `json:"example,omitempty"`
+}
+
+// String formats the doc as a single line, e.g.
+// "limit (query): Maximum number of items. Example: 20"
+func (d parameterDoc) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s)", d.Name, d.In)
+	if d.Description != "" {
+		fmt.Fprintf(&b, ": %s", strings.Join(strings.Fields(d.Description), " "))
+	}
+	if d.Example != nil {
+		example, _ := json.Marshal(d.Example)
+		fmt.Fprintf(&b, ". Example: %s", example)
+	}
+	return b.String()
+}
+
+// parameterDocs returns the docs of an endpoint's parameters that have a
+// description or an example
+func parameterDocs(endpoint types.Endpoint) []parameterDoc {
+	var docs []parameterDoc
+	for _, param := range endpoint.Parameters {
+		if param.In == "body" || param.Description == "" && param.Example == nil {
+			continue
+		}
+		docs = append(docs, parameterDoc{
+			Name:        param.Name,
+			In:          param.In,
+			Required:    param.Required,
+			Description: param.Description,
+			Example:     param.Example,
+		})
+	}
+	return docs
+}
+
+// writeParameterDocs writes testdata_parameters.json next to a JSON template,
+// listing the documented parameters of each endpoint under the template's
+// "METHOD path" keys. Nothing is written when no parameter is documented.
+func (g *Generator) writeParameterDocs(endpoints []types.Endpoint) error {
+	docs := make(map[string][]parameterDoc)
+	for _, endpoint := range endpoints {
+		if endpointDocs := parameterDocs(endpoint); len(endpointDocs) > 0 {
+			docs[fmt.Sprintf("%s %s", endpoint.Method, endpoint.Path)] = endpointDocs
+		}
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(docs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal parameter docs: %v", err)
+	}
+	outputPath := filepath.Join(g.outputDir, "testdata_parameters.json")
+	if err := os.WriteFile(outputPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write parameter docs: %v", err)
+	}
+	fmt.Printf("Parameter descriptions written to: %s\n", outputPath)
+	return nil
+}
@@ -0,0 +1,200 @@
+package testdata
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"auto-api-tester/internal/types"
+)
+
+// resourceIDs holds one identifier per resource of a spec so that related
+// endpoints agree: GET /users/{id}, DELETE /users/{userId} and the userId
+// field of POST /orders all use the same user. A resource is identified by
+// the path up to its parameter with parameter names erased, e.g. /users/{}.
+type resourceIDs struct {
+	values map[string]interface{}
+	// bySingular maps a singular collection name such as "user" to its resource
+	bySingular map[string]string
+}
+
+// newResourceIDs assigns identifiers to the resources of endpoints. Each one
+// is derived from the sample value of the resource's parameter and made
+// distinct per resource, in path order so the output is stable.
+func (g *Generator) newResourceIDs(endpoints []types.Endpoint) *resourceIDs {
+	params := make(map[string]types.Parameter)
+	for _, endpoint := range endpoints {
+		for i, segment := range pathSegments(endpoint.Path) {
+			name, ok := pathParamName(segment)
+			if !ok {
+				continue
+			}
+			key := resourceKey(endpoint.Path, i)
+			if _, seen := params[key]; seen {
+				continue
+			}
+			for _, param := range endpoint.Parameters {
+				if param.In == "path" && param.Name == name {
+					params[key] = param
+					break
+				}
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	ids := &resourceIDs{
+		values:     make(map[string]interface{}, len(keys)),
+		bySingular: make(map[string]string),
+	}
+	for n, key := range keys {
+		collection := collectionName(key)
+		ids.values[key] = distinctID(g.generateSampleValue(params[key]), singular(collection), n+1)
+		// Nested resources reuse collection names; the outermost one wins
+		if existing, ok := ids.bySingular[singular(collection)]; !ok || len(key) < len(existing) {
+			ids.bySingular[singular(collection)] = key
+		}
+	}
+	return ids
+}
+
+// apply makes an endpoint's test data use the shared identifiers: its path
+// parameters, query parameters named after a resource (userId, user_id), and
+// the matching fields of its body, including the id of the resource a POST
+// or PUT to the collection creates
+func (r *resourceIDs) apply(endpoint types.Endpoint, data *EndpointTestData) {
+	segments := pathSegments(endpoint.Path)
+	for i, segment := range segments {
+		if name, ok := pathParamName(segment); ok {
+			if value, ok := r.values[resourceKey(endpoint.Path, i)]; ok {
+				data.PathParams[name] = value
+			}
+		}
+	}
+
+	for name := range data.QueryParams {
+		if value, ok := r.reference(name); ok {
+			data.QueryParams[name] = value
+		}
+	}
+
+	// The resource the body describes: /users and /users/{id} both carry a user
+	own := ""
+	if len(segments) > 0 {
+		if _, isParam := pathParamName(segments[len(segments)-1]); isParam {
+			own = resourceKey(endpoint.Path, len(segments)-1)
+		} else {
+			own = resourceKey(endpoint.Path+"/{id}", len(segments))
+		}
+	}
+	if body, ok := data.Body.(map[string]interface{}); ok {
+		for field := range body {
+			if strings.EqualFold(field, "id") {
+				if value, ok := r.values[own]; ok {
+					body[field] = value
+				}
+				continue
+			}
+			if value, ok := r.reference(field); ok {
+				body[field] = value
+			}
+		}
+	}
+}
+
+// reference resolves a field named after a resource, such as userId or
+// user_id, to that resource's identifier
+func (r *resourceIDs) reference(field string) (interface{}, bool) {
+	lower := strings.ToLower(field)
+	for _, suffix := range []string{"_id", "id"} {
+		if name, ok := strings.CutSuffix(lower, suffix); ok && name != "" {
+			if key, ok := r.bySingular[strings.TrimSuffix(name, "_")]; ok {
+				return r.values[key], true
+			}
+		}
+	}
+	return nil, false
+}
+
+// pathSegments splits a path, or the path of an absolute endpoint URL, into
+// its non-empty segments
+func pathSegments(path string) []string {
+	if _, rest, ok := strings.Cut(path, "://"); ok {
+		path = ""
+		if _, p, ok := strings.Cut(rest, "/"); ok {
+			path = p
+		}
+	}
+	return strings.FieldsFunc(path, func(r rune) bool { return r == '/' })
+}
+
+// pathParamName returns the parameter name of a {name} segment
+func pathParamName(segment string) (string, bool) {
+	if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+		return segment[1 : len(segment)-1], true
+	}
+	return "", false
+}
+
+// resourceKey identifies the resource addressed by the parameter at segment
+// index i of path, e.g. /users/{} for /users/{userId}/orders and index 1
+func resourceKey(path string, i int) string {
+	segments := pathSegments(path)[:i+1]
+	key := make([]string, len(segments))
+	for j, segment := range segments {
+		if _, ok := pathParamName(segment); ok {
+			segment = "{}"
+		}
+		key[j] = segment
+	}
+	return "/" + strings.Join(key, "/")
+}
+
+// collectionName returns the collection segment preceding a resource's
+// parameter, e.g. users for /users/{}
+func collectionName(key string) string {
+	segments := pathSegments(key)
+	if len(segments) < 2 {
+		return ""
+	}
+	return strings.ToLower(segments[len(segments)-2])
+}
+
+// singular turns a plural collection name into the form used in field names
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "sses"), strings.HasSuffix(name, "xes"):
+		return name[:len(name)-2]
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss"):
+		return strings.TrimSuffix(name, "s")
+	}
+	return name
+}
+
+// distinctID derives the n-th resource's identifier from a sample value of
+// the parameter's type, so resources don't share identifiers
+func distinctID(sample interface{}, name string, n int) interface{} {
+	switch value := sample.(type) {
+	case int:
+		return n
+	case float64:
+		return float64(n)
+	case string:
+		// Keep a UUID a UUID by varying its last digits
+		if len(value) == 36 && strings.Count(value, "-") == 4 {
+			return fmt.Sprintf("%s%012d", value[:24], n)
+		}
+		if name == "" {
+			name = "resource"
+		}
+		return fmt.Sprintf("%s_%d", name, n)
+	}
+	return sample
+}
@@ -1,8 +1,22 @@
 package types
 
-// TestDataTemplate represents the structure of the test data template
-type TestDataTemplate struct {
-	Endpoints map[string]EndpointTestData `json:"endpoints"`
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// TemplateMetadata records how a test data file was generated
+type TemplateMetadata struct {
+	// GenerationFailures lists the entries left as placeholders because
+	// generating their data failed; they need manual attention
+	GenerationFailures []GenerationFailure `json:"generation_failures,omitempty"`
+}
+
+// GenerationFailure is an endpoint whose test data could not be generated
+type GenerationFailure struct {
+	Endpoint string `json:"endpoint"`
+	Error    string `json:"error"`
 }
 
 // Endpoint represents an API endpoint with its parameters and test data
@@ -11,15 +25,177 @@ type Endpoint struct {
 	Path       string
 	Parameters []Parameter
 	TestData   EndpointTestData
-	Responses  map[int]Response
+	// Weight is the spec's x-weight extension, copied into generated test data
+	Weight float64
+	// Responses are keyed by status code, with the spec's "default" response
+	// stored under DefaultResponse
+	Responses map[int]Response
+	// Tags and Summary come from the spec's operation and label generated templates
+	Tags    []string
+	Summary string
+	// Case names the test case when an endpoint has several
+	Case string
+	// BaseURL is the origin a relative Path is sent to when no other base
+	// URL is configured, such as the URL the spec was generated from
+	BaseURL string
+}
+
+// Key returns "METHOD path", followed by the case name in brackets for
+// endpoints with several test cases
+func (e Endpoint) Key() string {
+	key := e.Method + " " + e.Path
+	if e.Case != "" {
+		key += " [" + e.Case + "]"
+	}
+	return key
 }
 
 // EndpointTestData represents test data for a specific endpoint
 type EndpointTestData struct {
+	// Name identifies the test case among the endpoint's cases
+	Name string `json:"name,omitempty"`
+
 	PathParams  map[string]interface{} `json:"path_params,omitempty"`
 	QueryParams map[string]interface{} `json:"query_params,omitempty"`
 	Body        interface{}            `json:"body,omitempty"`
 	Headers     map[string]string      `json:"headers,omitempty"`
+
+	// ExpectedHeaders lists response headers that must be present for the test to pass
+	ExpectedHeaders []HeaderAssertion `json:"expected_headers,omitempty"`
+
+	// ArrayItems overrides the global array size used when generating array bodies
+	ArrayItems *ArrayBounds `json:"array_items,omitempty"`
+
+	// Weight biases how often the endpoint is exercised when iterating or
+	// sampling; an endpoint with weight 10 runs about 10x as often as weight 1
+	Weight float64 `json:"weight,omitempty"`
+
+	// ExpectedContentType and MinResponseBytes are asserted on binary
+	// responses, e.g. "application/pdf" or "image/*"
+	ExpectedContentType string `json:"expected_content_type,omitempty"`
+	MinResponseBytes    int    `json:"min_response_bytes,omitempty"`
+
+	// MaxConcurrency caps how many requests to this endpoint run at once,
+	// on top of the global worker limit
+	MaxConcurrency int `json:"max_concurrency,omitempty"`
+
+	// AuthProfile names the configured auth profile whose credentials this
+	// endpoint uses instead of the global auth; "none" sends no credentials
+	AuthProfile string `json:"auth_profile,omitempty"`
+
+	// BaseURL replaces the scheme and host of this endpoint's URL, taking
+	// precedence over the run's -base-url, for suites spanning several services
+	BaseURL string `json:"base_url,omitempty"`
+
+	// JSONAssertions are checked against the parsed JSON response body
+	JSONAssertions []JSONAssertion `json:"json_assertions,omitempty"`
+
+	// Extract maps variable names to JSONPath expressions whose values in
+	// the response body are stored for later requests to use as {{name}}
+	Extract map[string]string `json:"extract,omitempty"`
+
+	// DependsOn names the test cases that must finish before this one starts,
+	// by case name, "METHOD path" or "METHOD path [case]"
+	DependsOn []string `json:"depends_on,omitempty"`
+	// ExpectedStatus lists the status codes the test passes with in place
+	// of any 2xx, e.g. [400, 422] for a request the server must reject
+	ExpectedStatus []int `json:"expected_status,omitempty"`
+}
+
+// ExpectsRejection reports whether the case only passes with a 4xx status,
+// i.e. its input is deliberately invalid and must not be replaced by valid data
+func (d EndpointTestData) ExpectsRejection() bool {
+	for _, status := range d.ExpectedStatus {
+		if status < 400 || status >= 500 {
+			return false
+		}
+	}
+	return len(d.ExpectedStatus) > 0
+}
+
+// TestCases is the test data of an endpoint: a single object, or a list of
+// named cases that are each run as a separate test
+type TestCases []EndpointTestData
+
+// UnmarshalJSON accepts a single object as a one-case list
+func (c *TestCases) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var single EndpointTestData
+		if err := json.Unmarshal(data, &single); err != nil {
+			return err
+		}
+		*c = TestCases{single}
+		return nil
+	}
+	var cases []EndpointTestData
+	if err := json.Unmarshal(data, &cases); err != nil {
+		return err
+	}
+	*c = cases
+	return nil
+}
+
+// MarshalJSON writes a single case as a plain object
+func (c TestCases) MarshalJSON() ([]byte, error) {
+	if len(c) == 1 {
+		return json.Marshal(c[0])
+	}
+	return json.Marshal([]EndpointTestData(c))
+}
+
+// ArrayBounds limits how many items are generated for array bodies
+type ArrayBounds struct {
+	Min int `json:"min,omitempty"`
+	Max int `json:"max,omitempty"`
+}
+
+// WithDefaults fills unset bounds with the given defaults and makes sure
+// the range is valid
+func (b ArrayBounds) WithDefaults(min, max int) ArrayBounds {
+	if b.Min <= 0 {
+		b.Min = min
+	}
+	if b.Max <= 0 {
+		b.Max = max
+	}
+	if b.Max < b.Min {
+		b.Max = b.Min
+	}
+	return b
+}
+
+// HeaderAssertion describes a response header that must be present, optionally
+// with an exact value or a regular expression the value has to match
+type HeaderAssertion struct {
+	Name    string `json:"name"`
+	Value   string `json:"value,omitempty"`
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// JSONAssertion compares the value at a JSONPath such as "$.status" or
+// "$.items[0].id" with Value. Operator defaults to "=="; the others are
+// "!=", ">", ">=", "<", "<=", "contains", "matches" (regular expression) and
+// "exists". A path with a [*] wildcard must satisfy the assertion for every match.
+type JSONAssertion struct {
+	Path     string      `json:"path"`
+	Operator string      `json:"operator,omitempty"`
+	Value    interface{} `json:"value,omitempty"`
+
+	// Tolerance and RelativeTolerance let == and != treat numbers as equal
+	// when they differ by at most Tolerance, or by at most RelativeTolerance
+	// times the expected value's magnitude (e.g. 0.001 for 0.1%)
+	Tolerance         float64 `json:"tolerance,omitempty"`
+	RelativeTolerance float64 `json:"relative_tolerance,omitempty"`
+}
+
+// FieldRule generates values for fields whose name contains Pattern, using a
+// named Generator (iban, credit_card, isbn, slug, color, email, phone), one of
+// Values at random, or Template with # replaced by a digit and ? by a letter
+type FieldRule struct {
+	Pattern   string   `json:"pattern"`
+	Generator string   `json:"generator,omitempty"`
+	Values    []string `json:"values,omitempty"`
+	Template  string   `json:"template,omitempty"`
 }
 
 // Parameter represents an API parameter
@@ -29,10 +205,38 @@ type Parameter struct {
 	Required    bool
 	Schema      interface{}
 	ContentType string
+
+	// Description and Example document the parameter in the spec
+	Description string
+	Example     interface{}
 }
 
+// DefaultResponse is the Endpoint.Responses key of the spec's "default" response
+const DefaultResponse = 0
+
 // Response represents an API response
 type Response struct {
 	Description string
 	Schema      interface{}
 }
+
+// Timings breaks down where the time of a request went. DNS, Connect and TLS
+// are zero when an existing connection was reused.
+type Timings struct {
+	DNS        time.Duration `json:"dns"`
+	Connect    time.Duration `json:"connect"`
+	TLS        time.Duration `json:"tls"`
+	TTFB       time.Duration `json:"ttfb"`
+	Total      time.Duration `json:"total"`
+	ConnReused bool          `json:"conn_reused"`
+}
+
+// FieldDiff is one difference found by a content assertion: the value at Path
+// was Actual where Expected was wanted. Problem describes differences that
+// are not a plain value mismatch, such as a missing field.
+type FieldDiff struct {
+	Path     string      `json:"path"`
+	Expected interface{} `json:"expected,omitempty"`
+	Actual   interface{} `json:"actual,omitempty"`
+	Problem  string      `json:"problem,omitempty"`
+}
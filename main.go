@@ -3,97 +3,241 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"auto-api-tester/internal/config"
-	"auto-api-tester/internal/executor"
-	"auto-api-tester/internal/parser"
+	"auto-api-tester/internal/mockserver"
 	"auto-api-tester/internal/reporter"
+	"auto-api-tester/internal/schema"
 	"auto-api-tester/internal/testdata"
 	"auto-api-tester/internal/testdata/generator"
-	"auto-api-tester/internal/types"
+	"auto-api-tester/pkg/apitester"
 
 	_ "github.com/denisenkom/go-mssqldb" // for sqlserver
 	_ "github.com/go-sql-driver/mysql"   // for mysql
 	_ "github.com/lib/pq"                // for postgres
 )
 
-func convertTestResults(execResults []executor.TestResult) []reporter.TestResult {
-	repResults := make([]reporter.TestResult, len(execResults))
-	for i, r := range execResults {
-		status := 0
-		switch r.Status {
-		case "SUCCESS":
-			// Keep the original status code from the response
-			if r.Response == "No Content (204)" {
-				status = 204
-			} else {
-				status = 200
-			}
-		case "FAILURE":
-			status = 400
-		case "ERROR":
-			status = 500
-		}
-
-		// Try to parse response as JSON if it's not empty
-		var response interface{}
-		if r.Response != "" {
-			if err := json.Unmarshal([]byte(r.Response), &response); err != nil {
-				// If not JSON, use as string
-				response = r.Response
-			}
+// headerFlags collects repeated -header "Name: value" flags
+type headerFlags []string
+
+func (h *headerFlags) String() string { return strings.Join(*h, ", ") }
+
+func (h *headerFlags) Set(value string) error {
+	if !strings.Contains(value, ":") {
+		return fmt.Errorf("expected \"Name: value\", got %q", value)
+	}
+	*h = append(*h, value)
+	return nil
+}
+
+// inferSchema calls an endpoint and writes a JSON Schema inferred from its response
+func inferSchema(method, target, body string, headers headerFlags, timeout time.Duration, output string) error {
+	var reqBody io.Reader
+	if body != "" {
+		reqBody = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(strings.ToUpper(method), target, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for _, header := range headers {
+		name, value, _ := strings.Cut(header, ":")
+		req.Header.Set(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	resp, err := (&http.Client{Timeout: timeout}).Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %v", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return fmt.Errorf("response is not JSON: %v", err)
+	}
+
+	inferred, err := json.MarshalIndent(schema.Document(decoded), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal schema: %v", err)
+	}
+	if output == "" {
+		fmt.Println(string(inferred))
+		return nil
+	}
+	if err := os.WriteFile(output, append(inferred, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write schema: %v", err)
+	}
+	fmt.Printf("Schema written to %s\n", output)
+	return nil
+}
+
+// splitList returns the non-empty, trimmed items of a comma-separated flag
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
 		}
+	}
+	return items
+}
 
-		repResults[i] = reporter.TestResult{
-			Endpoint:    r.Endpoint,
-			Method:      r.Method,
-			Status:      status,
-			Duration:    r.Duration,
-			Error:       fmt.Sprintf("%v", r.Error),
-			RequestBody: r.RequestBody,
-			Response:    response,
+// extractGlobalFlag removes a flag such as -env-file from args, since it
+// applies before any subcommand flags are parsed, and returns its value
+func extractGlobalFlag(args []string, flagName string) ([]string, string) {
+	rest := make([]string, 0, len(args))
+	path := ""
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != flagName {
+			rest = append(rest, arg)
+			continue
+		}
+		if !hasValue && i+1 < len(args) {
+			i++
+			value = args[i]
 		}
+		path = value
 	}
-	return repResults
+	return rest, path
 }
 
 func main() {
+	// Populate the environment from a .env file before resolving config
+	args, envFile := extractGlobalFlag(os.Args[1:], "env-file")
+	args, configFile := extractGlobalFlag(args, "config")
+	os.Args = append(os.Args[:1], args...)
+	if envFile != "" {
+		if err := config.LoadEnvFile(envFile, true); err != nil {
+			log.Fatalf("Failed to load env file: %v", err)
+		}
+	} else if err := config.LoadEnvFile(config.DefaultEnvFile, false); err != nil {
+		log.Fatalf("Failed to load env file: %v", err)
+	}
 
-	// Load configuration
-	cfg, err := config.LoadConfig()
+	// Load configuration, from -config when given; its extension picks
+	// JSON or YAML
+	var cfg *config.Config
+	var err error
+	if configFile != "" {
+		cfg, err = config.LoadConfigFile(configFile)
+	} else {
+		cfg, err = config.LoadConfig()
+	}
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	// Serve the bundled mock API to try the tool without a real backend
+	if len(os.Args) > 1 && os.Args[1] == "mockserver" {
+		mockCmd := flag.NewFlagSet("mockserver", flag.ExitOnError)
+		addr := mockCmd.String("addr", "localhost:8089", "Address to listen on")
+		if err := mockCmd.Parse(os.Args[2:]); err != nil {
+			log.Fatalf("Failed to parse flags: %v", err)
+		}
+
+		fmt.Printf("Mock API listening on http://%s (spec at %s)\n", *addr, mockserver.SpecPath)
+		if err := mockserver.New().ListenAndServe(*addr); err != nil {
+			log.Fatalf("Mock server failed: %v", err)
+		}
+		return
+	}
+
+	// Infer a JSON Schema from a live response to bootstrap contract tests
+	if len(os.Args) > 1 && os.Args[1] == "infer-schema" {
+		inferCmd := flag.NewFlagSet("infer-schema", flag.ExitOnError)
+		target := inferCmd.String("url", "", "Endpoint URL to call")
+		method := inferCmd.String("method", http.MethodGet, "HTTP method")
+		body := inferCmd.String("body", "", "JSON request body")
+		output := inferCmd.String("output", "", "File to write the schema to (default stdout)")
+		var headers headerFlags
+		inferCmd.Var(&headers, "header", "Request header as \"Name: value\" (repeatable)")
+		if err := inferCmd.Parse(os.Args[2:]); err != nil {
+			log.Fatalf("Failed to parse flags: %v", err)
+		}
+		if *target == "" {
+			fmt.Println("Error: -url is required")
+			inferCmd.Usage()
+			os.Exit(1)
+		}
+
+		if err := inferSchema(*method, *target, *body, headers, time.Duration(cfg.Test.Timeout)*time.Second, *output); err != nil {
+			log.Fatalf("Failed to infer schema: %v", err)
+		}
+		return
+	}
+
 	// Check if we're running the generate command with input
 	if len(os.Args) > 1 && os.Args[1] == "generate" && len(os.Args) > 2 && os.Args[2] == "--input" {
 		// Create a new flag set for the generate command
 		generateCmd := flag.NewFlagSet("generate", flag.ExitOnError)
 
 		// Define flags
-		dbType := generateCmd.String("db-type", "", "Database type (postgres|mysql|sqlserver)")
+		dbType := generateCmd.String("db-type", "", "Database type (postgres|mysql|sqlserver|sqlite)")
 		dbHost := generateCmd.String("db-host", "", "Database host")
 		dbPort := generateCmd.Int("db-port", 0, "Database port")
-		dbName := generateCmd.String("db-name", "", "Database name")
+		dbName := generateCmd.String("db-name", "", "Database name, or the database file for sqlite")
+		dbSchema := generateCmd.String("db-schema", "", "Schema to read tables from (default public for postgres, the database name for mysql)")
 		dbUser := generateCmd.String("db-user", "", "Database user")
-		dbPassword := generateCmd.String("db-password", "", "Database password")
+		dbPassword := generateCmd.String("db-password", os.Getenv("DB_PASSWORD"), "Database password (defaults to $DB_PASSWORD)")
+		dbSSLMode := generateCmd.String("db-sslmode", generator.SSLDisable, "TLS for the database connection: "+strings.Join(generator.SSLModes, ", "))
+		dbSSLRootCert := generateCmd.String("db-sslrootcert", "", "CA certificate file to verify the database server against")
+		dbConnectTimeout := generateCmd.Duration("db-connect-timeout", generator.DefaultConnectTimeout, "How long to wait for the database before giving up")
+		dbMaxOpenConns := generateCmd.Int("db-max-open-conns", generator.DefaultMaxOpenConns, "Maximum open database connections")
+		dbMaxIdleConns := generateCmd.Int("db-max-idle-conns", generator.DefaultMaxIdleConns, "Maximum idle database connections kept in the pool")
+		dbConnMaxLifetime := generateCmd.Duration("db-conn-max-lifetime", generator.DefaultConnMaxLifetime, "How long a database connection may be reused")
 		templatePath := generateCmd.String("template", "", "Path to testdata template file")
 		outputPath := generateCmd.String("output", "", "Path to output testdata file")
+		noCache := generateCmd.Bool("no-cache", false, "Query the LLM even for prompts answered in llm.cache_dir, refreshing their cached responses")
+		seed := generateCmd.Int64("seed", 0, "Seed for generated values; the same seed, template and database give identical output (0 picks one and prints it)")
+		realistic := generateCmd.Bool("realistic", false, "Fill names, emails, addresses, phone numbers and companies with realistic values instead of synthetic ones like John42")
 
 		// Parse flags
 		if err := generateCmd.Parse(os.Args[3:]); err != nil {
 			log.Fatalf("Failed to parse flags: %v", err)
 		}
 
-		// Validate required flags
-		if *dbType == "" || *dbHost == "" || *dbPort == 0 || *dbName == "" || *dbUser == "" || *dbPassword == "" {
+		// Expand ${VAR} references so one committed command works across environments
+		for _, value := range []*string{dbHost, dbName, dbUser} {
+			expanded, err := config.ExpandEnv(*value)
+			if err != nil {
+				log.Fatalf("Invalid database flag: %v", err)
+			}
+			*value = expanded
+		}
+
+		// Validate required flags; SQLite only needs the database file path
+		if *dbType == "sqlite" {
+			if *dbName == "" {
+				fmt.Println("Error: -db-name must give the SQLite database file")
+				generateCmd.Usage()
+				os.Exit(1)
+			}
+		} else if *dbType == "" || *dbHost == "" || *dbPort == 0 || *dbName == "" || *dbUser == "" || *dbPassword == "" {
 			fmt.Println("Error: All database configuration flags are required")
 			generateCmd.Usage()
 			os.Exit(1)
@@ -113,10 +257,36 @@ func main() {
 			Database: *dbName,
 			User:     *dbUser,
 			Password: *dbPassword,
+			Schema:   *dbSchema,
+
+			SSLMode:     *dbSSLMode,
+			SSLRootCert: *dbSSLRootCert,
+
+			ConnectTimeout:  *dbConnectTimeout,
+			MaxOpenConns:    *dbMaxOpenConns,
+			MaxIdleConns:    *dbMaxIdleConns,
+			ConnMaxLifetime: *dbConnMaxLifetime,
 		}
 
 		// Initialize database generator
-		dbGenerator := generator.NewDBGenerator(dbConfig, *cfg.LLM, *templatePath, *outputPath)
+		if err := generator.ValidateFieldRules(cfg.Generation.FieldRules); err != nil {
+			log.Fatalf("Invalid generation config: %v", err)
+		}
+
+		llmConfig := *cfg.LLM
+		if *noCache {
+			llmConfig.RefreshCache = true
+		}
+
+		options := generator.Options{
+			ArrayItems: cfg.Generation.ArrayItems,
+			FieldRules: cfg.Generation.FieldRules,
+			Seed:       *seed,
+		}
+		if *realistic {
+			options.Provider = generator.RealisticProvider{}
+		}
+		dbGenerator := generator.NewDBGenerator(dbConfig, llmConfig, options, *templatePath, *outputPath)
 
 		// Generate test data
 		if err := dbGenerator.GenerateTestData(); err != nil {
@@ -127,6 +297,45 @@ func main() {
 		return
 	}
 
+	// Check if we're running the generate command learning bodies from live responses
+	if len(os.Args) > 2 && os.Args[1] == "generate" && os.Args[2] == "--from-responses" {
+		learnCmd := flag.NewFlagSet("generate --from-responses", flag.ExitOnError)
+		templatePath := learnCmd.String("template", filepath.Join("testdata", "testdata_template.json"), "Path to testdata template file")
+		outputPath := learnCmd.String("output", filepath.Join("testdata", "testdata.json"), "Path to output testdata file")
+		strip := learnCmd.String("strip", "", "Comma-separated extra server-managed fields to remove from learned bodies")
+		if err := learnCmd.Parse(os.Args[3:]); err != nil {
+			log.Fatalf("Failed to parse flags: %v", err)
+		}
+
+		data, err := testdata.LoadFile(*templatePath)
+		if err != nil {
+			log.Fatalf("Failed to load test data template: %v", err)
+		}
+
+		stripFields := append([]string{}, testdata.DefaultServerManagedFields...)
+		for _, field := range strings.Split(*strip, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				stripFields = append(stripFields, field)
+			}
+		}
+
+		learner := testdata.NewLearner(time.Duration(cfg.Test.Timeout)*time.Second, stripFields)
+		learned, err := learner.LearnBodies(data)
+		if err != nil {
+			log.Fatalf("Failed to learn bodies: %v", err)
+		}
+		for _, key := range learned {
+			fmt.Printf("Learned body for %s\n", key)
+		}
+
+		if err := testdata.SaveFile(*outputPath, data); err != nil {
+			log.Fatalf("Failed to save test data: %v", err)
+		}
+
+		fmt.Printf("Learned %d bodies; test data written to %s\n", len(learned), *outputPath)
+		return
+	}
+
 	// Check if we're running the generate command
 	if len(os.Args) > 1 && os.Args[1] == "generate" {
 		// Run the generate command
@@ -144,98 +353,146 @@ func main() {
 	if len(os.Args) > 1 && os.Args[1] == "-url" {
 		// This is the generate command
 		swaggerURL := os.Args[2]
-		outputDir := "testdata"
-		if len(os.Args) > 3 && os.Args[3] == "-output" {
-			outputDir = os.Args[4]
+		urlCmd := flag.NewFlagSet("-url", flag.ExitOnError)
+		output := urlCmd.String("output", "testdata", "Directory to write the test data template to")
+		groupByTag := urlCmd.Bool("group-by-tag", false, "Nest template entries by spec tag, with operation summaries as comments (JSON5)")
+		specCandidates := urlCmd.String("spec-candidates", "", "Comma-separated extra spec paths or URLs to try")
+		responseHints := urlCmd.Bool("response-hints", false, "Add a _response_hint field summarizing each endpoint's response schemas")
+		linkResources := urlCmd.Bool("link-resources", false, "Use the same identifier for a resource across all of its endpoints and references")
+		negative := urlCmd.Bool("negative", false, "Add cases breaking each endpoint's schema constraints, expected to be rejected with 400 or 422")
+		includePath := urlCmd.String("include-path", "", "Comma-separated path globs; only endpoints under a matching spec path are generated")
+		excludePath := urlCmd.String("exclude-path", "", "Comma-separated path globs; endpoints under a matching spec path are skipped")
+		tag := urlCmd.String("tag", "", "Comma-separated spec tags; only endpoints with one of them are generated")
+		method := urlCmd.String("method", "", "Comma-separated HTTP methods; only endpoints using one of them are generated")
+		proxy := urlCmd.String("proxy", cfg.Proxy, "HTTP proxy URL to fetch the spec through, overriding HTTP_PROXY/HTTPS_PROXY/NO_PROXY")
+		if err := urlCmd.Parse(os.Args[3:]); err != nil {
+			log.Fatalf("Failed to parse flags: %v", err)
+		}
+		cfg.Proxy = *proxy
+
+		templatePath, err := apitester.Generate(cfg, swaggerURL, apitester.GenerateOptions{
+			OutputDir:      *output,
+			SpecCandidates: splitList(*specCandidates),
+			Filter: apitester.Filter{
+				IncludePaths: splitList(*includePath),
+				ExcludePaths: splitList(*excludePath),
+				Tags:         splitList(*tag),
+				Methods:      splitList(*method),
+			},
+			GroupByTag:    *groupByTag,
+			ResponseHints: *responseHints,
+			LinkResources: *linkResources,
+			Negative:      *negative,
+		})
+		if err != nil {
+			log.Fatalf("%v", err)
 		}
 
-		// Initialize Swagger parser
-		swaggerParser := parser.NewSwaggerParser(swaggerURL)
+		templateFile := filepath.Base(templatePath)
+		fmt.Printf("Test data template generated successfully in %s\n", templatePath)
+		fmt.Printf("Please review and modify the template as needed, then rename it to %s to run the tests.\n", strings.Replace(templateFile, "_template", "", 1))
+		return
+	}
 
-		// Parse endpoints
-		endpoints, err := swaggerParser.ParseEndpoints()
+	// Parse run flags
+	runCmd := flag.NewFlagSet("run", flag.ExitOnError)
+	baseURL := runCmd.String("base-url", cfg.Test.BaseURL, "Base URL for relative endpoint paths, also replacing the scheme and host of absolute ones (e.g. https://staging.example.com)")
+	failOn := runCmd.String("fail-on", reporter.FailOnNone, "Which results fail the run: none, any or 5xx-only")
+	recordPath := runCmd.String("record", "", "Record every request/response pair to this cassette file")
+	replayPath := runCmd.String("replay", "", "Serve responses from this cassette file instead of calling the API")
+	specURL := runCmd.String("spec-url", cfg.Test.SpecURL, "Base URL of the Swagger/OpenAPI spec used to validate responses")
+	specCandidates := runCmd.String("spec-candidates", "", "Comma-separated extra spec paths or URLs to try")
+	proxy := runCmd.String("proxy", cfg.Proxy, "HTTP proxy URL for requests to the API and the spec, overriding HTTP_PROXY/HTTPS_PROXY/NO_PROXY")
+	requestTimeout := runCmd.Int("request-timeout", 0, "Override the per-request timeout in seconds for this run")
+	totalTimeout := runCmd.Int("total-timeout", cfg.Test.TotalTimeout, "Deadline in seconds for the whole run (0 for none)")
+	maxFailures := runCmd.Int("max-failures", cfg.Test.MaxFailures, "Abort the run after this many failed tests (0 runs everything)")
+	strict := runCmd.Bool("strict", cfg.Test.StrictResponses, "Fail responses with fields the spec's response schema doesn't declare (implies response validation)")
+	githubAnnotations := runCmd.Bool("github-annotations", os.Getenv("GITHUB_ACTIONS") == "true", "Print failures as GitHub Actions ::error annotations (default on inside GitHub Actions)")
+	sequential := runCmd.Bool("sequential", cfg.Test.Sequential, "Run the test cases one at a time in the order they are declared")
+	repeat := runCmd.Int("repeat", 0, "Load test: send each test case this many times and report aggregates per test case")
+	duration := runCmd.Duration("duration", 0, "Load test: keep sending requests for this long, e.g. 30s")
+	rampUp := runCmd.Duration("ramp-up", 0, "Load test: spread the start of the workers over this period")
+	printConfig := runCmd.Bool("print-config", false, "Print the effective configuration as JSON, with secrets redacted, and exit")
+	if err := runCmd.Parse(os.Args[1:]); err != nil {
+		log.Fatalf("Failed to parse flags: %v", err)
+	}
+
+	for _, value := range []*string{baseURL, specURL, proxy} {
+		expanded, err := config.ExpandEnv(*value)
 		if err != nil {
-			log.Fatalf("Failed to parse endpoints: %v", err)
+			log.Fatalf("Invalid URL flag: %v", err)
 		}
+		*value = expanded
+	}
 
-		fmt.Printf("Found %d endpoints to test\n", len(endpoints))
+	if *requestTimeout < 0 {
+		log.Fatalf("Invalid -request-timeout %d: must not be negative", *requestTimeout)
+	} else if *requestTimeout > 0 {
+		cfg.Test.Timeout = *requestTimeout
+	}
 
-		// Generate test data template
-		testDataGenerator := testdata.NewGenerator(outputDir)
-		if err := testDataGenerator.GenerateTemplate(endpoints); err != nil {
-			log.Fatalf("Failed to generate test data template: %v", err)
+	// Fold the flag overrides into the config the run uses
+	cfg.Test.BaseURL = *baseURL
+	cfg.Test.SpecURL = *specURL
+	cfg.Proxy = *proxy
+	cfg.Test.MaxFailures = *maxFailures
+	cfg.Test.TotalTimeout = *totalTimeout
+	cfg.Test.StrictResponses = *strict
+	cfg.Test.Sequential = *sequential
+
+	if *printConfig {
+		data, err := json.MarshalIndent(cfg.Redacted(), "", "  ")
+		if err != nil {
+			log.Fatalf("Failed to marshal config: %v", err)
 		}
-
-		fmt.Printf("Test data template generated successfully in %s/testdata_template.json\n", outputDir)
-		fmt.Println("Please review and modify the template as needed, then rename it to testdata.json to run the tests.")
+		fmt.Println(string(data))
 		return
 	}
 
+	if err := reporter.ValidateFailOn(*failOn); err != nil {
+		log.Fatalf("Invalid -fail-on: %v", err)
+	}
+
 	// Load test data
-	testDataLoader := testdata.NewLoader("testdata")
-	testData, err := testDataLoader.LoadTestData()
-	if err != nil {
+	endpoints, err := apitester.LoadEndpoints(cfg, "testdata")
+	if errors.Is(err, apitester.ErrNoTestData) {
 		fmt.Println("No test data found. Please generate test data template first:")
 		fmt.Println("  auto-api-tester generate -url <swagger-url>")
 		fmt.Println("Then fill in the test data in testdata/testdata_template.json")
 		return
+	} else if err != nil {
+		log.Fatalf("%v", err)
 	}
 
-	// Convert test data to endpoints
-	endpoints := make([]types.Endpoint, 0)
-	for endpoint, data := range testData.Endpoints {
-		// Parse method and path from endpoint string (e.g., "GET /api/users")
-		parts := strings.SplitN(endpoint, " ", 2)
-		if len(parts) != 2 {
-			continue
-		}
-		method := parts[0]
-		path := parts[1]
-
-		// Create endpoint with test data
-		ep := types.Endpoint{
-			Method: method,
-			Path:   path,
-			TestData: types.EndpointTestData{
-				PathParams:  data.PathParams,
-				QueryParams: data.QueryParams,
-				Body:        data.Body,
-				Headers:     data.Headers,
-			},
-		}
-		endpoints = append(endpoints, ep)
+	fmt.Printf("Loaded %d test cases from test data\n", len(endpoints))
+
+	options := apitester.Options{
+		BaseURL:           *baseURL,
+		SpecCandidates:    splitList(*specCandidates),
+		Record:            *recordPath,
+		Replay:            *replayPath,
+		Repeat:            *repeat,
+		Duration:          *duration,
+		RampUp:            *rampUp,
+		GitHubAnnotations: *githubAnnotations,
+		WriteFiles:        true,
+		Verbose:           true,
+	}
+	report, err := apitester.Run(context.Background(), cfg, endpoints, options)
+	if err != nil {
+		log.Fatalf("%v", err)
 	}
 
-	fmt.Printf("Loaded %d endpoints from test data\n", len(endpoints))
-
-	// Initialize test executor
-	testExecutor := executor.NewTestExecutor(executor.TestConfig{
-		Concurrent: cfg.Test.Concurrent,
-		MaxWorkers: cfg.Test.MaxWorkers,
-		Timeout:    cfg.Test.Timeout,
-		Retry: executor.RetryConfig{
-			Attempts: cfg.Test.Retry.Attempts,
-			Delay:    time.Duration(cfg.Test.Retry.Delay) * time.Second,
-		},
-	}, testDataLoader)
-
-	// Initialize reporter
-	testReporter := reporter.NewReporter(reporter.ReportingConfig{
-		Format:    []string{cfg.Reporting.Format},
-		OutputDir: cfg.Reporting.OutputDir,
-		Detailed:  cfg.Reporting.Detailed,
-	})
-
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Test.Timeout)*time.Second)
-	defer cancel()
-
-	// Run tests
-	results := testExecutor.RunTests(ctx, endpoints)
-
-	// Generate report
-	if err := testReporter.GenerateReport(convertTestResults(results)); err != nil {
-		log.Fatalf("Failed to generate report: %v", err)
+	exitCode, err := reporter.ExitCode(report.Results, *failOn)
+	if len(report.Load) > 0 {
+		exitCode, err = reporter.LoadExitCode(report.Load, *failOn)
+	}
+	if err != nil {
+		log.Fatalf("Failed to evaluate results: %v", err)
+	}
+	if exitCode != reporter.ExitOK {
+		fmt.Printf("API testing completed with failures (exit code %d)\n", exitCode)
+		os.Exit(exitCode)
 	}
 
 	fmt.Println("API testing completed successfully!")
@@ -0,0 +1,164 @@
+// Package apitester runs the tester in-process, so it can be embedded in a Go
+// test suite or another tool. It returns reports instead of exiting; the CLI
+// is a thin wrapper around it.
+package apitester
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+
+	"auto-api-tester/internal/config"
+	"auto-api-tester/internal/executor"
+	"auto-api-tester/internal/parser"
+	"auto-api-tester/internal/reporter"
+	"auto-api-tester/internal/testdata"
+	"auto-api-tester/internal/types"
+)
+
+// Aliases make the types of the internal packages usable by importers
+type (
+	Config     = config.Config
+	Endpoint   = types.Endpoint
+	Report     = reporter.Report
+	TestResult = reporter.TestResult
+	LoadResult = reporter.LoadResult
+	Filter     = parser.Filter
+)
+
+// ErrNoTestData is returned by LoadEndpoints when the directory holds no
+// test data file
+var ErrNoTestData = testdata.ErrNoTestData
+
+// LoadConfig reads config/config.json, .yaml or .yml, writing a default
+// config/config.json if none exists
+func LoadConfig() (*Config, error) {
+	return config.LoadConfig()
+}
+
+// LoadConfigFile reads a JSON or YAML config file, chosen by its extension,
+// writing one with the defaults if it is missing
+func LoadConfigFile(path string) (*Config, error) {
+	return config.LoadConfigFile(path)
+}
+
+// LoadEndpoints reads the test cases from the test data in dir, in the
+// order they are declared
+func LoadEndpoints(cfg *Config, dir string) ([]Endpoint, error) {
+	data, err := testdata.NewLoader(dir).LoadTestData()
+	if errors.Is(err, ErrNoTestData) {
+		return nil, fmt.Errorf("%s: %w", dir, err)
+	} else if err != nil {
+		return nil, fmt.Errorf("invalid test data: %v", err)
+	}
+
+	entries, err := data.Entries()
+	if err != nil {
+		return nil, fmt.Errorf("invalid test data: %v", err)
+	}
+	endpoints := make([]Endpoint, 0, len(entries))
+	for _, entry := range entries {
+		if profile := entry.AuthProfile; profile != "" && profile != executor.NoAuthProfile {
+			if _, ok := cfg.AuthProfiles[profile]; !ok {
+				return nil, fmt.Errorf("invalid test data: %s uses unknown auth profile %q", entry.ID(), profile)
+			}
+		}
+		endpoints = append(endpoints, Endpoint{
+			Method:   entry.Method,
+			Path:     entry.Path,
+			Case:     entry.Name,
+			TestData: entry.EndpointTestData,
+			BaseURL:  data.BaseURL,
+		})
+	}
+	return endpoints, nil
+}
+
+// GenerateOptions controls the test data template written by Generate
+type GenerateOptions struct {
+	// OutputDir is the directory the template is written to, testdata by default
+	OutputDir string
+	// SpecCandidates are extra spec paths or URLs to try besides the configured ones
+	SpecCandidates []string
+	// Filter limits the endpoints taken from the spec
+	Filter Filter
+
+	GroupByTag    bool
+	ResponseHints bool
+	LinkResources bool
+	Negative      bool
+}
+
+// Generate writes a test data template for the spec found at specURL and
+// returns the template's path
+func Generate(cfg *Config, specURL string, options GenerateOptions) (string, error) {
+	outputDir := options.OutputDir
+	if outputDir == "" {
+		outputDir = "testdata"
+	}
+
+	transport, err := proxyTransport(cfg.Proxy)
+	if err != nil {
+		return "", err
+	}
+	parserOptions := specOptions(cfg, options.SpecCandidates, transport)
+	parserOptions.Filter = options.Filter
+	endpoints, err := parser.NewSwaggerParser(specURL, parserOptions).ParseEndpoints()
+	if err != nil {
+		return "", fmt.Errorf("failed to parse endpoints: %v", err)
+	}
+
+	fmt.Printf("Found %d endpoints to test\n", len(endpoints))
+
+	generator := testdata.NewGenerator(outputDir, testdata.GeneratorOptions{
+		ArrayItems:    cfg.Generation.ArrayItems,
+		GroupByTag:    options.GroupByTag,
+		ResponseHints: options.ResponseHints,
+		LinkResources: options.LinkResources,
+		Negative:      options.Negative,
+	})
+	if err := generator.GenerateTemplate(endpoints); err != nil {
+		return "", fmt.Errorf("failed to generate test data template: %v", err)
+	}
+
+	templateFile := "testdata_template.json"
+	if options.GroupByTag {
+		templateFile = "testdata_template.json5"
+	}
+	return filepath.Join(outputDir, templateFile), nil
+}
+
+// specOptions combines the configured spec candidates with extra ones
+func specOptions(cfg *Config, candidates []string, transport http.RoundTripper) parser.Options {
+	return parser.Options{
+		Candidates:      append(append([]string{}, cfg.Spec.Candidates...), candidates...),
+		ReplaceDefaults: cfg.Spec.ReplaceDefaults,
+		Transport:       transport,
+	}
+}
+
+// proxyTransport returns the transport shared by the spec fetcher and the
+// executor. Without a configured proxy it is nil, leaving them on
+// http.DefaultTransport, which honours HTTP_PROXY, HTTPS_PROXY and NO_PROXY;
+// a configured proxy takes every request instead.
+func proxyTransport(proxy string) (http.RoundTripper, error) {
+	if proxy == "" {
+		return nil, nil
+	}
+	// The URL is left out of errors as it may carry credentials
+	u, err := url.Parse(proxy)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL: expected scheme and host, e.g. http://proxy.example.com:3128")
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid proxy URL: unsupported scheme %q (want http, https or socks5)", u.Scheme)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyURL(u)
+	return transport, nil
+}
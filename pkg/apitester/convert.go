@@ -0,0 +1,91 @@
+package apitester
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"auto-api-tester/internal/config"
+	"auto-api-tester/internal/executor"
+	"auto-api-tester/internal/reporter"
+)
+
+// convertTestResults maps executor results onto the reporter's form
+func convertTestResults(execResults []executor.TestResult) []reporter.TestResult {
+	repResults := make([]reporter.TestResult, len(execResults))
+	for i, r := range execResults {
+		repResults[i] = convertTestResult(r)
+	}
+	return repResults
+}
+
+// convertTestResult maps an executor result onto the reporter's form
+func convertTestResult(r executor.TestResult) reporter.TestResult {
+	// Only a JSON body is parsed; HTML error pages and plain text stay raw
+	var response interface{}
+	if r.Response != "" && isJSONContentType(r.ContentType) {
+		if err := json.Unmarshal([]byte(r.Response), &response); err != nil {
+			response = nil
+		}
+	}
+
+	errText := ""
+	if r.Error != nil {
+		errText = r.Error.Error()
+	}
+
+	return reporter.TestResult{
+		Endpoint:        r.Endpoint,
+		Method:          r.Method,
+		Case:            r.Case,
+		Status:          r.Status,
+		StatusCode:      r.StatusCode,
+		ExpectedStatus:  r.ExpectedStatus,
+		Skipped:         r.Status == "SKIPPED",
+		Duration:        r.Duration,
+		Error:           errText,
+		RequestBody:     r.RequestBody,
+		Response:        response,
+		RawResponse:     r.Response,
+		ContentType:     r.ContentType,
+		ResponseHeaders: r.ResponseHeaders,
+		Timings:         r.Timings,
+		Diffs:           r.Diffs,
+	}
+}
+
+// convertLoadResults maps a load run's aggregates onto the reporter's form
+func convertLoadResults(execResults []executor.LoadResult, elapsed time.Duration) []reporter.LoadResult {
+	repResults := make([]reporter.LoadResult, len(execResults))
+	for i, r := range execResults {
+		errText := ""
+		if r.Error != nil {
+			errText = r.Error.Error()
+		}
+		repResults[i] = reporter.NewLoadResult(r.Endpoint, r.Method, r.Case, r.Requests, r.Errors, r.ServerErrors, r.Durations, errText, elapsed)
+	}
+	return repResults
+}
+
+// isJSONContentType reports whether a Content-Type is JSON, including
+// vendor types such as application/problem+json
+func isJSONContentType(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// executorAuth converts configured credentials for the executor
+func executorAuth(auth config.AuthConfig) executor.AuthConfig {
+	return executor.AuthConfig{
+		Type:         auth.Type,
+		HeaderName:   auth.HeaderName,
+		Value:        auth.Value,
+		Token:        auth.Token,
+		TokenURL:     auth.TokenURL,
+		ClientID:     auth.ClientID,
+		ClientSecret: auth.ClientSecret,
+		RefreshToken: auth.RefreshToken,
+		Scopes:       auth.Scopes,
+	}
+}
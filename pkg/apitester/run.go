@@ -0,0 +1,294 @@
+package apitester
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"time"
+
+	"auto-api-tester/internal/executor"
+	"auto-api-tester/internal/parser"
+	"auto-api-tester/internal/reporter"
+	"auto-api-tester/internal/types"
+)
+
+// Options are the settings of a single run that are not part of Config.
+// The zero value runs the test cases as configured.
+type Options struct {
+	// BaseURL overrides test.base_url: it is prepended to relative endpoint
+	// paths and replaces the scheme and host of absolute ones
+	BaseURL string
+	// SpecCandidates are extra spec paths or URLs to try when fetching the
+	// spec for response validation
+	SpecCandidates []string
+
+	// Record and Replay are cassette files to record the run to or to
+	// serve responses from; at most one may be set
+	Record string
+	Replay string
+
+	// Repeat, Duration and RampUp turn the run into a load test reporting
+	// aggregates per test case in Report.Load
+	Repeat   int
+	Duration time.Duration
+	RampUp   time.Duration
+
+	// GitHubAnnotations prints failures as GitHub Actions annotations
+	GitHubAnnotations bool
+
+	// WriteFiles writes the report files, metrics file and HAR file the
+	// config asks for; otherwise the returned Report is the only output
+	WriteFiles bool
+	// Verbose prints each request and response and the run's progress to stdout
+	Verbose bool
+}
+
+// Run executes the test cases of endpoints, sends results to the configured
+// sinks and notifications, and returns the report. Failed tests are reported
+// in the Report, not as an error. Files and stdout output are opt-in through
+// WriteFiles and Verbose; a cassette is only written when Record is set.
+func Run(ctx context.Context, cfg *Config, endpoints []Endpoint, options Options) (*Report, error) {
+	if options.BaseURL == "" {
+		options.BaseURL = cfg.Test.BaseURL
+	}
+	if options.BaseURL != "" {
+		if u, err := url.Parse(options.BaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid base URL %q: expected scheme and host", options.BaseURL)
+		}
+	}
+	if options.Repeat < 0 || options.Duration < 0 || options.RampUp < 0 {
+		return nil, fmt.Errorf("invalid load test settings: repeat, duration and ramp-up must not be negative")
+	}
+	if cfg.Test.TotalTimeout < 0 {
+		return nil, fmt.Errorf("invalid total timeout %d: must not be negative", cfg.Test.TotalTimeout)
+	}
+	for service, profile := range cfg.AuthServices {
+		if _, ok := cfg.AuthProfiles[profile]; !ok && profile != executor.NoAuthProfile {
+			return nil, fmt.Errorf("invalid config: auth_services %s uses unknown auth profile %q", service, profile)
+		}
+	}
+	if err := reporter.ValidateFormats(cfg.Reporting.Format); err != nil {
+		return nil, fmt.Errorf("invalid reporting config: %v", err)
+	}
+	transport, err := proxyTransport(cfg.Proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	// Attach the spec's documented responses for validation
+	validateResponses := cfg.Test.ValidateResponses || cfg.Test.StrictResponses
+	if validateResponses || cfg.Test.ExpectDocumentedStatus {
+		if cfg.Test.SpecURL == "" {
+			return nil, fmt.Errorf("response validation requires a spec URL (test.spec_url or -spec-url)")
+		}
+		specEndpoints, err := parser.NewSwaggerParser(cfg.Test.SpecURL, specOptions(cfg, options.SpecCandidates, transport)).ParseEndpoints()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse spec for response validation: %v", err)
+		}
+		responses := make(map[string]map[int]types.Response, len(specEndpoints))
+		for _, ep := range specEndpoints {
+			responses[ep.Method+" "+ep.Path] = ep.Responses
+		}
+		endpoints = append([]Endpoint{}, endpoints...)
+		for i := range endpoints {
+			endpoints[i].Responses = responses[endpoints[i].Method+" "+specPath(endpoints[i].Path)]
+		}
+	}
+
+	// Record to or replay from a cassette
+	var cassetteConfig executor.CassetteConfig
+	switch {
+	case options.Record != "" && options.Replay != "":
+		return nil, fmt.Errorf("record and replay cannot be used together")
+	case options.Record != "":
+		cassetteConfig = executor.CassetteConfig{Mode: executor.CassetteRecord, Path: options.Record}
+	case options.Replay != "":
+		cassetteConfig = executor.CassetteConfig{Mode: executor.CassetteReplay, Path: options.Replay}
+	}
+
+	loadConfig := executor.LoadConfig{Repeat: options.Repeat, Duration: options.Duration, RampUp: options.RampUp}
+	testExecutor, err := newExecutor(cfg, options, transport, cassetteConfig, loadConfig, validateResponses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize test executor: %v", err)
+	}
+
+	// Keep every artifact of this run together when run folders are enabled
+	outputDir := cfg.Reporting.OutputDir
+	if cfg.Reporting.RunFolders {
+		outputDir = reporter.RunDir(outputDir, time.Now())
+	}
+
+	testReporter, err := newReporter(cfg, options, outputDir)
+	if err != nil {
+		return nil, err
+	}
+	testExecutor.OnResult(func(result executor.TestResult) {
+		testReporter.Write(convertTestResult(result))
+	})
+
+	var report *Report
+	if loadConfig.Enabled() {
+		// A load run reports aggregates per test case instead of every request
+		loadResults, elapsed := testExecutor.RunLoad(ctx, endpoints)
+		reportLoad := convertLoadResults(loadResults, elapsed)
+		if report, err = testReporter.GenerateLoadReport(reportLoad, elapsed); err != nil {
+			return nil, fmt.Errorf("failed to generate report: %v", err)
+		}
+		for _, result := range reportLoad {
+			options.printf("%s: %d requests, %.1f req/s, %.1f%% errors, p99 %s\n", result.Name(), result.Requests, result.RequestsPerSecond, result.ErrorRate*100, result.Latency.P99.Round(100*time.Microsecond))
+		}
+	} else {
+		results := testExecutor.RunTests(ctx, endpoints)
+		if testExecutor.Aborted() {
+			options.printf("Run aborted early; reporting the %d tests that ran\n", len(results))
+		}
+		if report, err = testReporter.GenerateReport(convertTestResults(results)); err != nil {
+			return nil, fmt.Errorf("failed to generate report: %v", err)
+		}
+	}
+
+	if err := testExecutor.SaveCassette(); err != nil {
+		return nil, fmt.Errorf("failed to save cassette: %v", err)
+	}
+	if options.Record != "" {
+		options.printf("Cassette recorded to %s\n", options.Record)
+	}
+
+	// Export the raw exchanges
+	if cfg.Reporting.HAR && options.WriteFiles {
+		harPath := filepath.Join(outputDir, fmt.Sprintf("report_%s.har", time.Now().Format("20060102_150405")))
+		if err := testExecutor.WriteHAR(harPath); err != nil {
+			return nil, fmt.Errorf("failed to write HAR file: %v", err)
+		}
+		options.printf("HAR file written to %s\n", harPath)
+	}
+
+	if cfg.Reporting.RunFolders && options.WriteFiles {
+		options.printf("Run artifacts written to %s\n", outputDir)
+	}
+	return report, nil
+}
+
+// printf prints to stdout for verbose runs
+func (o Options) printf(format string, args ...interface{}) {
+	if o.Verbose {
+		fmt.Printf(format, args...)
+	}
+}
+
+// specPath returns the path of an endpoint as the spec writes it, without
+// the scheme and host of test data generated with absolute URLs
+func specPath(path string) string {
+	if u, err := url.Parse(path); err == nil && u.Host != "" {
+		return u.Path
+	}
+	return path
+}
+
+// newExecutor configures a test executor from cfg; test cases come from the
+// endpoints rather than a loader
+func newExecutor(cfg *Config, options Options, transport http.RoundTripper, cassette executor.CassetteConfig, load executor.LoadConfig, validateResponses bool) (*executor.TestExecutor, error) {
+	authProfiles := make(map[string]executor.AuthConfig, len(cfg.AuthProfiles))
+	for name, profile := range cfg.AuthProfiles {
+		authProfiles[name] = executorAuth(profile)
+	}
+
+	return executor.NewTestExecutor(executor.TestConfig{
+		Concurrent:     cfg.Test.Concurrent,
+		MaxWorkers:     cfg.Test.MaxWorkers,
+		RequestTimeout: cfg.Test.Timeout,
+		TotalTimeout:   cfg.Test.TotalTimeout,
+		BaseURL:        options.BaseURL,
+
+		FollowRedirects: cfg.Test.FollowRedirects == nil || *cfg.Test.FollowRedirects,
+		MaxRedirects:    cfg.Test.MaxRedirects,
+		UseCookies:      cfg.Test.UseCookies,
+
+		Retry: executor.RetryConfig{
+			Attempts: cfg.Test.Retry.Attempts,
+			Delay:    time.Duration(cfg.Test.Retry.Delay) * time.Second,
+			Budget:   cfg.Test.Retry.Budget,
+
+			RetryableStatusCodes: cfg.Test.Retry.RetryableStatusCodes,
+		},
+		Idempotency: executor.IdempotencyConfig{
+			Enabled: cfg.Test.IdempotencyKey.Enabled,
+			Header:  cfg.Test.IdempotencyKey.Header,
+		},
+		Auth:         executorAuth(cfg.Auth),
+		AuthProfiles: authProfiles,
+		ServiceAuth:  cfg.AuthServices,
+		RecordHAR:    cfg.Reporting.HAR && options.WriteFiles,
+		Quiet:        !options.Verbose,
+		Transport:    transport,
+		Cassette:     cassette,
+		MaxFailures:  cfg.Test.MaxFailures,
+		Breaker: executor.BreakerConfig{
+			Threshold: cfg.Test.CircuitBreaker.Threshold,
+			Window:    time.Duration(cfg.Test.CircuitBreaker.WindowSeconds) * time.Second,
+			Cooldown:  time.Duration(cfg.Test.CircuitBreaker.CooldownSeconds) * time.Second,
+		},
+		Variables:              cfg.Test.Variables,
+		EnvHeaders:             cfg.Test.EnvHeaders,
+		Sequential:             cfg.Test.Sequential,
+		Load:                   load,
+		Iterations:             cfg.Test.Iterations,
+		Sample:                 cfg.Test.Sample,
+		AllowEmptyBinary:       cfg.Test.AllowEmptyBinary,
+		ValidateResponses:      validateResponses,
+		StrictResponses:        cfg.Test.StrictResponses,
+		ExpectDocumentedStatus: cfg.Test.ExpectDocumentedStatus,
+		ExpectedHeaders:        cfg.Assertions.Headers,
+		UnredactedHeaders:      cfg.Reporting.UnredactedHeaders,
+		IgnoreFields:           cfg.Assertions.IgnoreFields,
+		DetectCachedResponses:  cfg.Assertions.DetectCachedResponses,
+		Golden: executor.GoldenConfig{
+			Enabled:      cfg.Golden.Enabled,
+			Dir:          cfg.Golden.Dir,
+			IgnoreFields: cfg.Golden.IgnoreFields,
+		},
+	}, nil)
+}
+
+// newReporter configures the reporter with the sinks and notifier of cfg
+func newReporter(cfg *Config, options Options, outputDir string) (*reporter.Reporter, error) {
+	testReporter := reporter.NewReporter(reporter.ReportingConfig{
+		Format:      cfg.Reporting.Format,
+		OutputDir:   outputDir,
+		Detailed:    cfg.Reporting.Detailed,
+		MetricsFile: cfg.Reporting.MetricsFile,
+		Sort:        cfg.Reporting.Sort,
+		Template:    cfg.Reporting.Template,
+		SkipFiles:   !options.WriteFiles,
+	})
+	for _, sinkConfig := range cfg.Reporting.Sinks {
+		sink, err := reporter.NewSink(reporter.SinkConfig{
+			Type:    sinkConfig.Type,
+			URL:     sinkConfig.URL,
+			Headers: sinkConfig.Headers,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid reporting sink: %v", err)
+		}
+		testReporter.AddSink(sink)
+	}
+	if cfg.Notify.WebhookURL != "" {
+		notifier, err := reporter.NewNotifier(reporter.NotifyConfig{
+			WebhookURL: cfg.Notify.WebhookURL,
+			Label:      cfg.Notify.Label,
+			ReportURL:  cfg.Notify.ReportURL,
+			Template:   cfg.Notify.Template,
+			NotifyOn:   cfg.Notify.NotifyOn,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid notification settings: %v", err)
+		}
+		testReporter.AddSink(notifier)
+	}
+	if options.GitHubAnnotations {
+		testReporter.AddSink(reporter.NewGitHubAnnotations())
+	}
+	return testReporter, nil
+}